@@ -26,7 +26,7 @@ type AST interface {
 	// In this case, `i` should be less than zero.
 	Move(n, newParent *Node, i int) error
 
-	// Delete deletes a node `n` from the AST.
+	// Delete deletes a node `n` and its whole subtree from the AST, detaching it from its parent.
 	Delete(n *Node) error
 
 	// Root returns the root node of the AST.
@@ -73,9 +73,10 @@ func (a *astConcrete) Add(parent *Node, i int, label NodeLabelType, value NodeVa
 	}
 	if newNode.Parent == nil {
 		if a.root != nil {
-			msg := "the root node already exists in the AST"
-			a.logger.Error(msg)
-			return nil, fmt.Errorf(msg)
+			if err := a.root.UpdateParent(NodeParentInfo{Parent: newNode, IdxToParent: 0}); err != nil {
+				a.logger.Error("error attaching the previous root node to the new one")
+				return nil, err
+			}
 		}
 		a.root = newNode
 	}
@@ -85,7 +86,19 @@ func (a *astConcrete) Add(parent *Node, i int, label NodeLabelType, value NodeVa
 }
 
 func (a *astConcrete) Move(n, newParent *Node, i int) error {
-	return n.UpdateParent(NodeParentInfo{Parent: newParent, IdxToParent: i})
+	if n == nil {
+		msg := "node is nil"
+		a.logger.Error(msg)
+		return fmt.Errorf(msg)
+	}
+
+	if err := n.UpdateParent(NodeParentInfo{Parent: newParent, IdxToParent: i}); err != nil {
+		return err
+	}
+	if newParent == nil {
+		a.root = n
+	}
+	return nil
 }
 
 func (a *astConcrete) Delete(n *Node) error {
@@ -95,8 +108,19 @@ func (a *astConcrete) Delete(n *Node) error {
 		return fmt.Errorf(msg)
 	}
 
+	for _, descendant := range a.postOrder(n) {
+		delete(a.nodes, descendant.Id)
+	}
+	if n.Parent != nil {
+		delete(n.Parent.Children, n.idxToParent)
+		n.Parent = nil
+		n.idxToParent = -1
+	}
+	if a.root == n {
+		a.root = nil
+	}
+
 	n.DestroySubtree()
-	delete(a.nodes, n.Id)
 	return nil
 }
 
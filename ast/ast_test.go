@@ -0,0 +1,81 @@
+package ast_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"testing"
+)
+
+func TestAST(t *testing.T) {
+	t.Run("Test adding a root over the previous one", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		oldRoot, _ := tree.Add(nil, -1, "old", "")
+		newRoot, err := tree.Add(nil, -1, "new", "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root() != newRoot || oldRoot.Parent != newRoot || newRoot.Children[0] != oldRoot {
+			t.Errorf("Expected the previous root to be the only child of the new one")
+		}
+	})
+
+	t.Run("Test moving a node to the root", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "root", "")
+		child, _ := tree.Add(root, 0, "child", "")
+		if err := tree.Move(child, nil, -1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root() != child || child.Parent != nil || root.Degree() != 0 {
+			t.Errorf("Expected the child to become the root")
+		}
+	})
+
+	t.Run("Test deleting a subtree", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "root", "")
+		child, _ := tree.Add(root, 0, "child", "")
+		_, _ = tree.Add(child, 0, "grandchild", "")
+		sibling, _ := tree.Add(root, 1, "sibling", "")
+
+		if err := tree.Delete(child); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if root.Degree() != 1 || root.Children[1] != sibling || child.Parent != nil || child.IdxToParent() != -1 {
+			t.Errorf("Expected the child to be detached from the root")
+		}
+		if nodes := tree.PreOrderNodes(); len(nodes) != 2 {
+			t.Errorf("Expected 2 nodes left, got %d", len(nodes))
+		}
+		if _, err := tree.Add(root, 0, "new", ""); err != nil {
+			t.Errorf("Expected the index of the deleted child to be free, got %v", err)
+		}
+	})
+
+	t.Run("Test deleting the root", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "root", "")
+		if err := tree.Delete(root); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root() != nil || ast.RootHash(tree) != 0 {
+			t.Errorf("Expected an empty tree")
+		}
+	})
+
+	t.Run("Test copying a tree", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "root", "")
+		root.Pos = ast.NodePos{Start: 0, End: 3}
+		_, _ = tree.Add(root, 4, "a", "x")
+		_, _ = tree.Add(root, 7, "b", "y")
+
+		copied, copies := ast.Copy(tree, slog.Logger{})
+		if ast.RootHash(copied) != ast.RootHash(tree) {
+			t.Errorf("Expected the copy to be isomorphic to the tree")
+		}
+		if copies[root] != copied.Root() || copied.Root() == root || copied.Root().Pos != root.Pos {
+			t.Errorf("Expected the root to be copied with its position")
+		}
+	})
+}
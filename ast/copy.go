@@ -0,0 +1,38 @@
+package ast
+
+import "log/slog"
+
+// Copy returns a deep copy of `tree`, keeping the positions of the nodes,
+// and the mapping from the nodes of `tree` to their copies.
+func Copy(tree AST, logger slog.Logger) (AST, map[*Node]*Node) {
+	result := NewAST(logger)
+	copies := make(map[*Node]*Node)
+
+	var copyNode func(n, parent *Node, idx int)
+	copyNode = func(n, parent *Node, idx int) {
+		c, err := result.Add(parent, idx, n.Label, n.Value)
+		if err != nil {
+			// The indices of the children of a fresh node are always free.
+			panic(err)
+		}
+		c.Pos = n.Pos
+		copies[n] = c
+		for i, child := range n.OrderedChildren() {
+			copyNode(child, c, i)
+		}
+	}
+
+	if tree.Root() != nil {
+		copyNode(tree.Root(), nil, -1)
+	}
+	return result, copies
+}
+
+// RootHash returns the hash of the root of `tree` computed by AST.MakeHashMemo, or 0 if the tree is empty.
+// Two trees with the same root hash are isomorphic.
+func RootHash(tree AST) uint64 {
+	if tree.Root() == nil {
+		return 0
+	}
+	return tree.MakeHashMemo()[tree.Root().Id]
+}
@@ -0,0 +1,62 @@
+package ast
+
+import (
+	"fmt"
+	"github.com/cespare/xxhash/v2"
+	"strconv"
+)
+
+// hashOf returns the hash of the subtree of `n`, given the hashes of its ordered children.
+func hashOf(n *Node, childHashes []uint64) uint64 {
+	propertyStr := fmt.Sprintf("<%s>[%s]<", n.Label, n.Value)
+	propertyHash := xxhash.Sum64String(propertyStr)
+	if len(childHashes) == 0 {
+		return propertyHash
+	}
+
+	var combinedChildrenHash xxhash.Digest
+	_, _ = combinedChildrenHash.WriteString(strconv.FormatUint(propertyHash, 10))
+	for _, childHash := range childHashes {
+		_, _ = combinedChildrenHash.WriteString(strconv.FormatUint(childHash, 10))
+	}
+	return combinedChildrenHash.Sum64()
+}
+
+// Rehash updates the memo after the node `n` has changed, recomputing the hashes of `n` and of its ancestors only,
+// instead of the whole tree as AST.MakeHashMemo does.
+// It must be called after each mutation of the tree, on every node whose label, value or children changed:
+//   - the node itself after AST.UpdateLabel or AST.UpdateValue,
+//   - the new node after AST.Add, which then also covers its parent,
+//   - the former and the new parent of the node after AST.Move,
+//   - the former parent of the node after AST.Delete, see also Forget.
+//
+// The hashes of the children of `n` are taken from the memo, and computed if missing, e.g. for an added subtree.
+// The ancestors are not visited past the first one whose hash is unchanged.
+func (m NodeHashMemo) Rehash(n *Node) {
+	for ; n != nil; n = n.Parent {
+		children := n.OrderedChildren()
+		childHashes := make([]uint64, 0, len(children))
+		for _, child := range children {
+			childHash, ok := m[child.Id]
+			if !ok {
+				childHash = child.HashValue(&m)
+			}
+			childHashes = append(childHashes, childHash)
+		}
+
+		hash := hashOf(n, childHashes)
+		if previous, ok := m[n.Id]; ok && previous == hash {
+			return
+		}
+		m[n.Id] = hash
+	}
+}
+
+// Forget removes the hashes of the subtree of `n` from the memo.
+// It must be called before AST.Delete, which detaches the descendants of `n`.
+func (m NodeHashMemo) Forget(n *Node) {
+	delete(m, n.Id)
+	for _, child := range n.Children {
+		m.Forget(child)
+	}
+}
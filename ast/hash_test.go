@@ -0,0 +1,111 @@
+package ast_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"math/rand/v2"
+	"testing"
+)
+
+// assertFreshHashes checks that `memo` holds the hashes that MakeHashMemo computes from scratch, and nothing else.
+func assertFreshHashes(t *testing.T, tree ast.AST, memo ast.NodeHashMemo) {
+	t.Helper()
+
+	fresh := tree.MakeHashMemo()
+	if len(memo) != len(fresh) {
+		t.Fatalf("Expected %d hashes, got %d", len(fresh), len(memo))
+	}
+	for id, hash := range fresh {
+		if memo[id] != hash {
+			t.Fatalf("Expected the hash of %s to be %d, got %d", id, hash, memo[id])
+		}
+	}
+}
+
+// isAncestorOrSelf tells whether `a` is `n` or one of its ancestors.
+func isAncestorOrSelf(a, n *ast.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == a {
+			return true
+		}
+	}
+	return false
+}
+
+// nextIndexOf returns an index after the ones of the children of `n`, which may have gaps after moves and deletions.
+func nextIndexOf(n *ast.Node) int {
+	next := 0
+	for idx := range n.Children {
+		next = max(next, idx+1)
+	}
+	return next
+}
+
+func TestNodeHashMemo_Rehash(t *testing.T) {
+	t.Run("Test updating a leaf only rehashes its ancestors", func(t *testing.T) {
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "Block", "")
+		left, _ := tree.Add(root, 0, "Call", "")
+		leaf, _ := tree.Add(left, 0, "Ident", "f")
+		right, _ := tree.Add(root, 1, "Call", "")
+		_, _ = tree.Add(right, 0, "Ident", "g")
+
+		memo := tree.MakeHashMemo()
+		rightHash := memo[right.Id]
+		_ = tree.UpdateValue(leaf, "h")
+		memo.Rehash(leaf)
+
+		assertFreshHashes(t, tree, memo)
+		if memo[right.Id] != rightHash {
+			t.Errorf("Expected the hash of the sibling subtree to be kept")
+		}
+	})
+
+	t.Run("Test random mutations keep the memo fresh", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(7, 11))
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "Root", "")
+		nodes := []*ast.Node{root}
+		memo := tree.MakeHashMemo()
+		labels := []ast.NodeLabelType{"a", "b", "c"}
+
+		for i := 0; i < 500; i++ {
+			n := nodes[rng.IntN(len(nodes))]
+			switch rng.IntN(5) {
+			case 0, 1:
+				added, err := tree.Add(n, nextIndexOf(n), labels[rng.IntN(len(labels))], "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				memo.Rehash(added)
+				nodes = append(nodes, added)
+			case 2:
+				_ = tree.UpdateValue(n, ast.NodeValueType(labels[rng.IntN(len(labels))]))
+				memo.Rehash(n)
+			case 3:
+				newParent := nodes[rng.IntN(len(nodes))]
+				if n == root || isAncestorOrSelf(n, newParent) {
+					continue
+				}
+				oldParent := n.Parent
+				if err := tree.Move(n, newParent, nextIndexOf(newParent)+rng.IntN(3)); err != nil {
+					t.Fatal(err)
+				}
+				memo.Rehash(oldParent)
+				memo.Rehash(newParent)
+			case 4:
+				if n == root {
+					continue
+				}
+				parent := n.Parent
+				memo.Forget(n)
+				if err := tree.Delete(n); err != nil {
+					t.Fatal(err)
+				}
+				memo.Rehash(parent)
+				nodes = tree.PreOrderNodes()
+			}
+			assertFreshHashes(t, tree, memo)
+		}
+	})
+}
@@ -2,11 +2,9 @@ package ast
 
 import (
 	"fmt"
-	"github.com/cespare/xxhash/v2"
 	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
 	"sort"
-	"strconv"
 	"sync"
 )
 
@@ -18,6 +16,7 @@ type Node struct {
 	Parent      *Node
 	Children    map[int]*Node
 	Id          NodeIdType
+	Pos         NodePos
 	idxToParent int
 }
 
@@ -49,6 +48,7 @@ func NewNode(parentInfo NodeParentInfo, label NodeLabelType, value NodeValueType
 		Parent:      nil,
 		Children:    make(map[int]*Node),
 		Id:          newIdStr,
+		Pos:         NoPos,
 		idxToParent: -1,
 	}
 
@@ -88,6 +88,11 @@ func (n *Node) UpdateParent(newParentInfo NodeParentInfo) error {
 	return nil
 }
 
+// IdxToParent returns the index of the Node in the children map of its Parent, or -1 if it has no Parent.
+func (n *Node) IdxToParent() int {
+	return n.idxToParent
+}
+
 func (n *Node) DestroySubtree() {
 	if n == nil {
 		panic("destroying node is nil")
@@ -141,29 +146,13 @@ func (n *Node) Isomorphic(other *Node) bool {
 }
 
 func (n *Node) HashValue(memo *NodeHashMemo) uint64 {
-	propertyStr := fmt.Sprintf("<%s>[%s]<", n.Label, n.Value)
-	propertyHash := xxhash.Sum64String(propertyStr)
-
-	if len(n.Children) == 0 {
-		if memo != nil {
-			lock.Lock()
-			defer lock.Unlock()
-			(*memo)[n.Id] = propertyHash
-		}
-		return propertyHash
-	}
-
 	children := n.OrderedChildren()
-
-	var combinedChildrenHash xxhash.Digest
-	_, _ = combinedChildrenHash.WriteString(strconv.FormatUint(propertyHash, 10))
-
+	childHashes := make([]uint64, 0, len(children))
 	for _, child := range children {
-		childHash := child.HashValue(memo)
-		_, _ = combinedChildrenHash.WriteString(strconv.FormatUint(childHash, 10))
+		childHashes = append(childHashes, child.HashValue(memo))
 	}
 
-	result := combinedChildrenHash.Sum64()
+	result := hashOf(n, childHashes)
 	if memo != nil {
 		lock.Lock()
 		defer lock.Unlock()
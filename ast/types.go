@@ -5,3 +5,17 @@ type (
 	NodeLabelType string
 	NodeValueType string
 )
+
+// NodePos is the half-open byte range [Start, End) that a Node spans in its source text.
+// Nodes that are not backed by any source text have NoPos.
+type NodePos struct {
+	Start, End int
+}
+
+// NoPos is the position of nodes that do not come from a source text.
+var NoPos = NodePos{Start: -1, End: -1}
+
+// IsValid reports whether the position refers to an actual range of the source text.
+func (p NodePos) IsValid() bool {
+	return p.Start >= 0 && p.End >= p.Start
+}
@@ -0,0 +1,184 @@
+// Package cache persists parsed trees and their hash memos in a local directory,
+// so that files whose content did not change are not parsed and hashed again on the next run.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/transform"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// formatVersion is the version of the format of the cached files, part of every key,
+// so that files written by older versions are never read.
+const formatVersion = 1
+
+// Key identifies a cached tree by everything it is built from.
+type Key string
+
+// KeyOf returns the key of the tree of the file at `path` with the content `content` normalized by `pipeline`.
+// The key covers the extension of the file, which picks the frontend, the names of the passes and the content,
+// so that a cached tree is only reused for the same content parsed and normalized the same way.
+func KeyOf(path string, content []byte, pipeline transform.Pipeline) Key {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "v%d\x00%s\x00", formatVersion, strings.ToLower(filepath.Ext(path)))
+	for _, pass := range pipeline {
+		_, _ = fmt.Fprintf(h, "%s\x00", pass.Name())
+	}
+	_, _ = h.Write(content)
+	return Key(hex.EncodeToString(h.Sum(nil)))
+}
+
+// Entry is a cached tree with its hash memo.
+type Entry struct {
+	Tree   ast.AST
+	Hashes ast.NodeHashMemo
+}
+
+// Store is a cache of trees backed by the files of a directory, one per key.
+// It is safe to share a directory between concurrent processes, as every file is written atomically.
+type Store struct {
+	dir    string
+	logger slog.Logger
+}
+
+// NewStore creates a Store in the directory `dir`, which is created on the first save.
+func NewStore(dir string, logger slog.Logger) *Store {
+	return &Store{dir: dir, logger: logger}
+}
+
+// record is the serialized form of an Entry: the nodes of the tree in preorder.
+type record struct {
+	Nodes []nodeRecord
+}
+
+type nodeRecord struct {
+	Label      ast.NodeLabelType
+	Value      ast.NodeValueType
+	Start, End int
+	Degree     int
+	Hash       uint64
+}
+
+// Load returns the entry saved with `key`, or false if there is none.
+// Unreadable entries, e.g. truncated files, are reported as missing.
+func (s *Store) Load(key Key) (Entry, bool, error) {
+	file, err := os.Open(s.pathOf(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var r record
+	if err := gob.NewDecoder(file).Decode(&r); err != nil {
+		s.logger.Warn("ignoring an unreadable cache entry", "key", key, "error", err)
+		return Entry{}, false, nil
+	}
+	entry, err := s.entryOf(r)
+	if err != nil {
+		s.logger.Warn("ignoring an invalid cache entry", "key", key, "error", err)
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// Save saves `entry` with `key`, replacing any previous entry.
+func (s *Store) Save(key Key, entry Entry) error {
+	var r record
+	for _, n := range entry.Tree.PreOrderNodes() {
+		hash, ok := entry.Hashes[n.Id]
+		if !ok {
+			return fmt.Errorf("missing hash of a node labeled %s", n.Label)
+		}
+		r.Nodes = append(r.Nodes, nodeRecord{
+			Label:  n.Label,
+			Value:  n.Value,
+			Start:  n.Pos.Start,
+			End:    n.Pos.End,
+			Degree: n.Degree(),
+			Hash:   hash,
+		})
+	}
+
+	path := s.pathOf(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	if err := gob.NewEncoder(file).Encode(r); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// pathOf returns the path of the file of `key`, spread over subdirectories by the first characters of the key.
+func (s *Store) pathOf(key Key) string {
+	return filepath.Join(s.dir, string(key[:2]), string(key[2:])+".gob")
+}
+
+// entryOf rebuilds the tree of `r`, whose nodes get new ids.
+func (s *Store) entryOf(r record) (Entry, error) {
+	tree := ast.NewAST(s.logger)
+	hashes := make(ast.NodeHashMemo, len(r.Nodes))
+
+	// remaining are the numbers of children still to add to the nodes of the current path.
+	type frame struct {
+		node      *ast.Node
+		remaining int
+	}
+	stack := make([]frame, 0)
+	for i, nr := range r.Nodes {
+		var parent *ast.Node
+		idx := -1
+		if i > 0 {
+			for len(stack) > 0 && stack[len(stack)-1].remaining == 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return Entry{}, errors.New("more nodes than the degrees allow")
+			}
+			top := &stack[len(stack)-1]
+			parent = top.node
+			idx = parent.Degree()
+			top.remaining--
+		}
+
+		n, err := tree.Add(parent, idx, nr.Label, nr.Value)
+		if err != nil {
+			return Entry{}, err
+		}
+		n.Pos = ast.NodePos{Start: nr.Start, End: nr.End}
+		hashes[n.Id] = nr.Hash
+		stack = append(stack, frame{node: n, remaining: nr.Degree})
+	}
+	for _, f := range stack {
+		if f.remaining > 0 {
+			return Entry{}, errors.New("fewer nodes than the degrees require")
+		}
+	}
+
+	if len(r.Nodes) == 0 {
+		hashes = nil
+	}
+	return Entry{Tree: tree, Hashes: hashes}, nil
+}
@@ -0,0 +1,114 @@
+package cache_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/cache"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"github.com/Xanonymous-GitHub/gumtree-go/transform"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const source = `package main
+
+func main() {
+	println("hello")
+}
+`
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test a saved tree is loaded back with its hashes", func(t *testing.T) {
+		t.Parallel()
+
+		tree, err := frontend.NewGoTreeGenerator(slog.Logger{}).Generate([]byte(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := cache.NewStore(t.TempDir(), slog.Logger{})
+		key := cache.KeyOf("main.go", []byte(source), nil)
+		if err := store.Save(key, cache.Entry{Tree: tree, Hashes: tree.MakeHashMemo()}); err != nil {
+			t.Fatal(err)
+		}
+
+		entry, ok, err := store.Load(key)
+		if err != nil || !ok {
+			t.Fatalf("Expected the entry to be found, got %v, %v", ok, err)
+		}
+		if ast.RootHash(entry.Tree) != ast.RootHash(tree) {
+			t.Errorf("Expected the loaded tree to be isomorphic to the saved one")
+		}
+
+		nodes, loaded := tree.PreOrderNodes(), entry.Tree.PreOrderNodes()
+		if len(nodes) != len(loaded) {
+			t.Fatalf("Expected %d nodes, got %d", len(nodes), len(loaded))
+		}
+		memo := entry.Tree.MakeHashMemo()
+		for i, n := range loaded {
+			if n.Pos != nodes[i].Pos {
+				t.Errorf("Expected the position %v, got %v", nodes[i].Pos, n.Pos)
+			}
+			if entry.Hashes[n.Id] != memo[n.Id] {
+				t.Errorf("Expected the cached hash of %s to be %d, got %d", n.Label, memo[n.Id], entry.Hashes[n.Id])
+			}
+		}
+	})
+
+	t.Run("Test empty trees are cached", func(t *testing.T) {
+		t.Parallel()
+
+		store := cache.NewStore(t.TempDir(), slog.Logger{})
+		key := cache.KeyOf("empty.txt", nil, nil)
+		if err := store.Save(key, cache.Entry{Tree: ast.NewAST(slog.Logger{})}); err != nil {
+			t.Fatal(err)
+		}
+		entry, ok, err := store.Load(key)
+		if err != nil || !ok || entry.Tree.Root() != nil {
+			t.Errorf("Expected an empty tree, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("Test missing and corrupted entries are not found", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		store := cache.NewStore(dir, *slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+		key := cache.KeyOf("main.go", []byte(source), nil)
+		if _, ok, err := store.Load(key); ok || err != nil {
+			t.Errorf("Expected a missing entry, got %v, %v", ok, err)
+		}
+
+		path := filepath.Join(dir, string(key[:2]), string(key[2:])+".gob")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := store.Load(key); ok || err != nil {
+			t.Errorf("Expected a corrupted entry to be missing, got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("Test keys depend on the extension, the pipeline and the content", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := transform.Pipeline{transform.NewDropLabelsPass(slog.Logger{}, "Comment")}
+		key := cache.KeyOf("main.go", []byte(source), nil)
+		if cache.KeyOf("other/main.go", []byte(source), nil) != key {
+			t.Errorf("Expected the key not to depend on the directory")
+		}
+		for _, other := range []cache.Key{
+			cache.KeyOf("main.txt", []byte(source), nil),
+			cache.KeyOf("main.go", []byte(source), pipeline),
+			cache.KeyOf("main.go", []byte(source+"\n"), nil),
+		} {
+			if other == key {
+				t.Errorf("Expected a different key")
+			}
+		}
+	})
+}
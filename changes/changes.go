@@ -0,0 +1,306 @@
+// Package changes groups the actions of an edit script into high-level changes of the source code,
+// e.g. a method renamed, a parameter added or statements wrapped in an if.
+package changes
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"slices"
+	"strings"
+)
+
+type Kind int
+
+const (
+	// Added is the insertion of a whole subtree.
+	Added Kind = iota
+
+	// Removed is the deletion of a whole subtree.
+	Removed
+
+	// Updated is the change of the value of a node.
+	Updated
+
+	// Renamed is the change of the name of a declaration, e.g. a function or a parameter.
+	Renamed
+
+	// Moved is the move of a subtree, either under a new parent or among its siblings.
+	Moved
+
+	// Wrapped is the insertion of a subtree, e.g. an if statement, around existing subtrees moved into it.
+	Wrapped
+
+	// Unwrapped is the deletion of a subtree whose descendants are moved out of it.
+	Unwrapped
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Updated:
+		return "updated"
+	case Renamed:
+		return "renamed"
+	case Moved:
+		return "moved"
+	case Wrapped:
+		return "wrapped"
+	case Unwrapped:
+		return "unwrapped"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Change is a group of actions of an edit script making a single change of the source code.
+type Change struct {
+	Kind Kind
+
+	// Entity names what is changed, e.g. `method`, `parameter` or `statement`.
+	// It is the label of the node when there is no better name.
+	Entity string
+
+	// Node is the root of the change.
+	// It is a node of the destination tree for Added and Wrapped, where it is the inserted wrapper,
+	// and a node of the source tree otherwise, where it is the deleted wrapper for Unwrapped.
+	Node *ast.Node
+
+	// Actions are the actions of the script making the change, in the order of the script.
+	Actions editscript.Script
+}
+
+func (c *Change) String() string {
+	switch c.Kind {
+	case Updated, Renamed:
+		action := c.Actions[0]
+		if action.Label != c.Node.Label {
+			return fmt.Sprintf("%s %s: %s: %s → %s: %s", c.Entity, c.Kind, c.Node.Label, c.Node.Value, action.Label, action.Value)
+		}
+		return fmt.Sprintf("%s %s: %s → %s", c.Entity, c.Kind, c.Node.Value, action.Value)
+	case Moved:
+		action := c.Actions[0]
+		if action.Parent == c.Node.Parent {
+			return fmt.Sprintf("%s moved within %s: %s", c.Entity, containerOf(action.Parent), describeNode(c.Node))
+		}
+		return fmt.Sprintf("%s moved into %s: %s", c.Entity, containerOf(action.Parent), describeNode(c.Node))
+	case Wrapped, Unwrapped:
+		preposition := "in"
+		if c.Kind == Unwrapped {
+			preposition = "from"
+		}
+		entity := c.Entity
+		if count := c.Actions.CountOf(editscript.Move); count > 1 {
+			entity = fmt.Sprintf("%d %ss", count, entity)
+		}
+		return fmt.Sprintf("%s %s %s %s: %s", entity, c.Kind, preposition, containerOf(c.Node), describeNode(c.Node))
+	default:
+		return fmt.Sprintf("%s %s: %s", c.Entity, c.Kind, describeNode(c.Node))
+	}
+}
+
+// ChangeSet is the list of changes made by an edit script, in the order of their first action in the script.
+type ChangeSet []Change
+
+// CountOf returns the number of changes of the given kind in the change set.
+func (s ChangeSet) CountOf(k Kind) int {
+	count := 0
+	for _, change := range s {
+		if change.Kind == k {
+			count++
+		}
+	}
+	return count
+}
+
+// Group splits the edit script `script`, as made by editscript.Generate or editscript.Simplify, into changes.
+// Every action of the script belongs to exactly one change.
+//
+// Inserted and deleted subtrees are grouped by their root. Subtrees inserted around nodes moved into them are wrapping changes,
+// and the deleted subtrees nodes are moved out of are unwrapping ones. Updates and moves are changes of their own,
+// where the updates of the name of a declaration are renames.
+// Declarations and statements are recognized by the labels of the Go and JSON frontends.
+func Group(script editscript.Script) ChangeSet {
+	g := &grouper{
+		script:   script,
+		inserted: make(map[*ast.Node]int),
+		deleted:  make(map[*ast.Node]int),
+		claimed:  make([]bool, len(script)),
+	}
+	for i, action := range script {
+		switch action.Type {
+		case editscript.Insert:
+			g.inserted[action.Node] = i
+		case editscript.Delete:
+			g.deleted[action.Node] = i
+		}
+	}
+
+	g.groupWrappers()
+	for i, action := range script {
+		if g.claimed[i] {
+			continue
+		}
+		switch action.Type {
+		case editscript.Insert:
+			root := rootOf(action.Node, g.inserted)
+			g.add(Added, entityOf(root), root, g.subtreeOf(root, g.inserted))
+		case editscript.Delete:
+			root := rootOf(action.Node, g.deleted)
+			g.add(Removed, entityOf(root), root, g.subtreeOf(root, g.deleted))
+		case editscript.TreeInsert:
+			g.add(Added, entityOf(action.Node), action.Node, []int{i})
+		case editscript.TreeDelete:
+			g.add(Removed, entityOf(action.Node), action.Node, []int{i})
+		case editscript.Update:
+			kind := Updated
+			if isRenamable(action.Node) {
+				kind = Renamed
+			}
+			g.add(kind, entityOf(action.Node), action.Node, []int{i})
+		case editscript.Move:
+			g.add(Moved, entityOf(action.Node), action.Node, []int{i})
+		}
+	}
+
+	slices.SortStableFunc(g.changes, func(a, b indexedChange) int {
+		return a.first - b.first
+	})
+	changes := make(ChangeSet, 0, len(g.changes))
+	for _, change := range g.changes {
+		changes = append(changes, change.Change)
+	}
+	return changes
+}
+
+// indexedChange is a change with the index of its first action in the script.
+type indexedChange struct {
+	Change
+	first int
+}
+
+// grouper holds the state of the grouping of a script.
+type grouper struct {
+	script editscript.Script
+
+	// inserted and deleted map the nodes inserted and deleted by the script to the index of their action.
+	inserted map[*ast.Node]int
+	deleted  map[*ast.Node]int
+
+	// claimed tells whether each action of the script already belongs to a change.
+	claimed []bool
+	changes []indexedChange
+}
+
+// add makes a change of the actions at the indices `indices` of the script, and claims them.
+func (g *grouper) add(kind Kind, entity string, node *ast.Node, indices []int) {
+	slices.Sort(indices)
+	actions := make(editscript.Script, 0, len(indices))
+	for _, i := range indices {
+		actions = append(actions, g.script[i])
+		g.claimed[i] = true
+	}
+	g.changes = append(g.changes, indexedChange{
+		Change: Change{Kind: kind, Entity: entity, Node: node, Actions: actions},
+		first:  indices[0],
+	})
+}
+
+// groupWrappers makes the wrapping changes, then the unwrapping ones, of the moves under inserted nodes and from deleted nodes.
+func (g *grouper) groupWrappers() {
+	wrappers, wrapped := make([]*ast.Node, 0), make(map[*ast.Node][]int)
+	for i, action := range g.script {
+		if action.Type != editscript.Move || action.Parent == nil {
+			continue
+		}
+		if _, ok := g.inserted[action.Parent]; ok {
+			root := rootOf(action.Parent, g.inserted)
+			if _, ok := wrapped[root]; !ok {
+				wrappers = append(wrappers, root)
+			}
+			wrapped[root] = append(wrapped[root], i)
+		}
+	}
+	for _, root := range wrappers {
+		g.add(Wrapped, entityOfMoves(g.script, wrapped[root]), root, append(g.subtreeOf(root, g.inserted), wrapped[root]...))
+	}
+
+	unwrappers, unwrapped := make([]*ast.Node, 0), make(map[*ast.Node][]int)
+	for i, action := range g.script {
+		if action.Type != editscript.Move || g.claimed[i] || action.Node.Parent == nil {
+			continue
+		}
+		if _, ok := g.deleted[action.Node.Parent]; ok {
+			root := rootOf(action.Node.Parent, g.deleted)
+			if _, ok := unwrapped[root]; !ok {
+				unwrappers = append(unwrappers, root)
+			}
+			unwrapped[root] = append(unwrapped[root], i)
+		}
+	}
+	for _, root := range unwrappers {
+		g.add(Unwrapped, entityOfMoves(g.script, unwrapped[root]), root, append(g.subtreeOf(root, g.deleted), unwrapped[root]...))
+	}
+}
+
+// subtreeOf returns the indices of the actions inserting or deleting `root` and its descendants, given by `actions`,
+// skipping the descendants of nodes that are not in `actions`, e.g. moved ones.
+func (g *grouper) subtreeOf(root *ast.Node, actions map[*ast.Node]int) []int {
+	indices := make([]int, 0)
+	stack := []*ast.Node{root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i, ok := actions[n]
+		if !ok || g.claimed[i] {
+			continue
+		}
+		indices = append(indices, i)
+		stack = append(stack, n.OrderedChildren()...)
+	}
+	return indices
+}
+
+// rootOf returns the highest ancestor of `n` such that it and all the nodes between them are in `actions`.
+func rootOf(n *ast.Node, actions map[*ast.Node]int) *ast.Node {
+	for n.Parent != nil {
+		if _, ok := actions[n.Parent]; !ok {
+			break
+		}
+		n = n.Parent
+	}
+	return n
+}
+
+// entityOfMoves returns the entity of the nodes moved by the actions at `indices`, or `subtree` if they differ.
+func entityOfMoves(script editscript.Script, indices []int) string {
+	entity := entityOf(script[indices[0]].Node)
+	for _, i := range indices[1:] {
+		if entityOf(script[i].Node) != entity {
+			return "subtree"
+		}
+	}
+	return entity
+}
+
+// describeNode describes `n` with its value, or the names it declares, e.g. `FuncDecl: Area [10,52)`.
+func describeNode(n *ast.Node) string {
+	return editscript.DescribeNamedNode(n, nameOf(n))
+}
+
+// nameOf returns the value of `n`, or the names it declares.
+func nameOf(n *ast.Node) string {
+	if n.Value != "" {
+		return string(n.Value)
+	}
+	names := make([]string, 0)
+	for _, child := range n.OrderedChildren() {
+		if isName(child) {
+			names = append(names, string(child.Value))
+		}
+	}
+	return strings.Join(names, ", ")
+}
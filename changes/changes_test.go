@@ -0,0 +1,194 @@
+package changes_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/changes"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"testing"
+)
+
+// groupOf groups the script turning `src` into `dst`, mapping the nodes with the same name.
+func groupOf(t *testing.T, src, dst *asttest.Builder) changes.ChangeSet {
+	t.Helper()
+
+	mappings := comparator.NewMappingStore()
+	for name, n := range src.Nodes {
+		if partner, ok := dst.Nodes[name]; ok {
+			mappings.Add(n, partner)
+		}
+	}
+	script := editscript.Generate(src.Tree, dst.Tree, mappings)
+	changeSet := changes.Group(script)
+
+	count := 0
+	for _, change := range changeSet {
+		count += len(change.Actions)
+	}
+	if count != len(script) {
+		t.Errorf("Expected the changes to hold the %d actions of the script, got %d", len(script), count)
+	}
+	return changeSet
+}
+
+func assertChanges(t *testing.T, changeSet changes.ChangeSet, expected ...string) {
+	t.Helper()
+
+	if len(changeSet) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(changeSet), changeSet)
+	}
+	for i, change := range changeSet {
+		if change.String() != expected[i] {
+			t.Errorf("Expected change %d to be %q, got %q", i, expected[i], change.String())
+		}
+	}
+}
+
+// method builds the method `func (s *T) <name>(<params>) {}`, with the empty block `body`.
+func method(name ast.NodeValueType, params ...ast.NodeValueType) *asttest.Builder {
+	b := asttest.NewBuilder().
+		Add("", "f", "FuncDecl", "").
+		Add("f", "recv", "FieldList", "").
+		Add("recv", "recvField", "Field", "").
+		Add("recvField", "recvName", "Ident", "s").
+		Add("recvField", "recvType", "Ident", "T").
+		Add("f", "name", "Ident", name).
+		Add("f", "type", "FuncType", "").
+		Add("type", "params", "FieldList", "")
+	for _, param := range params {
+		b.Add("params", string(param), "Field", "").
+			Add(string(param), string(param)+"Name", "Ident", param).
+			Add(string(param), string(param)+"Type", "Ident", "int")
+	}
+	return b.Add("f", "body", "BlockStmt", "")
+}
+
+// statement adds the statement `<name>()` named `name` under the node named `parent` of `b`.
+func statement(b *asttest.Builder, parent, name string) *asttest.Builder {
+	return b.Add(parent, name, "ExprStmt", "").Add(name, name+"Call", "Ident", ast.NodeValueType(name))
+}
+
+// ifStmt adds the statement `if ok {}` named `if`, with the block `then`, under the node named `parent` of `b`.
+func ifStmt(b *asttest.Builder, parent string) *asttest.Builder {
+	return b.Add(parent, "if", "IfStmt", "").Add("if", "cond", "Ident", "ok").Add("if", "then", "BlockStmt", "")
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test identical trees have no changes", func(t *testing.T) {
+		t.Parallel()
+
+		assertChanges(t, groupOf(t, method("Area"), method("Area")))
+	})
+
+	t.Run("Test a method renamed", func(t *testing.T) {
+		t.Parallel()
+
+		changeSet := groupOf(t, method("Area"), method("Size"))
+		assertChanges(t, changeSet, "method renamed: Area → Size")
+		if changeSet.CountOf(changes.Renamed) != 1 {
+			t.Errorf("Expected 1 rename, got %d", changeSet.CountOf(changes.Renamed))
+		}
+	})
+
+	t.Run("Test a parameter added and another one renamed", func(t *testing.T) {
+		t.Parallel()
+
+		src := method("Total", "shapes")
+		dst := method("Total", "shapes", "limit")
+		dst.Nodes["shapesName"].Value = "items"
+
+		changeSet := groupOf(t, src, dst)
+		assertChanges(t, changeSet, "parameter added: Field: limit", "parameter renamed: shapes → items")
+		if len(changeSet[0].Actions) != 3 {
+			t.Errorf("Expected the addition to hold 3 inserts, got %d", len(changeSet[0].Actions))
+		}
+	})
+
+	t.Run("Test simplified scripts give the same changes", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := method("Total", "shapes"), method("Total", "shapes", "limit")
+		script := editscript.Generate(src.Tree, dst.Tree, comparator.NewMappingStore())
+		full, simplified := changes.Group(script), changes.Group(editscript.Simplify(script))
+
+		if len(full) != len(simplified) {
+			t.Fatalf("Expected %d changes, got %d", len(full), len(simplified))
+		}
+		for i := range full {
+			if full[i].String() != simplified[i].String() {
+				t.Errorf("Expected change %d to be %q, got %q", i, full[i].String(), simplified[i].String())
+			}
+		}
+	})
+
+	t.Run("Test a parameter removed", func(t *testing.T) {
+		t.Parallel()
+
+		assertChanges(t, groupOf(t, method("Total", "shapes", "limit"), method("Total", "shapes")), "parameter removed: Field: limit")
+	})
+
+	t.Run("Test a statement moved into a block", func(t *testing.T) {
+		t.Parallel()
+
+		src := ifStmt(statement(method("Area"), "body", "s1"), "body")
+		dst := statement(ifStmt(method("Area"), "body"), "then", "s1")
+
+		changeSet := groupOf(t, src, dst)
+		assertChanges(t, changeSet, "statement moved into block: ExprStmt")
+		if changeSet[0].Node != src.Nodes["s1"] {
+			t.Errorf("Expected the change to be rooted at the moved statement")
+		}
+	})
+
+	t.Run("Test statements wrapped in an if and unwrapped", func(t *testing.T) {
+		t.Parallel()
+
+		src := statement(statement(method("Area"), "body", "s1"), "body", "s2")
+		dst := statement(statement(ifStmt(method("Area"), "body"), "then", "s1"), "then", "s2")
+
+		wrapped := groupOf(t, src, dst)
+		assertChanges(t, wrapped, "2 statements wrapped in if: IfStmt")
+		if wrapped[0].Kind != changes.Wrapped || wrapped[0].Node != dst.Nodes["if"] {
+			t.Errorf("Expected a wrapping change rooted at the inserted if, got %v", wrapped[0])
+		}
+		if len(wrapped[0].Actions) != 5 {
+			t.Errorf("Expected 3 inserts and 2 moves, got %v", wrapped[0].Actions)
+		}
+
+		unwrapped := groupOf(t, dst, src)
+		assertChanges(t, unwrapped, "2 statements unwrapped from if: IfStmt")
+		if unwrapped[0].Node != dst.Nodes["if"] {
+			t.Errorf("Expected the unwrapping change to be rooted at the deleted if")
+		}
+	})
+
+	t.Run("Test a JSON key renamed and a value added", func(t *testing.T) {
+		t.Parallel()
+
+		src := asttest.NewBuilder().
+			Add("", "o", "object", "").
+			Add("o", "m", "member", "license").
+			Add("m", "v", "string", `"MIT"`)
+		dst := asttest.NewBuilder().
+			Add("", "o", "object", "").
+			Add("o", "m", "member", "licence").
+			Add("m", "v", "string", `"MIT"`).
+			Add("o", "n", "member", "private").
+			Add("n", "w", "boolean", "true")
+
+		assertChanges(t, groupOf(t, src, dst), "key renamed: license → licence", "key added: member: private")
+	})
+}
+
+func TestChange_String(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test unknown kinds", func(t *testing.T) {
+		if changes.Kind(42).String() != "Kind(42)" {
+			t.Errorf("Expected Kind(42), got %s", changes.Kind(42))
+		}
+	})
+}
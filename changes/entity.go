@@ -0,0 +1,145 @@
+package changes
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"slices"
+	"strings"
+)
+
+// entityOf names what `n` is in the source code, e.g. `method` for the FuncDecl of a method.
+// The names of declarations stand for the declarations themselves.
+func entityOf(n *ast.Node) string {
+	if isName(n) {
+		return entityOf(n.Parent)
+	}
+
+	switch n.Label {
+	case "FuncDecl":
+		if children := n.OrderedChildren(); len(children) > 0 && children[0].Label == "FieldList" {
+			return "method"
+		}
+		return "function"
+	case "Field":
+		return fieldEntityOf(n)
+	case "TypeSpec":
+		return "type"
+	case "ValueSpec":
+		if n.Parent != nil && n.Parent.Value == "const" {
+			return "constant"
+		}
+		return "variable"
+	case "ImportSpec":
+		return "import"
+	case "File":
+		return "file"
+	case "BlockStmt":
+		return "block"
+	case frontend.JSONMemberLabel:
+		return "key"
+	case frontend.JSONObjectLabel, frontend.JSONArrayLabel, frontend.JSONStringLabel,
+		frontend.JSONNumberLabel, frontend.JSONBooleanLabel, frontend.JSONNullLabel:
+		return "value"
+	}
+
+	label := string(n.Label)
+	if strings.HasSuffix(label, "Stmt") || strings.HasSuffix(label, "Decl") {
+		return "statement"
+	}
+	return label
+}
+
+// fieldEntityOf names the Field `n` after the FieldList holding it.
+func fieldEntityOf(n *ast.Node) string {
+	list := n.Parent
+	if list == nil || list.Parent == nil {
+		return "field"
+	}
+
+	switch list.Parent.Label {
+	case "FuncDecl":
+		return "receiver"
+	case "InterfaceType":
+		return "method"
+	case "FuncType":
+		// The FieldLists of a FuncType are its type parameters, parameters and results, the first and last ones being optional.
+		lists := make([]*ast.Node, 0)
+		for _, child := range list.Parent.OrderedChildren() {
+			if child.Label == "FieldList" {
+				lists = append(lists, child)
+			}
+		}
+		switch idx := slices.Index(lists, list); {
+		case len(lists) == 3 && idx == 0:
+			return "type parameter"
+		case len(lists) > 1 && idx == len(lists)-1:
+			return "result"
+		default:
+			return "parameter"
+		}
+	case "TypeSpec":
+		return "type parameter"
+	default:
+		return "field"
+	}
+}
+
+// isRenamable tells whether updating the value of `n` renames a declaration, i.e. `n` is a name or a JSON member.
+func isRenamable(n *ast.Node) bool {
+	return isName(n) || n.Label == frontend.JSONMemberLabel
+}
+
+// isName tells whether `n` is the Ident naming a declaration, e.g. a FuncDecl.
+//
+// The names of a Field are followed by its type, and the names of a ValueSpec may be followed by its type or values,
+// so that only the first child of a ValueSpec is known to be a name.
+func isName(n *ast.Node) bool {
+	if n.Label != "Ident" || n.Parent == nil {
+		return false
+	}
+
+	siblings := n.Parent.OrderedChildren()
+	idx := slices.Index(siblings, n)
+	switch n.Parent.Label {
+	case "FuncDecl":
+		return true
+	case "TypeSpec", "ValueSpec":
+		return idx == 0
+	case "Field":
+		// The type is the last child, unless it is followed by a tag.
+		for _, sibling := range siblings[idx+1:] {
+			if sibling.Label != "BasicLit" {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// containerOf names the node `n` holding other nodes, e.g. `if` for an IfStmt.
+func containerOf(n *ast.Node) string {
+	if n == nil {
+		return "root"
+	}
+
+	switch n.Label {
+	case "IfStmt":
+		return "if"
+	case "ForStmt", "RangeStmt":
+		return "for"
+	case "SwitchStmt", "TypeSwitchStmt":
+		return "switch"
+	case "SelectStmt":
+		return "select"
+	case "CaseClause", "CommClause":
+		return "case"
+	case "FuncLit":
+		return "function literal"
+	case frontend.JSONObjectLabel, frontend.JSONArrayLabel:
+		return string(n.Label)
+	default:
+		return entityOf(n)
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+)
+
+// environment is what commands use to talk to the outside world.
+type environment struct {
+	stdout, stderr io.Writer
+	logger         slog.Logger
+}
+
+type command struct {
+	// usage is the synopsis of the command, without the program name.
+	usage string
+
+	// summary is a one-line description of the command.
+	summary string
+
+	run func(env *environment, args []string) error
+}
+
+var commands = map[string]command{
+	"clones":   clonesCommand,
+	"diff":     diffCommand,
+	"git-diff": gitDiffCommand,
+	"merge":    mergeCommand,
+	"parse":    parseCommand,
+	"stats":    statsCommand,
+	"webdiff":  webdiffCommand,
+}
+
+// errChangesFound makes a command exit with status 1 without printing any message,
+// e.g. `git-diff -exit-code` finding changes or `merge` finding conflicts.
+var errChangesFound = errors.New("changes found")
+
+// usageError is returned by commands invoked with wrong arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func newUsageError(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// flagError is returned by commands whose flags cannot be parsed, which the flag set has already reported with the usage.
+type flagError struct {
+	err error
+}
+
+func (e *flagError) Error() string {
+	return e.err.Error()
+}
+
+func (e *flagError) Unwrap() error {
+	return e.err
+}
+
+// Run runs the gumtree command line with `args` (without the program name) and returns the exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	env := &environment{
+		stdout: stdout,
+		stderr: stderr,
+		logger: *slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "gumtree: unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	err := cmd.run(env, args[1:])
+	var usageErr *usageError
+	var flagErr *flagError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errChangesFound):
+		return 1
+	case errors.As(err, &flagErr):
+		return 2
+	case errors.As(err, &usageErr):
+		_, _ = fmt.Fprintf(stderr, "gumtree %s: %v\nusage: gumtree %s\n", args[0], err, cmd.usage)
+		return 2
+	default:
+		_, _ = fmt.Fprintf(stderr, "gumtree %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "usage: gumtree <command> [arguments]")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet creates the flag set of a command with the synopsis `usage`.
+// Parsing errors are returned rather than exiting the program.
+func newFlagSet(env *environment, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(usage, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(env.stderr, "usage: gumtree %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the flags of a command, returning a flagError if they are invalid, e.g. `-drop '*'`.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &flagError{err: err}
+}
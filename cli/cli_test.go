@@ -0,0 +1,274 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Xanonymous-GitHub/gumtree-go/cli"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	srcGo = "package p\n\n// f adds.\nfunc f() int {\n\t// one\n\treturn (1 + 2)\n}\n\nvar s = \"a   b\"\n"
+	dstGo = "package p\n\n// f adds.\nfunc f() int {\n\t// one\n\treturn (1 + 3)\n}\n\nvar s = \"a   b\"\n"
+
+	cloneGo = "package p\n\nfunc %s(a, b int) int {\n\tif a > b {\n\t\treturn a - b\n\t}\n\treturn b - a\n}\n"
+
+	baseGo  = "package p\n\nvar x = 1\n\nvar y = 1\n"
+	leftGo  = "package p\n\nvar x = 2\n\nvar y = 1\n"
+	rightGo = "package p\n\nvar x = 1\n\nvar y = 2\n"
+	otherGo = "package p\n\nvar x = 3\n\nvar y = 1\n"
+)
+
+// writeFiles writes the files of `contents`, keyed by their path relative to `dir`.
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+
+	for path, content := range contents {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// run runs the command line with `args` and returns its exit code, its standard output and its standard error.
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":                     srcGo,
+		"b.go":                     dstGo,
+		"config.json":              `{"minHeight": 1}`,
+		"invalid.json":             `{"minDice": 2}`,
+		"misspelled.json":          `{"minHight": 1}`,
+		"base.go":                  baseGo,
+		"left.go":                  leftGo,
+		"right.go":                 rightGo,
+		"other.go":                 otherGo,
+		"clones/abs.go":            strings.Replace(cloneGo, "%s", "abs", 1),
+		"clones/dist.go":           strings.Replace(cloneGo, "%s", "dist", 1),
+		"clones/exact/abs.go":      strings.Replace(cloneGo, "%s", "abs", 1),
+		"clones/exact/readme.txt":  "not code\n",
+		"clones/.hidden/abs.go":    strings.Replace(cloneGo, "%s", "abs", 1),
+		"clones/.hidden/README.md": "hidden\n",
+	})
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	cases := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout []string
+		stderr []string
+
+		// notStdout are the texts the standard output must not contain.
+		notStdout []string
+	}{
+		{name: "Test no command prints the usage", code: 2, stderr: []string{"usage: gumtree <command>", "diff"}},
+		{name: "Test help", args: []string{"help"}, code: 0, stderr: []string{"commands:"}},
+		{name: "Test an unknown command", args: []string{"frobnicate"}, code: 2, stderr: []string{`unknown command "frobnicate"`}},
+		{name: "Test a missing argument", args: []string{"parse"}, code: 2, stderr: []string{"expected exactly one file", "usage: gumtree parse"}},
+		{name: "Test a missing file", args: []string{"parse", path("missing.go")}, code: 1, stderr: []string{"gumtree parse:", "missing.go"}},
+		{name: "Test an unknown flag", args: []string{"diff", "-frobnicate", path("a.go"), path("b.go")}, code: 2, stderr: []string{"-frobnicate"}},
+
+		{
+			name:   "Test parsing a file",
+			args:   []string{"parse", path("a.go")},
+			stdout: []string{"File [", "FuncDecl [", "Comment: // one [", "ParenExpr [", `BasicLit: "a   b"`},
+		},
+		{
+			name:      "Test -drop removes the nodes with the labels",
+			args:      []string{"parse", "-drop", "Comment,CommentGroup", path("a.go")},
+			stdout:    []string{"FuncDecl ["},
+			notStdout: []string{"Comment"},
+		},
+		{name: "Test -drop rejects *", args: []string{"parse", "-drop", "*", path("a.go")}, code: 2, stderr: []string{"* is not accepted"}},
+		{
+			name:      "Test -collapse replaces single-child nodes by their child",
+			args:      []string{"parse", "-collapse", "ParenExpr", path("a.go")},
+			stdout:    []string{"BinaryExpr: +"},
+			notStdout: []string{"ParenExpr"},
+		},
+		{
+			name:      "Test -normalize-ws collapses the whitespace of values",
+			args:      []string{"parse", "-normalize-ws", "*", path("a.go")},
+			stdout:    []string{`BasicLit: "a b"`},
+			notStdout: []string{`"a   b"`},
+		},
+
+		{
+			name:   "Test diffing files",
+			args:   []string{"diff", "-color", "never", path("a.go"), path("b.go")},
+			stdout: []string{"-\treturn (1 + 2)\n", "+\treturn (1 + 3)  [UPD 2→3]\n"},
+		},
+		{name: "Test diffing identical files prints nothing", args: []string{"diff", path("a.go"), path("a.go")}},
+		{
+			name:   "Test diffing with a config file and bounded matching",
+			args:   []string{"diff", "-color", "never", "-config", path("config.json"), "-bounded", "-verify", path("a.go"), path("b.go")},
+			stdout: []string{"[UPD 2→3]"},
+		},
+		{name: "Test an invalid config file is rejected", args: []string{"diff", "-config", path("invalid.json"), path("a.go"), path("b.go")}, code: 2, stderr: []string{"minDice 2"}},
+		{name: "Test a misspelled config parameter is rejected", args: []string{"diff", "-config", path("misspelled.json"), path("a.go"), path("b.go")}, code: 2, stderr: []string{"minHight"}},
+		{name: "Test a file and a directory cannot be compared", args: []string{"diff", path("a.go"), path("clones")}, code: 2, stderr: []string{"expected two files or two directories"}},
+		{name: "Test an unknown color mode", args: []string{"diff", "-color", "sometimes", path("a.go"), path("b.go")}, code: 2, stderr: []string{`unknown color mode "sometimes"`}},
+
+		{
+			name:   "Test the stats of a diff",
+			args:   []string{"stats", path("a.go"), path("b.go")},
+			stdout: []string{`"scriptLength": 1`, `"update": 1`},
+		},
+		{name: "Test an invalid cost", args: []string{"stats", "-cost", "rename=1", path("a.go"), path("b.go")}, code: 2, stderr: []string{`unknown action type "rename"`}},
+
+		{
+			name:   "Test merging independent changes",
+			args:   []string{"merge", path("base.go"), path("left.go"), path("right.go")},
+			stdout: []string{"var x = 2\n\nvar y = 2\n"},
+		},
+		{
+			name:   "Test merging conflicting changes exits with 1",
+			args:   []string{"merge", path("base.go"), path("left.go"), path("other.go")},
+			code:   1,
+			stdout: []string{"var x = 2\n"},
+			stderr: []string{"conflict:"},
+		},
+		{name: "Test merging without the right version", args: []string{"merge", path("base.go"), path("left.go")}, code: 2, stderr: []string{"expected a base, a left and a right file"}},
+
+		{
+			name:      "Test finding clones",
+			args:      []string{"clones", path("clones")},
+			stdout:    []string{"type-2 clones of 27 nodes (3):", "dist.go:1-8 File", "dist.go:3-8 BlockStmt"},
+			notStdout: []string{".hidden"},
+		},
+		{
+			name:      "Test finding exact clones only",
+			args:      []string{"clones", "-exact", path("clones")},
+			stdout:    []string{"type-1 clones of 27 nodes (2):", filepath.Join("exact", "abs.go") + ":1-8 File"},
+			notStdout: []string{"type-2", ".hidden"},
+		},
+		{name: "Test finding clones in a file", args: []string{"clones", path("a.go"), path("b.go")}, code: 2, stderr: []string{"expected exactly one directory"}},
+
+		{name: "Test an unknown git-diff format", args: []string{"git-diff", "-format", "patch", "HEAD~1", "HEAD"}, code: 2, stderr: []string{`unknown format "patch"`}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, stdout, stderr := run(tc.args...)
+			if code != tc.code {
+				t.Errorf("Expected the exit code %d, got %d with the error %q", tc.code, code, stderr)
+			}
+			for _, expected := range tc.stdout {
+				if !strings.Contains(stdout, expected) {
+					t.Errorf("Expected the output to contain %q, got %q", expected, stdout)
+				}
+			}
+			for _, unexpected := range tc.notStdout {
+				if strings.Contains(stdout, unexpected) {
+					t.Errorf("Expected the output not to contain %q, got %q", unexpected, stdout)
+				}
+			}
+			for _, expected := range tc.stderr {
+				if !strings.Contains(stderr, expected) {
+					t.Errorf("Expected the error to contain %q, got %q", expected, stderr)
+				}
+			}
+			if tc.stdout == nil && tc.notStdout == nil && stdout != "" {
+				t.Errorf("Expected no output, got %q", stdout)
+			}
+		})
+	}
+
+	t.Run("Test the clones as JSON", func(t *testing.T) {
+		t.Parallel()
+
+		code, stdout, stderr := run("clones", "-json", path("clones"))
+		if code != 0 {
+			t.Fatalf("Expected the exit code 0, got %d with the error %q", code, stderr)
+		}
+		var classes []struct {
+			Type   string `json:"type"`
+			Clones []struct {
+				Path string `json:"path"`
+			} `json:"clones"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &classes); err != nil {
+			t.Fatalf("Expected JSON, got %v in %q", err, stdout)
+		}
+		if len(classes) == 0 || len(classes[0].Clones) < 2 {
+			t.Errorf("Expected a class of at least 2 clones, got %+v", classes)
+		}
+	})
+
+	t.Run("Test the stats of directories", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := t.TempDir(), t.TempDir()
+		writeFiles(t, src, map[string]string{"a.go": srcGo, "removed.go": baseGo})
+		writeFiles(t, dst, map[string]string{"a.go": dstGo, "added.json": `{"a": 1}`})
+
+		code, stdout, stderr := run("stats", src, dst)
+		if code != 0 {
+			t.Fatalf("Expected the exit code 0, got %d with the error %q", code, stderr)
+		}
+		var stats struct {
+			Files []struct {
+				Status string `json:"status"`
+			} `json:"files"`
+			Total struct {
+				Actions map[string]int `json:"actions"`
+			} `json:"total"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+			t.Fatalf("Expected JSON, got %v in %q", err, stdout)
+		}
+		if len(stats.Files) != 3 || stats.Total.Actions["update"] != 1 {
+			t.Errorf("Expected 3 files and 1 update in total, got %s", stdout)
+		}
+	})
+
+	t.Run("Test -cache saves the parsed trees", func(t *testing.T) {
+		t.Parallel()
+
+		cacheDir := t.TempDir()
+		countEntries := func() int {
+			count := 0
+			_ = filepath.WalkDir(cacheDir, func(path string, _ os.DirEntry, _ error) error {
+				if strings.HasSuffix(path, ".gob") {
+					count++
+				}
+				return nil
+			})
+			return count
+		}
+
+		code, first, stderr := run("diff", "-color", "never", "-cache", cacheDir, path("a.go"), path("b.go"))
+		if code != 0 {
+			t.Fatalf("Expected the exit code 0, got %d with the error %q", code, stderr)
+		}
+		if entries := countEntries(); entries != 2 {
+			t.Errorf("Expected an entry per file, got %d", entries)
+		}
+
+		code, second, stderr := run("diff", "-color", "never", "-cache", cacheDir, path("a.go"), path("b.go"))
+		if code != 0 || second != first {
+			t.Errorf("Expected the same diff from the cache, got %q with the error %q", second, stderr)
+		}
+		if entries := countEntries(); entries != 2 {
+			t.Errorf("Expected the entries to be reused, got %d", entries)
+		}
+	})
+}
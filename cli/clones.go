@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/clones"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const clonesUsage = "clones [flags] <dir>"
+
+var clonesCommand = command{
+	usage:   clonesUsage,
+	summary: "find the duplicated code of the files of a directory",
+	run:     runClones,
+}
+
+// cloneClass is the JSON output of a clone class.
+type cloneClass struct {
+	Type   string          `json:"type"`
+	Size   int             `json:"size"`
+	Height int             `json:"height"`
+	Clones []cloneLocation `json:"clones"`
+}
+
+type cloneLocation struct {
+	Path      string `json:"path"`
+	Label     string `json:"label"`
+	StartLine int    `json:"startLine,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+}
+
+func runClones(env *environment, args []string) error {
+	flags := newFlagSet(env, clonesUsage)
+	var transforms transformFlags
+	transforms.register(flags)
+	minHeight := flags.Int("min-height", clones.DefaultMinHeight, "minimum `height` of the cloned subtrees")
+	minSize := flags.Int("min-size", clones.DefaultMinSize, "minimum number of `nodes` of the cloned subtrees")
+	exactOnly := flags.Bool("exact", false, "only report identical clones, leaving out the ones with renamed identifiers or changed literals")
+	asJSON := flags.Bool("json", false, "print the clone classes as JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return newUsageError("expected exactly one directory")
+	}
+
+	detector := clones.NewDetector(clones.Options{MinHeight: *minHeight, MinSize: *minSize})
+	opts := diffOptions(env, &transforms)
+	err := filepath.WalkDir(flags.Arg(0), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != flags.Arg(0) && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tree, hashes, err := diff.ParseHashed(path, content, opts)
+		switch {
+		case errors.Is(err, frontend.ErrUnsupportedFile):
+			return nil
+		case err != nil:
+			env.logger.Warn("skipping a file that cannot be parsed", "path", path, "error", err)
+			return nil
+		}
+		detector.AddHashed(path, content, tree, hashes)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	classes := detector.Classes()
+	if *exactOnly {
+		exact := classes[:0]
+		for _, class := range classes {
+			if class.Type == clones.Type1 {
+				exact = append(exact, class)
+			}
+		}
+		classes = exact
+	}
+
+	if *asJSON {
+		return writeJSON(env, cloneClassesOf(classes))
+	}
+	return printCloneClasses(env, classes)
+}
+
+func cloneClassesOf(classes []clones.Class) []cloneClass {
+	result := make([]cloneClass, 0, len(classes))
+	for _, class := range classes {
+		c := cloneClass{Type: class.Type.String(), Size: class.Size, Height: class.Height, Clones: make([]cloneLocation, 0, len(class.Locations))}
+		for _, location := range class.Locations {
+			c.Clones = append(c.Clones, cloneLocation{
+				Path:      location.Path,
+				Label:     string(location.Node.Label),
+				StartLine: location.StartLine,
+				EndLine:   location.EndLine,
+			})
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// printCloneClasses prints each class followed by its clones, e.g. `  shapes.go:3-8 FuncDecl`.
+func printCloneClasses(env *environment, classes []clones.Class) error {
+	for i, class := range classes {
+		var b strings.Builder
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		_, _ = fmt.Fprintf(&b, "%s clones of %d nodes (%d):\n", class.Type, class.Size, len(class.Locations))
+		for _, location := range class.Locations {
+			_, _ = fmt.Fprintf(&b, "  %s", location.Path)
+			switch {
+			case location.StartLine == 0:
+			case location.StartLine == location.EndLine:
+				_, _ = fmt.Fprintf(&b, ":%d", location.StartLine)
+			default:
+				_, _ = fmt.Fprintf(&b, ":%d-%d", location.StartLine, location.EndLine)
+			}
+			_, _ = fmt.Fprintf(&b, " %s\n", location.Node.Label)
+		}
+		if _, err := fmt.Fprint(env.stdout, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
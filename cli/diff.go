@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/render"
+	"io"
+	"os"
+)
+
+const diffUsage = "diff [flags] <src> <dst>"
+
+var diffCommand = command{
+	usage:   diffUsage,
+	summary: "print the structural diff of two files or directories as an annotated unified diff",
+	run:     runDiff,
+}
+
+func runDiff(env *environment, args []string) error {
+	fs := newFlagSet(env, diffUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	context := fs.Int("context", render.DefaultContext, "number of unchanged `lines` shown around changes")
+	color := registerColorFlag(fs)
+	htmlOutput := fs.String("html", "", "write a side-by-side HTML report to `file` instead of printing the diff (- for the standard output)")
+	renameThreshold := registerRenameThresholdFlag(fs)
+	listChanges := fs.Bool("changes", false, "list the changes, e.g. a method renamed, instead of printing the diff")
+	verify := fs.Bool("verify", false, "check that replaying the edit script on the source tree yields the destination tree")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return newUsageError("expected a source and a destination file or directory")
+	}
+
+	textRenderer := render.NewTextRenderer(color.enabledFor(env.stdout))
+	textRenderer.Context = *context
+	var renderer render.Renderer = textRenderer
+	if *listChanges {
+		renderer = render.NewChangesRenderer()
+	}
+
+	bothDirs, err := areDirectories(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if bothDirs {
+		if *htmlOutput != "" {
+			return newUsageError("-html only supports files, use webdiff to browse the diff of directories")
+		}
+		diffs, err := dirdiff.Compare(fs.Arg(0), fs.Arg(1), dirdiffOptions(env, &transforms, *renameThreshold))
+		if err != nil {
+			return err
+		}
+		if *verify {
+			for _, d := range diffs {
+				if err := verifyResult(env, d.Result); err != nil {
+					return err
+				}
+			}
+		}
+		return printFileDiffs(env, diffs, renderer, textRenderer.Color)
+	}
+
+	result, err := diff.Files(fs.Arg(0), fs.Arg(1), diffOptions(env, &transforms))
+	if err != nil {
+		return err
+	}
+	if *verify {
+		if err := verifyResult(env, result); err != nil {
+			return err
+		}
+	}
+
+	if *htmlOutput != "" {
+		return writeHTMLReport(env, *htmlOutput, result)
+	}
+	return renderer.Render(env.stdout, result)
+}
+
+// verifyResult replays the edit script of `result`, see editscript.Verify. Results of files that could not be compared are nil.
+func verifyResult(env *environment, result *diff.Result) error {
+	if result == nil {
+		return nil
+	}
+	if err := editscript.Verify(result.Src, result.Dst, result.Script, env.logger); err != nil {
+		return fmt.Errorf("%s: %w", displayName(result), err)
+	}
+	return nil
+}
+
+func displayName(result *diff.Result) string {
+	if result.DstPath != "" {
+		return result.DstPath
+	}
+	return result.SrcPath
+}
+
+// printFileDiffs prints a header line per changed file, e.g. `renamed a/x.go → b/y.go (87% similar)`, followed by its diff.
+// Files that cannot be compared are reported without failing the command.
+func printFileDiffs(env *environment, diffs []dirdiff.FileDiff, renderer render.Renderer, color bool) error {
+	for _, d := range diffs {
+		header := headerOf(d.FilePair)
+		if color {
+			header = "\x1b[1m" + header + "\x1b[0m"
+		}
+		if _, err := fmt.Fprintln(env.stdout, header); err != nil {
+			return err
+		}
+
+		if d.Err != nil {
+			if _, err := fmt.Fprintf(env.stdout, "cannot compare: %v\n", d.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := renderer.Render(env.stdout, d.Result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func headerOf(pair dirdiff.FilePair) string {
+	switch pair.Status {
+	case dirdiff.Added:
+		return fmt.Sprintf("added %s", pair.DstPath)
+	case dirdiff.Removed:
+		return fmt.Sprintf("removed %s", pair.SrcPath)
+	case dirdiff.Renamed:
+		return fmt.Sprintf("renamed %s → %s (%.0f%% similar)", pair.SrcPath, pair.DstPath, 100*pair.Similarity)
+	default:
+		return fmt.Sprintf("modified %s", pair.SrcPath)
+	}
+}
+
+// writeHTMLReport writes the side-by-side HTML report of `result` to `path`, or to the standard output if it is `-`.
+func writeHTMLReport(env *environment, path string, result *diff.Result) error {
+	renderer := render.NewHTMLRenderer()
+	if path == "-" {
+		return renderer.Render(env.stdout, result)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := renderer.Render(file, result); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// colorFlag tells whether to colorize the output: `auto` colorizes it when it is a terminal.
+type colorFlag string
+
+func registerColorFlag(fs *flag.FlagSet) *colorFlag {
+	color := colorFlag("auto")
+	fs.Var(&color, "color", "colorize the output: auto, always or never")
+	return &color
+}
+
+func (f *colorFlag) String() string {
+	return string(*f)
+}
+
+func (f *colorFlag) Set(value string) error {
+	switch value {
+	case "auto", "always", "never":
+		*f = colorFlag(value)
+		return nil
+	default:
+		return fmt.Errorf("unknown color mode %q", value)
+	}
+}
+
+func (f *colorFlag) enabledFor(w io.Writer) bool {
+	switch *f {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return isTerminal(w)
+	}
+}
+
+// isTerminal reports whether `w` is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/gitdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/render"
+)
+
+const gitDiffUsage = "git-diff [flags] <rev1> <rev2> [paths...]"
+
+var gitDiffCommand = command{
+	usage:   gitDiffUsage,
+	summary: "print the structural edit scripts of the files changed between two revisions of a git repository",
+	run:     runGitDiff,
+}
+
+func runGitDiff(env *environment, args []string) error {
+	fs := newFlagSet(env, gitDiffUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	dir := fs.String("C", ".", "run as if started in `dir`")
+	format := fs.String("format", "script", "output `format`: script for the edit scripts, changes for their grouped changes, or diff for annotated unified diffs")
+	context := fs.Int("context", render.DefaultContext, "number of unchanged `lines` shown around changes with -format diff")
+	color := registerColorFlag(fs)
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 if there are structural changes, e.g. to fail a pre-merge check")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return newUsageError("expected two revisions")
+	}
+
+	var renderer render.Renderer
+	switch *format {
+	case "script":
+		renderer = render.NewScriptRenderer()
+	case "changes":
+		renderer = render.NewChangesRenderer()
+	case "diff":
+		textRenderer := render.NewTextRenderer(color.enabledFor(env.stdout))
+		textRenderer.Context = *context
+		renderer = textRenderer
+	default:
+		return newUsageError("unknown format %q", *format)
+	}
+
+	repo, err := gitdiff.NewRepository(*dir)
+	if err != nil {
+		return err
+	}
+	diffs, err := gitdiff.Compare(repo, fs.Arg(0), fs.Arg(1), fs.Args()[2:], diffOptions(env, &transforms))
+	if err != nil {
+		return err
+	}
+
+	if err := printFileDiffs(env, diffs, renderer, color.enabledFor(env.stdout)); err != nil {
+		return err
+	}
+
+	if *exitCode {
+		for _, d := range diffs {
+			if d.Err != nil || len(d.Result.Script) > 0 || d.Status != dirdiff.Modified {
+				return errChangesFound
+			}
+		}
+	}
+	return nil
+}
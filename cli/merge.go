@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/merge"
+	"github.com/Xanonymous-GitHub/gumtree-go/unparse"
+	"os"
+)
+
+const mergeUsage = "merge [flags] <base> <left> <right>"
+
+var mergeCommand = command{
+	usage:   mergeUsage,
+	summary: "merge the changes of two versions of a file structurally and report their conflicts",
+	run:     runMerge,
+}
+
+func runMerge(env *environment, args []string) error {
+	fs := newFlagSet(env, mergeUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	printTreeOnly := fs.Bool("tree", false, "print the merged tree instead of its source text")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return newUsageError("expected a base, a left and a right file")
+	}
+
+	contents := make([][]byte, 0, 3)
+	for _, path := range fs.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, content)
+	}
+	result, err := merge.Contents(fs.Arg(0), contents[0], fs.Arg(1), contents[1], fs.Arg(2), contents[2], diffOptions(env, &transforms))
+	if err != nil {
+		return err
+	}
+
+	if err := printMerged(env, result, fs.Arg(0), *printTreeOnly); err != nil {
+		return err
+	}
+	for _, conflict := range result.Conflicts {
+		if _, err := fmt.Fprintf(env.stderr, "conflict: %s\n", conflict.String()); err != nil {
+			return err
+		}
+	}
+	if result.HasConflicts() {
+		return errChangesFound
+	}
+	return nil
+}
+
+// printMerged prints the source text of the merged tree, or the tree itself for the languages without printer.
+func printMerged(env *environment, result *merge.Result, path string, treeOnly bool) error {
+	printer, err := unparse.PrinterFor(path)
+	if treeOnly || errors.Is(err, unparse.ErrUnsupportedLanguage) {
+		return printTree(env.stdout, result.Merged.Root(), 0)
+	} else if err != nil {
+		return err
+	}
+
+	text, err := result.Unparse(printer)
+	if err != nil {
+		return err
+	}
+	_, err = env.stdout.Write(text)
+	return err
+}
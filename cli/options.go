@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/cache"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/transform"
+	"strings"
+)
+
+// labelsFlag is a comma-separated list of labels that may be repeated, e.g. `-drop Comment -drop CommentGroup`.
+// The special label `*` stands for every label, unless the flag rejects it.
+type labelsFlag struct {
+	labels []ast.NodeLabelType
+	all    bool
+	set    bool
+
+	// noWildcard rejects `*`, for flags where selecting every label is not meaningful, e.g. `-drop`.
+	noWildcard bool
+}
+
+func (f *labelsFlag) String() string {
+	if f.all {
+		return "*"
+	}
+
+	labels := make([]string, 0, len(f.labels))
+	for _, label := range f.labels {
+		labels = append(labels, string(label))
+	}
+	return strings.Join(labels, ",")
+}
+
+func (f *labelsFlag) Set(value string) error {
+	f.set = true
+	for _, label := range strings.Split(value, ",") {
+		label = strings.TrimSpace(label)
+		switch label {
+		case "":
+			continue
+		case "*":
+			if f.noWildcard {
+				return errors.New("* is not accepted, list the labels instead")
+			}
+			f.all = true
+		default:
+			f.labels = append(f.labels, ast.NodeLabelType(label))
+		}
+	}
+	return nil
+}
+
+// selected returns the labels given to the flag, or nil if every label is selected.
+func (f *labelsFlag) selected() []ast.NodeLabelType {
+	if f.all {
+		return nil
+	}
+	return f.labels
+}
+
+// transformFlags are the flags shared by every command, configuring the normalization applied to trees before matching,
+// where the normalized trees are cached and the parameters of the matching.
+type transformFlags struct {
+	drop, collapse, normalizeWhitespace labelsFlag
+	cacheDir                            string
+	config                              configFlag
+	bounded                             bool
+}
+
+// configFlag is the path of a JSON file holding the parameters of the matching, see comparator.LoadConfig.
+// The file is loaded and validated when the flag is parsed.
+type configFlag struct {
+	path   string
+	config *comparator.Config
+}
+
+func (f *configFlag) String() string {
+	return f.path
+}
+
+func (f *configFlag) Set(path string) error {
+	config, err := comparator.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	f.path, f.config = path, &config
+	return nil
+}
+
+func (f *transformFlags) register(fs *flag.FlagSet) {
+	f.drop.noWildcard = true
+	fs.Var(&f.drop, "drop", "ignore the nodes with these `labels` (comma-separated, repeatable), e.g. Comment,CommentGroup")
+	fs.Var(&f.collapse, "collapse", "replace the single-child nodes with these `labels` by their child, e.g. ParenExpr (* for any)")
+	fs.Var(&f.normalizeWhitespace, "normalize-ws", "collapse the whitespace in the values of the nodes with these `labels`, e.g. BasicLit (* for any)")
+	fs.StringVar(&f.cacheDir, "cache", "", "cache the parsed trees in `dir`, so that unchanged files are not parsed again by the next runs")
+	fs.Var(&f.config, "config", "read the parameters of the matching, e.g. {\"minHeight\": 1, \"minDice\": 0.3}, from the JSON `file`")
+	fs.BoolVar(&f.bounded, "bounded", false, "bound the memory taken by the matching of large trees, warning when it degrades the result")
+}
+
+// pipeline returns the transformation pipeline described by the flags.
+func (f *transformFlags) pipeline(env *environment) transform.Pipeline {
+	pipeline := make(transform.Pipeline, 0)
+	if f.drop.set {
+		pipeline = append(pipeline, transform.NewDropLabelsPass(env.logger, f.drop.selected()...))
+	}
+	if f.collapse.set {
+		pipeline = append(pipeline, transform.NewCollapseChainsPass(env.logger, f.collapse.selected()...))
+	}
+	if f.normalizeWhitespace.set {
+		pipeline = append(pipeline, transform.NewNormalizeValuesPass(transform.CollapseWhitespace, env.logger, f.normalizeWhitespace.selected()...))
+	}
+	return pipeline
+}
+
+// diffOptions returns the options comparing files with the default frontends and the normalization described by `transforms`.
+// The -bounded flag takes precedence over the config file.
+func diffOptions(env *environment, transforms *transformFlags) diff.Options {
+	opts := diff.DefaultOptions(env.logger)
+	opts.Pipeline = transforms.pipeline(env)
+	if transforms.config.config != nil {
+		opts.Config = *transforms.config.config
+	}
+	if transforms.bounded {
+		opts.Limits = comparator.DefaultLimits()
+	}
+	if transforms.cacheDir != "" {
+		opts.Cache = cache.NewStore(transforms.cacheDir, env.logger)
+	}
+	return opts
+}
+
+func registerRenameThresholdFlag(fs *flag.FlagSet) *float64 {
+	return fs.Float64("rename-threshold", dirdiff.DefaultRenameThreshold, "minimum `similarity` of a removed and an added file to be reported as a rename, above 1 to disable rename detection")
+}
+
+// dirdiffOptions returns the options comparing directories, see diffOptions.
+func dirdiffOptions(env *environment, transforms *transformFlags, renameThreshold float64) dirdiff.Options {
+	return dirdiff.Options{
+		Diff:            diffOptions(env, transforms),
+		RenameThreshold: renameThreshold,
+	}
+}
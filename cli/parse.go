@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"io"
+	"os"
+	"strings"
+)
+
+const parseUsage = "parse [flags] <file>"
+
+var parseCommand = command{
+	usage:   parseUsage,
+	summary: "print the normalized AST of a file",
+	run:     runParse,
+}
+
+func runParse(env *environment, args []string) error {
+	fs := newFlagSet(env, parseUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return newUsageError("expected exactly one file")
+	}
+
+	content, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	tree, err := diff.Parse(fs.Arg(0), content, diffOptions(env, &transforms))
+	if err != nil {
+		return err
+	}
+
+	return printTree(env.stdout, tree.Root(), 0)
+}
+
+// printTree prints the subtree of `n` with one node per line, indented by depth,
+// e.g. `Ident: main [5,9)`.
+func printTree(w io.Writer, n *ast.Node, depth int) error {
+	if n == nil {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(string(n.Label))
+	if n.Value != "" {
+		b.WriteString(": ")
+		b.WriteString(strings.ReplaceAll(string(n.Value), "\n", `\n`))
+	}
+	if n.Pos.IsValid() {
+		_, _ = fmt.Fprintf(&b, " [%d,%d)", n.Pos.Start, n.Pos.End)
+	}
+	b.WriteByte('\n')
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+	for _, child := range n.OrderedChildren() {
+		if err := printTree(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/metrics"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const statsUsage = "stats [flags] <src> <dst>"
+
+var statsCommand = command{
+	usage:   statsUsage,
+	summary: "print the metrics of the structural diff of two files or directories as JSON",
+	run:     runStats,
+}
+
+// fileStats are the metrics of a file of compared directories.
+type fileStats struct {
+	SrcPath string         `json:"srcPath,omitempty"`
+	DstPath string         `json:"dstPath,omitempty"`
+	Status  dirdiff.Status `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	*metrics.Stats
+}
+
+// dirStats are the metrics of compared directories.
+type dirStats struct {
+	Files []fileStats   `json:"files"`
+	Total metrics.Stats `json:"total"`
+}
+
+func runStats(env *environment, args []string) error {
+	fs := newFlagSet(env, statsUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	costs := costFlag{model: metrics.NewCostModel()}
+	fs.Var(&costs, "cost", "`costs` of the actions as [label:]type=cost (comma-separated, repeatable), e.g. move=0.5,Comment:insert=0")
+	renameThreshold := registerRenameThresholdFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return newUsageError("expected a source and a destination file or directory")
+	}
+
+	bothDirs, err := areDirectories(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if !bothDirs {
+		result, err := diff.Files(fs.Arg(0), fs.Arg(1), diffOptions(env, &transforms))
+		if err != nil {
+			return err
+		}
+		return writeJSON(env, metrics.Of(result, costs.model))
+	}
+
+	diffs, err := dirdiff.Compare(fs.Arg(0), fs.Arg(1), dirdiffOptions(env, &transforms, *renameThreshold))
+	if err != nil {
+		return err
+	}
+	stats := dirStats{Files: make([]fileStats, 0, len(diffs))}
+	all := make([]metrics.Stats, 0, len(diffs))
+	for _, d := range diffs {
+		file := fileStats{SrcPath: d.SrcPath, DstPath: d.DstPath, Status: d.Status}
+		if d.Err != nil {
+			file.Error = d.Err.Error()
+		} else {
+			s := metrics.Of(d.Result, costs.model)
+			file.Stats = &s
+			all = append(all, s)
+		}
+		stats.Files = append(stats.Files, file)
+	}
+	stats.Total = metrics.Sum(all...)
+	return writeJSON(env, stats)
+}
+
+func writeJSON(env *environment, v any) error {
+	encoder := json.NewEncoder(env.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// costFlag fills a cost model from a comma-separated list of costs that may be repeated, e.g. `-cost move=0.5 -cost Comment:insert=0`.
+// The special type `*` stands for every action type.
+type costFlag struct {
+	model metrics.CostModel
+	specs []string
+}
+
+func (f *costFlag) String() string {
+	return strings.Join(f.specs, ",")
+}
+
+func (f *costFlag) Set(value string) error {
+	for _, spec := range strings.Split(value, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		key, costText, ok := strings.Cut(spec, "=")
+		if !ok {
+			return fmt.Errorf("expected [label:]type=cost, got %q", spec)
+		}
+		cost, err := strconv.ParseFloat(costText, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cost %q: %w", costText, err)
+		}
+		label, typeName, hasLabel := strings.Cut(key, ":")
+		if !hasLabel {
+			typeName = label
+		}
+		types, err := actionTypesOf(typeName)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range types {
+			if !hasLabel {
+				f.model.ActionCosts[t] = cost
+				continue
+			}
+			if f.model.LabelCosts[ast.NodeLabelType(label)] == nil {
+				f.model.LabelCosts[ast.NodeLabelType(label)] = make(map[editscript.ActionType]float64)
+			}
+			f.model.LabelCosts[ast.NodeLabelType(label)][t] = cost
+		}
+		f.specs = append(f.specs, spec)
+	}
+	return nil
+}
+
+// actionTypesOf returns the action types named `name`, e.g. `insert`, or every type for `*`.
+// The tree actions of simplified scripts are weighed as the actions on their nodes, see metrics.CostModel.
+func actionTypesOf(name string) ([]editscript.ActionType, error) {
+	types := []editscript.ActionType{editscript.Insert, editscript.Delete, editscript.Update, editscript.Move}
+	if name == "*" {
+		return types, nil
+	}
+	for _, t := range types {
+		if t.String() == name {
+			return []editscript.ActionType{t}, nil
+		}
+	}
+
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, t.String())
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown action type %q, expected one of %s or *", name, strings.Join(names, ", "))
+}
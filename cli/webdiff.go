@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/web"
+	"net"
+	"net/http"
+	"os"
+)
+
+const webdiffUsage = "webdiff [flags] <src> <dst>"
+
+var webdiffCommand = command{
+	usage:   webdiffUsage,
+	summary: "serve an interactive viewer of the structural diff of two files or directories",
+	run:     runWebdiff,
+}
+
+func runWebdiff(env *environment, args []string) error {
+	fs := newFlagSet(env, webdiffUsage)
+	var transforms transformFlags
+	transforms.register(fs)
+	addr := fs.String("addr", "localhost:4567", "`address` to listen on")
+	renameThreshold := registerRenameThresholdFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return newUsageError("expected a source and a destination file or directory")
+	}
+
+	opts := dirdiffOptions(env, &transforms, *renameThreshold)
+	pairs, err := filePairsOf(fs.Arg(0), fs.Arg(1), opts)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(env.stderr, "serving the diff of %d files on http://%s\n", len(pairs), listener.Addr())
+
+	server := web.NewServer(pairs, opts.Diff)
+	return http.Serve(listener, server)
+}
+
+// filePairsOf returns the files to compare between `src` and `dst`, which are either two files or two directories.
+// The files of two directories are paired with dirdiff.Pair.
+func filePairsOf(src, dst string, opts dirdiff.Options) ([]dirdiff.FilePair, error) {
+	bothDirs, err := areDirectories(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if bothDirs {
+		return dirdiff.Pair(src, dst, opts)
+	}
+	return []dirdiff.FilePair{{SrcPath: src, DstPath: dst, Status: dirdiff.Modified}}, nil
+}
+
+// areDirectories tells whether `src` and `dst` are two directories or two files, and fails otherwise.
+func areDirectories(src, dst string) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+	dstInfo, err := os.Stat(dst)
+	if err != nil {
+		return false, err
+	}
+	if srcInfo.IsDir() != dstInfo.IsDir() {
+		return false, newUsageError("expected two files or two directories")
+	}
+	return srcInfo.IsDir(), nil
+}
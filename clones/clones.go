@@ -0,0 +1,253 @@
+// Package clones finds the code clones of a codebase, i.e. the subtrees of its ASTs that are duplicated.
+//
+// Clones are grouped into classes by the hashes of their subtrees:
+// Type-1 clones are identical, and Type-2 clones are identical up to the values of their leaves,
+// i.e. the identifiers and literals that have been renamed or changed.
+package clones
+
+import (
+	"bytes"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"sort"
+)
+
+// Default thresholds on the subtrees considered as clones, leaving out trivial subtrees such as single expressions.
+const (
+	DefaultMinHeight = 2
+	DefaultMinSize   = 10
+)
+
+// Type is the kind of similarity of the clones of a class.
+type Type int
+
+const (
+	// Type1 clones are identical subtrees.
+	Type1 Type = iota + 1
+
+	// Type2 clones are identical subtrees up to the values of their leaves.
+	Type2
+)
+
+func (t Type) String() string {
+	switch t {
+	case Type1:
+		return "type-1"
+	case Type2:
+		return "type-2"
+	default:
+		return "unknown"
+	}
+}
+
+// Location is a clone, i.e. a subtree of an indexed tree.
+type Location struct {
+	// Path is the path of the tree given to Detector.Add.
+	Path string
+
+	// Node is the root of the subtree.
+	Node *ast.Node
+
+	// StartLine and EndLine are the 1-based lines spanned by the subtree, or 0 if its node has no position.
+	StartLine, EndLine int
+}
+
+// Class is a group of at least two clones of the same type.
+type Class struct {
+	Type Type
+
+	// Hash is the hash shared by the clones, see ast.Node.HashValue for Type-1 clones.
+	Hash uint64
+
+	// Size and Height are the number of nodes and the height of each clone.
+	Size, Height int
+
+	// Locations are the clones, sorted by path and position.
+	Locations []Location
+}
+
+// Options configure which subtrees are indexed as potential clones.
+type Options struct {
+	// MinHeight is the minimum height of a clone, a leaf having a height of 0.
+	MinHeight int
+
+	// MinSize is the minimum number of nodes of a clone.
+	MinSize int
+}
+
+// DefaultOptions returns the options with the default thresholds.
+func DefaultOptions() Options {
+	return Options{MinHeight: DefaultMinHeight, MinSize: DefaultMinSize}
+}
+
+// subtree is an indexed subtree.
+type subtree struct {
+	location Location
+	size     int
+	height   int
+
+	// hashes are the hashes of the subtree by clone type, and parentHashes the ones of its parent,
+	// which are zero if the parent is not indexed.
+	hashes, parentHashes [Type2 + 1]uint64
+	parent               *ast.Node
+}
+
+// Detector indexes the subtrees of many trees to find their clones.
+type Detector struct {
+	opts     Options
+	subtrees []*subtree
+}
+
+// NewDetector creates a Detector indexing the subtrees allowed by `opts`.
+func NewDetector(opts Options) *Detector {
+	return &Detector{opts: opts}
+}
+
+// Add indexes the subtrees of `tree`, the AST of the file at `path` with the content `content`.
+// The content is only used to compute the lines of the clones and may be nil.
+func (d *Detector) Add(path string, content []byte, tree ast.AST) {
+	d.AddHashed(path, content, tree, tree.MakeHashMemo())
+}
+
+// AddHashed indexes the subtrees of `tree` as Add does, given its hash memo, e.g. a cached one.
+func (d *Detector) AddHashed(path string, content []byte, tree ast.AST, exact ast.NodeHashMemo) {
+	if tree.Root() == nil {
+		return
+	}
+
+	lines := newLineIndex(content)
+	indexed := make(map[*ast.Node]*subtree)
+
+	var visit func(n *ast.Node) (structural uint64, size, height int)
+	visit = func(n *ast.Node) (uint64, int, int) {
+		children := n.OrderedChildren()
+		childHashes := make([]uint64, 0, len(children))
+		size, height := 1, 0
+		for _, child := range children {
+			h, childSize, childHeight := visit(child)
+			childHashes = append(childHashes, h)
+			size += childSize
+			height = max(height, childHeight+1)
+		}
+		structural := structuralHashOf(n, childHashes)
+
+		if height >= d.opts.MinHeight && size >= d.opts.MinSize {
+			s := &subtree{
+				location: Location{Path: path, Node: n},
+				size:     size,
+				height:   height,
+				parent:   n.Parent,
+			}
+			s.hashes[Type1], s.hashes[Type2] = exact[n.Id], structural
+			if n.Pos.IsValid() {
+				s.location.StartLine, s.location.EndLine = lines.lineOf(n.Pos.Start), lines.lineOf(max(n.Pos.Start, n.Pos.End-1))
+			}
+			indexed[n] = s
+			d.subtrees = append(d.subtrees, s)
+		}
+		return structural, size, height
+	}
+	visit(tree.Root())
+
+	for _, s := range indexed {
+		if parent, ok := indexed[s.parent]; ok {
+			s.parentHashes = parent.hashes
+		}
+	}
+}
+
+// Classes returns the clone classes of the indexed subtrees, the largest first.
+//
+// Only maximal classes are reported: a class is left out if its clones are the children of the clones of another class,
+// and a Type-2 class is left out if its clones are all identical, as it is then a Type-1 class.
+func (d *Detector) Classes() []Class {
+	classes := append(d.classesOf(Type1), d.classesOf(Type2)...)
+	sort.SliceStable(classes, func(i, j int) bool {
+		if classes[i].Size != classes[j].Size {
+			return classes[i].Size > classes[j].Size
+		}
+		if classes[i].Type != classes[j].Type {
+			return classes[i].Type < classes[j].Type
+		}
+		return lessLocation(classes[i].Locations[0], classes[j].Locations[0])
+	})
+	return classes
+}
+
+// classesOf groups the indexed subtrees by their hash of type `t`, keeping the maximal groups.
+func (d *Detector) classesOf(t Type) []Class {
+	groups := make(map[uint64][]*subtree)
+	for _, s := range d.subtrees {
+		groups[s.hashes[t]] = append(groups[s.hashes[t]], s)
+	}
+
+	classes := make([]Class, 0)
+	for hash, group := range groups {
+		if len(group) < 2 || isSubsumed(group, t) || (t == Type2 && isIdentical(group)) {
+			continue
+		}
+
+		class := Class{Type: t, Hash: hash, Size: group[0].size, Height: group[0].height, Locations: make([]Location, 0, len(group))}
+		for _, s := range group {
+			class.Locations = append(class.Locations, s.location)
+		}
+		sort.Slice(class.Locations, func(i, j int) bool {
+			return lessLocation(class.Locations[i], class.Locations[j])
+		})
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// isSubsumed tells whether the subtrees of `group` are the children of distinct clones sharing a hash of type `t`,
+// which then make a larger class.
+func isSubsumed(group []*subtree, t Type) bool {
+	parents := make(map[*ast.Node]bool, len(group))
+	for _, s := range group {
+		if s.parentHashes[t] == 0 || s.parentHashes[t] != group[0].parentHashes[t] || parents[s.parent] {
+			return false
+		}
+		parents[s.parent] = true
+	}
+	return true
+}
+
+// isIdentical tells whether the subtrees of `group` are all identical.
+func isIdentical(group []*subtree) bool {
+	for _, s := range group[1:] {
+		if s.hashes[Type1] != group[0].hashes[Type1] {
+			return false
+		}
+	}
+	return true
+}
+
+func lessLocation(a, b Location) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.Node.Pos.Start < b.Node.Pos.Start
+}
+
+// lineIndex finds the lines of the offsets of a content.
+type lineIndex struct {
+	// starts are the offsets of the starts of the lines.
+	starts []int
+}
+
+func newLineIndex(content []byte) *lineIndex {
+	starts := []int{0}
+	for offset := 0; ; {
+		i := bytes.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			break
+		}
+		offset += i + 1
+		starts = append(starts, offset)
+	}
+	return &lineIndex{starts: starts}
+}
+
+// lineOf returns the 1-based line of `offset`.
+func (l *lineIndex) lineOf(offset int) int {
+	return sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset })
+}
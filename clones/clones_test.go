@@ -0,0 +1,142 @@
+package clones_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/clones"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"log/slog"
+	"testing"
+)
+
+const shapes = `package shapes
+
+func area(w, h int) int {
+	if w < 0 || h < 0 {
+		return 0
+	}
+	return w * h
+}
+
+func size(width, height int) int {
+	if width < 0 || height < 0 {
+		return 0
+	}
+	return width * height
+}
+`
+
+const geometry = `package geometry
+
+func area(w, h int) int {
+	if w < 0 || h < 0 {
+		return 0
+	}
+	return w * h
+}
+
+func perimeter(w, h int) int {
+	return 2 * (w + h)
+}
+`
+
+func detect(t *testing.T, opts clones.Options, files map[string]string) []clones.Class {
+	t.Helper()
+
+	detector := clones.NewDetector(opts)
+	generator := frontend.NewGoTreeGenerator(slog.Logger{})
+	for path, content := range files {
+		tree, err := generator.Generate([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		detector.Add(path, []byte(content), tree)
+	}
+	return detector.Classes()
+}
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test identical functions make a Type-1 class", func(t *testing.T) {
+		t.Parallel()
+
+		classes := detect(t, clones.DefaultOptions(), map[string]string{"shapes.go": shapes, "geometry.go": geometry})
+		if len(classes) == 0 || classes[0].Type != clones.Type1 {
+			t.Fatalf("Expected a Type-1 class first, got %v", classes)
+		}
+
+		class := classes[0]
+		if class.Locations[0].Node.Label != "FuncDecl" {
+			t.Errorf("Expected the whole functions to be clones, got %s", class.Locations[0].Node.Label)
+		}
+		if len(class.Locations) != 2 || class.Locations[0].Path != "geometry.go" || class.Locations[1].Path != "shapes.go" {
+			t.Fatalf("Expected the clones in geometry.go and shapes.go, got %v", class.Locations)
+		}
+		for _, location := range class.Locations {
+			if location.StartLine != 3 || location.EndLine != 8 {
+				t.Errorf("Expected the clone to span lines 3 to 8, got %d to %d", location.StartLine, location.EndLine)
+			}
+		}
+	})
+
+	t.Run("Test renamed identifiers make a Type-2 class", func(t *testing.T) {
+		t.Parallel()
+
+		classes := detect(t, clones.DefaultOptions(), map[string]string{"shapes.go": shapes, "geometry.go": geometry})
+		var found *clones.Class
+		for i, class := range classes {
+			if class.Type == clones.Type2 && class.Locations[0].Node.Label == "FuncDecl" {
+				found = &classes[i]
+			}
+		}
+		if found == nil {
+			t.Fatalf("Expected a Type-2 class of functions, got %v", classes)
+		}
+		if len(found.Locations) != 3 {
+			t.Errorf("Expected area twice and size, got %d clones", len(found.Locations))
+		}
+	})
+
+	t.Run("Test only maximal classes are reported", func(t *testing.T) {
+		t.Parallel()
+
+		classes := detect(t, clones.DefaultOptions(), map[string]string{"shapes.go": shapes, "geometry.go": geometry})
+		for _, class := range classes {
+			if class.Type == clones.Type1 && class.Locations[0].Node.Label != "FuncDecl" {
+				t.Errorf("Expected the parts of the cloned functions to be left out, got a class of %s", class.Locations[0].Node.Label)
+			}
+		}
+	})
+
+	t.Run("Test subtrees below the thresholds are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		classes := detect(t, clones.Options{MinHeight: 2, MinSize: 1000}, map[string]string{"shapes.go": shapes, "geometry.go": geometry})
+		if len(classes) != 0 {
+			t.Errorf("Expected no class, got %d", len(classes))
+		}
+	})
+
+	t.Run("Test clones within a single tree", func(t *testing.T) {
+		t.Parallel()
+
+		tree := ast.NewAST(slog.Logger{})
+		root, _ := tree.Add(nil, -1, "Block", "")
+		for i := 0; i < 2; i++ {
+			stmt, _ := tree.Add(root, i, "Call", "")
+			_, _ = tree.Add(stmt, 0, "Ident", "f")
+			args, _ := tree.Add(stmt, 1, "Args", "")
+			_, _ = tree.Add(args, 0, "Ident", "x")
+		}
+
+		detector := clones.NewDetector(clones.Options{MinHeight: 1, MinSize: 1})
+		detector.Add("tree", nil, tree)
+		classes := detector.Classes()
+		if len(classes) != 1 || len(classes[0].Locations) != 2 || classes[0].Size != 4 {
+			t.Fatalf("Expected the two calls to be clones, got %v", classes)
+		}
+		if classes[0].Locations[0].StartLine != 0 {
+			t.Errorf("Expected no lines without positions, got %d", classes[0].Locations[0].StartLine)
+		}
+	})
+}
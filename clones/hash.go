@@ -0,0 +1,27 @@
+package clones
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/cespare/xxhash/v2"
+	"strconv"
+)
+
+// structuralHashOf returns the hash of the subtree of `n` ignoring the values of the leaves, given the hashes of its children.
+// The leaves carry the identifiers and literals in every frontend, while the values of inner nodes, e.g. operators,
+// are part of the structure.
+func structuralHashOf(n *ast.Node, childHashes []uint64) uint64 {
+	var digest xxhash.Digest
+	_, _ = digest.WriteString("<")
+	_, _ = digest.WriteString(string(n.Label))
+	_, _ = digest.WriteString(">")
+	if len(childHashes) > 0 {
+		_, _ = digest.WriteString("[")
+		_, _ = digest.WriteString(string(n.Value))
+		_, _ = digest.WriteString("]")
+	}
+	for _, h := range childHashes {
+		_, _ = digest.WriteString(strconv.FormatUint(h, 10))
+		_, _ = digest.WriteString(",")
+	}
+	return digest.Sum64()
+}
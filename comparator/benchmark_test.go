@@ -0,0 +1,114 @@
+package comparator_test
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
+	"log/slog"
+	"math/rand/v2"
+	"testing"
+)
+
+var benchmarkSizes = []int{10_000, 100_000, 1_000_000}
+
+// generatedTrees builds a random tree of `size` nodes and a copy of it where one node out of a hundred has a new value,
+// like two versions of a generated file.
+func generatedTrees(size int) (ast.AST, ast.AST) {
+	rng := rand.New(rand.NewPCG(uint64(size), 42))
+	labels := []ast.NodeLabelType{"Message", "Field", "Type", "Name", "Option"}
+
+	src := ast.NewAST(slog.Logger{})
+	nodes := make([]*ast.Node, 0, size)
+	for i := 0; i < size; i++ {
+		var parent *ast.Node
+		idx := -1
+		if i > 0 {
+			parent = nodes[rng.IntN(len(nodes))]
+			idx = parent.Degree()
+		}
+		n, err := src.Add(parent, idx, labels[rng.IntN(len(labels))], ast.NodeValueType(fmt.Sprint(rng.IntN(100))))
+		if err != nil {
+			panic(err)
+		}
+		nodes = append(nodes, n)
+	}
+
+	dst, _ := ast.Copy(src, slog.Logger{})
+	for _, n := range dst.PreOrderNodes() {
+		if rng.IntN(100) == 0 {
+			_ = dst.UpdateValue(n, "changed")
+		}
+	}
+	return src, dst
+}
+
+// layersOf groups the nodes of `tree` higher than the default minimum height by height,
+// i.e. the layers the top-down phase pops when every node is opened.
+func layersOf(tree ast.AST) map[int][]*ast.Node {
+	layers := make(map[int][]*ast.Node)
+	for _, n := range tree.PostOrderNodes() {
+		if height := n.Height(); height > comparator.DefaultMinHeight {
+			layers[height] = append(layers[height], n)
+		}
+	}
+	return layers
+}
+
+// BenchmarkGroupIsomorphic compares grouping every layer of same-height nodes by hash through the n×m pairs of the layer,
+// as the top-down phase used to, against bucketing each side directly.
+func BenchmarkGroupIsomorphic(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("CrossPairs/%d", size), func(b *testing.B) {
+			src, dst := generatedTrees(size)
+			memo1, memo2 := src.MakeHashMemo(), dst.MakeHashMemo()
+			layers1, layers2 := layersOf(src), layersOf(dst)
+			pairs := 0
+			for height, nodes := range layers1 {
+				pairs += len(nodes) * len(layers2[height])
+			}
+			if pairs > 20_000_000 {
+				b.Skipf("%d pairs would take gigabytes", pairs)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for height, nodes := range layers1 {
+					comparator.NewIsomorphicMappings(memo1, memo2, CrossPairOf(nodes, layers2[height]))
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Buckets/%d", size), func(b *testing.B) {
+			src, dst := generatedTrees(size)
+			memo1, memo2 := src.MakeHashMemo(), dst.MakeHashMemo()
+			layers1, layers2 := layersOf(src), layersOf(dst)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for height, nodes := range layers1 {
+					comparator.GroupIsomorphic(memo1, memo2, nodes, layers2[height])
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompare runs the whole matching of generated trees.
+func BenchmarkCompare(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			src, dst := generatedTrees(size)
+			memo1, memo2 := src.MakeHashMemo(), dst.MakeHashMemo()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c, err := comparator.New(&src, &dst, comparator.WithHashMemos(memo1, memo2), comparator.WithLogger(&slog.Logger{}))
+				if err != nil {
+					b.Fatal(err)
+				}
+				c.Compare()
+			}
+		})
+	}
+}
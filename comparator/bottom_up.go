@@ -0,0 +1,146 @@
+package comparator
+
+import (
+	"context"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
+	"github.com/Xanonymous-GitHub/gumtree-go/utils"
+	"github.com/samber/lo"
+	"sort"
+)
+
+// bottomUp is the second phase of GumTree.
+// Two nodes match (called a container mapping) if their descendants (i.e., all of their children, and their children's children...)
+// include a large number of common mappings.
+// When two nodes match, an additional recovery step searches for additional mappings (called recovery mappings) among their descendants.
+// It stops with an error wrapping ErrBudgetExceeded when `ctx` is done.
+func (c *comparator) bottomUp(ctx context.Context) error {
+	root1 := (*c.tree1).Root()
+	root2 := (*c.tree2).Root()
+
+	// visited holds, by preorder index, the source node whose candidates were last searched from each destination node.
+	visited := make([]*ast.Node, len(c.order2.nodes))
+	for _, n1 := range (*c.tree1).PostOrderNodes() {
+		if err := budgetErrorOf(ctx); err != nil {
+			return err
+		}
+
+		if n1 == root1 {
+			if c.mappings.Add(root1, root2) {
+				return c.recover(ctx, root1, root2)
+			}
+			break
+		}
+
+		if c.mappings.IsSrcMapped(n1) || n1.Degree() == 0 {
+			continue
+		}
+
+		descendants1 := c.order1.descendantsOf(n1)
+		mapped := c.mappedIndicesOf(descendants1)
+		var best *ast.Node
+		bestDice := -1.0
+		for _, candidate := range c.candidatesOf(n1, descendants1, visited) {
+			dice := c.diceOf(n1, candidate, mapped)
+			if dice > bestDice {
+				best, bestDice = candidate, dice
+			}
+		}
+
+		if best != nil && bestDice > c.minDice {
+			c.mappings.Add(n1, best)
+			if max(c.order1.sizeOf(n1), c.order2.sizeOf(best)) < c.maxSize {
+				if err := c.recover(ctx, n1, best); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// candidatesOf returns the unmatched destination nodes having the same label as `n1`,
+// and having some descendants matched to `descendants1`, the descendants of `n1`.
+// Past MaxContainerCandidates, the remaining candidates are left out.
+func (c *comparator) candidatesOf(n1 *ast.Node, descendants1 []*ast.Node, visited []*ast.Node) []*ast.Node {
+	root2 := (*c.tree2).Root()
+	candidates := make([]*ast.Node, 0)
+
+	for _, descendant := range descendants1 {
+		seed := c.mappings.DstOf(descendant)
+		if seed == nil {
+			continue
+		}
+
+		for parent := seed.Parent; parent != nil; parent = parent.Parent {
+			i := c.order2.index[parent]
+			if visited[i] == n1 {
+				break
+			}
+			visited[i] = n1
+
+			if parent.Label == n1.Label && parent != root2 && !c.mappings.IsDstMapped(parent) {
+				if c.limits.MaxContainerCandidates > 0 && len(candidates) == c.limits.MaxContainerCandidates {
+					c.degrade("MaxContainerCandidates")
+					return candidates
+				}
+				candidates = append(candidates, parent)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// mappedIndicesOf returns the sorted preorder indices of the destination nodes mapped to `descendants1`.
+func (c *comparator) mappedIndicesOf(descendants1 []*ast.Node) []int {
+	mapped := make([]int, 0)
+	for _, descendant := range descendants1 {
+		if dst := c.mappings.DstOf(descendant); dst != nil {
+			mapped = append(mapped, c.order2.index[dst])
+		}
+	}
+	sort.Ints(mapped)
+	return mapped
+}
+
+// recover searches for additional mappings among the descendants of the mapped nodes `n1` and `n2`.
+// Unmatched children are first matched when they are isomorphic, following the longest common subsequence of the children lists.
+// The remaining children are then matched when their label is unique on both sides, and the recovery goes on with them.
+func (c *comparator) recover(ctx context.Context, n1, n2 *ast.Node) error {
+	if err := budgetErrorOf(ctx); err != nil {
+		return err
+	}
+
+	children1 := lo.Filter(n1.OrderedChildren(), func(n *ast.Node, _ int) bool { return !c.mappings.IsSrcMapped(n) })
+	children2 := lo.Filter(n2.OrderedChildren(), func(n *ast.Node, _ int) bool { return !c.mappings.IsDstMapped(n) })
+
+	if c.limits.MaxRecoveryCells > 0 && len(children1)*len(children2) > c.limits.MaxRecoveryCells {
+		c.degrade("MaxRecoveryCells")
+		c.matchUniqueHashes(children1, children2)
+	} else {
+		isomorphicPairs := utils.LongestCommonSubsequence(children1, children2, func(a, b *ast.Node) bool {
+			return c.hashMemo1[a.Id] == c.hashMemo2[b.Id]
+		})
+		for _, idxPair := range isomorphicPairs {
+			forEachIsomorphicNodesPairOf(NewPair(children1[idxPair[0]], children2[idxPair[1]]), func(pair Pair[*ast.Node, *ast.Node]) {
+				c.mappings.Add(pair.Left(), pair.Right())
+			})
+		}
+	}
+
+	children1 = lo.Filter(children1, func(n *ast.Node, _ int) bool { return !c.mappings.IsSrcMapped(n) })
+	children2 = lo.Filter(children2, func(n *ast.Node, _ int) bool { return !c.mappings.IsDstMapped(n) })
+
+	byLabel1 := lo.GroupBy(children1, func(n *ast.Node) ast.NodeLabelType { return n.Label })
+	byLabel2 := lo.GroupBy(children2, func(n *ast.Node) ast.NodeLabelType { return n.Label })
+	for _, child1 := range children1 {
+		sameLabel1, sameLabel2 := byLabel1[child1.Label], byLabel2[child1.Label]
+		if len(sameLabel1) == 1 && len(sameLabel2) == 1 && c.mappings.Add(child1, sameLabel2[0]) {
+			if err := c.recover(ctx, child1, sameLabel2[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
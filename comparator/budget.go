@@ -0,0 +1,21 @@
+package comparator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExceeded is returned when the context of the matching is done before it completes,
+// e.g. because its deadline has passed on pathological trees.
+// The mappings found so far are still returned: they are consistent, but may miss many pairs,
+// so that callers may rather fall back to a textual difference.
+var ErrBudgetExceeded = errors.New("matching budget exceeded")
+
+// budgetErrorOf returns ErrBudgetExceeded, wrapping the cause, if `ctx` is done, and nil otherwise.
+func budgetErrorOf(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrBudgetExceeded, err)
+	}
+	return nil
+}
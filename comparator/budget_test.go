@@ -0,0 +1,112 @@
+package comparator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"log/slog"
+	"testing"
+)
+
+// countdownContext is a context that is canceled once its error has been checked `checks` times,
+// i.e. at a given step of the matching.
+type countdownContext struct {
+	context.Context
+	checks int
+}
+
+func (c *countdownContext) Err() error {
+	if c.checks <= 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestCompareContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test a done context stops the matching", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "2", "3"), statementsTree("3", "4", "1")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := comparator.NewComparator(&src, &dst, 1, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+		mappings, err := c.CompareContext(ctx)
+		if !errors.Is(err, comparator.ErrBudgetExceeded) || !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the budget to be exceeded because of the cancellation, got %v", err)
+		}
+		if mappings.Size() != 0 {
+			t.Errorf("Expected no mapping, got %d", mappings.Size())
+		}
+
+		if again, err := c.CompareContext(context.Background()); again != mappings || !errors.Is(err, comparator.ErrBudgetExceeded) {
+			t.Errorf("Expected the same mappings and error, got %v", err)
+		}
+	})
+
+	t.Run("Test a context never done completes the matching", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "2", "3"), statementsTree("3", "4", "1")
+		c := comparator.NewComparator(&src, &dst, 1, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+		mappings, err := c.CompareContext(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !mappings.Has(src.Root(), dst.Root()) {
+			t.Errorf("Expected the roots to be mapped")
+		}
+	})
+
+	t.Run("Test roots of different labels are mapped", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "2"), statementsTree("1", "2")
+		_ = dst.UpdateLabel(dst.Root(), "File")
+
+		for checks := 0; checks < 20; checks++ {
+			c := comparator.NewComparator(&src, &dst, 1, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+			mappings, _ := c.CompareContext(&countdownContext{Context: context.Background(), checks: checks})
+			for _, pair := range mappings.Pairs() {
+				isRoots := pair.Left() == src.Root() && pair.Right() == dst.Root()
+				if !isRoots && pair.Left().Label != pair.Right().Label {
+					t.Errorf("Expected mapped nodes other than the roots to have the same label, got %s and %s", pair.Left().Label, pair.Right().Label)
+				}
+			}
+		}
+
+		c := comparator.NewComparator(&src, &dst, 1, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+		if mappings := c.Compare(); !mappings.Has(src.Root(), dst.Root()) {
+			t.Errorf("Expected the roots to be mapped despite their labels")
+		}
+	})
+
+	src, dst := generatedTrees(2_000)
+	full := comparator.NewComparator(&src, &dst, comparator.DefaultMinHeight, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{}).Compare()
+
+	for _, checks := range []int{1, 10, 100, 1_000} {
+		t.Run(fmt.Sprintf("Test stopping after %d checks keeps a subset of the mappings", checks), func(t *testing.T) {
+			t.Parallel()
+
+			c := comparator.NewComparator(&src, &dst, comparator.DefaultMinHeight, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+			mappings, err := c.CompareContext(&countdownContext{Context: context.Background(), checks: checks})
+			if !errors.Is(err, comparator.ErrBudgetExceeded) {
+				t.Fatalf("Expected the budget to be exceeded, got %v", err)
+			}
+			if mappings.Size() >= full.Size() {
+				t.Errorf("Expected fewer than %d mappings, got %d", full.Size(), mappings.Size())
+			}
+
+			assertConsistent(t, mappings)
+			for _, pair := range mappings.Pairs() {
+				if !full.Has(pair.Left(), pair.Right()) {
+					t.Errorf("Expected %v to be mapped to %v as by the whole matching", pair.Left(), pair.Right())
+				}
+			}
+		})
+	}
+}
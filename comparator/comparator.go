@@ -1,45 +1,105 @@
 package comparator
 
 import (
+	"context"
 	"github.com/Xanonymous-GitHub/gumtree-go/ast"
 	"log/slog"
 )
 
+// Default parameters of the matching, as recommended by the GumTree paper.
+const (
+	DefaultMinHeight = 2
+	DefaultMaxSize   = 1000
+	DefaultMinDice   = 0.5
+)
+
 type Comparator interface {
+	// Compare matches the nodes of the two trees, with the top-down phase then the bottom-up phase,
+	// and returns the resulting mappings.
+	// The matching is only done once, later calls return the same mappings.
+	Compare() MappingStore
+
+	// CompareContext matches the nodes as Compare does, and stops when `ctx` is done,
+	// checking it between the layers of the top-down phase and between the nodes of the bottom-up phase and of its recovery.
+	// It then returns the mappings found so far, which are consistent, i.e. one-to-one between nodes of the same label,
+	// except for the roots of the trees, which the bottom-up phase maps to each other whatever their labels,
+	// with an error wrapping ErrBudgetExceeded and the error of the context.
+	// Later calls return the same mappings and error.
+	CompareContext(ctx context.Context) (MappingStore, error)
+
+	// Degradations returns the limits reached by Compare, which are none without Limits.
+	Degradations() []Degradation
 }
 
 type comparator struct {
 	tree1, tree2       *ast.AST
 	list1, list2       HeightIndexedPriorityList
+	hashMemo1          ast.NodeHashMemo
+	hashMemo2          ast.NodeHashMemo
 	candidateMappings  mappingsType
 	uniqueMappings     mappingsType
+	mappings           MappingStore
 	minDice            float64
 	minHeight, maxSize int
-	logger             slog.Logger
+	limits             Limits
+	degradations       []Degradation
+
+	// err is the error of the matching, if it has been stopped.
+	err error
+
+	// order1 and order2 number the nodes of the trees in preorder.
+	order1, order2 *preorder
+
+	logger slog.Logger
+}
+
+func (c *comparator) Compare() MappingStore {
+	mappings, _ := c.CompareContext(context.Background())
+	return mappings
 }
 
+func (c *comparator) CompareContext(ctx context.Context) (MappingStore, error) {
+	if c.mappings != nil {
+		return c.mappings, c.err
+	}
+
+	c.mappings = NewMappingStore()
+	if (*c.tree1).Root() == nil || (*c.tree2).Root() == nil {
+		return c.mappings, nil
+	}
+
+	// The mappings of the top-down phase are added even if it has been stopped,
+	// since each of them pairs whole isomorphic subtrees.
+	c.err = c.topDown(ctx)
+	for _, mapping := range c.uniqueMappings {
+		c.mappings.Add(mapping.Left(), mapping.Right())
+	}
+
+	if c.err == nil {
+		c.err = c.bottomUp(ctx)
+	}
+	return c.mappings, c.err
+}
+
+func (c *comparator) Degradations() []Degradation {
+	return c.degradations
+}
+
+// NewComparator creates a Comparator with positional parameters, see Config.
+// It panics if the parameters are invalid, unlike New which returns an error.
 func NewComparator(
 	tree1, tree2 *ast.AST,
 	minHeight, maxSize int,
 	minDice float64,
 	logger slog.Logger,
 ) Comparator {
-	if tree1 == nil || tree2 == nil {
-		panic("trees cannot be nil")
-	}
-	if minHeight < 0 {
-		panic("minHeight cannot be negative")
-	}
-
-	return &comparator{
-		tree1:             tree1,
-		tree2:             tree2,
-		list1:             NewHeightIndexedPriorityList(logger),
-		list2:             NewHeightIndexedPriorityList(logger),
-		candidateMappings: make(mappingsType, 0),
-		uniqueMappings:    make(mappingsType, 0),
-		minDice:           minDice,
-		minHeight:         minHeight,
-		maxSize:           maxSize,
+	c, err := New(
+		tree1, tree2,
+		WithConfig(Config{MinHeight: minHeight, MaxSize: maxSize, MinDice: minDice}),
+		WithLogger(&logger),
+	)
+	if err != nil {
+		panic(err)
 	}
+	return c
 }
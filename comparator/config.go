@@ -0,0 +1,155 @@
+package comparator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"os"
+)
+
+// ErrInvalidConfig is returned when the parameters of a comparator are out of their range.
+var ErrInvalidConfig = errors.New("invalid comparator config")
+
+// Config holds the parameters of the matching, which can be loaded from a JSON file, see LoadConfig.
+type Config struct {
+	// MinHeight is the height up to which the top-down phase leaves subtrees to the bottom-up phase.
+	MinHeight int `json:"minHeight"`
+
+	// MaxSize is the number of descendants from which the bottom-up phase does not recover the mappings of a container.
+	MaxSize int `json:"maxSize"`
+
+	// MinDice is the ratio of common descendants, from 0 to 1, above which the bottom-up phase maps two containers.
+	MinDice float64 `json:"minDice"`
+
+	// Limits bound the memory and time taken by the matching, see Limits. The zero value sets no limit.
+	Limits Limits `json:"limits"`
+}
+
+// DefaultConfig returns the parameters recommended by the GumTree paper, without limits.
+func DefaultConfig() Config {
+	return Config{
+		MinHeight: DefaultMinHeight,
+		MaxSize:   DefaultMaxSize,
+		MinDice:   DefaultMinDice,
+	}
+}
+
+// Validate returns an error wrapping ErrInvalidConfig if a parameter is out of its range.
+func (c Config) Validate() error {
+	switch {
+	case c.MinHeight < 0:
+		return fmt.Errorf("%w: minHeight %d is negative", ErrInvalidConfig, c.MinHeight)
+	case c.MaxSize < 0:
+		return fmt.Errorf("%w: maxSize %d is negative", ErrInvalidConfig, c.MaxSize)
+	case !(c.MinDice >= 0 && c.MinDice <= 1):
+		return fmt.Errorf("%w: minDice %v is not between 0 and 1", ErrInvalidConfig, c.MinDice)
+	case c.Limits.MaxCandidatePairs < 0 || c.Limits.MaxRecoveryCells < 0 || c.Limits.MaxContainerCandidates < 0:
+		return fmt.Errorf("%w: limits %+v are negative", ErrInvalidConfig, c.Limits)
+	}
+	return nil
+}
+
+// LoadConfig reads the JSON file at `path`, e.g. `{"minHeight": 1, "limits": {"maxCandidatePairs": 1000}}`,
+// whose missing parameters keep their default value, then validates it.
+// Unknown parameters are rejected, so that a misspelled one is not silently ignored.
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+
+	config := DefaultConfig()
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Option customizes a comparator created by New.
+type Option func(*options)
+
+type options struct {
+	config               Config
+	hashMemo1, hashMemo2 ast.NodeHashMemo
+	logger               *slog.Logger
+}
+
+// WithConfig replaces all the parameters of the matching, see Config.
+func WithConfig(config Config) Option {
+	return func(o *options) { o.config = config }
+}
+
+// WithMinHeight sets Config.MinHeight.
+func WithMinHeight(minHeight int) Option {
+	return func(o *options) { o.config.MinHeight = minHeight }
+}
+
+// WithMaxSize sets Config.MaxSize.
+func WithMaxSize(maxSize int) Option {
+	return func(o *options) { o.config.MaxSize = maxSize }
+}
+
+// WithMinDice sets Config.MinDice.
+func WithMinDice(minDice float64) Option {
+	return func(o *options) { o.config.MinDice = minDice }
+}
+
+// WithLimits sets Config.Limits.
+func WithLimits(limits Limits) Option {
+	return func(o *options) { o.config.Limits = limits }
+}
+
+// WithHashMemos gives the hash memos of the trees,
+// e.g. cached ones or ones kept up to date with NodeHashMemo.Rehash while the trees are edited.
+// A nil memo is computed by the comparator.
+func WithHashMemos(hashMemo1, hashMemo2 ast.NodeHashMemo) Option {
+	return func(o *options) { o.hashMemo1, o.hashMemo2 = hashMemo1, hashMemo2 }
+}
+
+// WithLogger sets the logger of the comparator, which is slog.Default() otherwise.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// New creates a Comparator matching `tree1` against `tree2` with the default parameters customized by `opts`.
+// It returns an error wrapping ErrInvalidConfig instead of a comparator if a tree is nil or a parameter is out of its range.
+func New(tree1, tree2 *ast.AST, opts ...Option) (Comparator, error) {
+	o := options{config: DefaultConfig()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if tree1 == nil || tree2 == nil {
+		return nil, fmt.Errorf("%w: trees cannot be nil", ErrInvalidConfig)
+	}
+	if err := o.config.Validate(); err != nil {
+		return nil, err
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+
+	return &comparator{
+		tree1:             tree1,
+		tree2:             tree2,
+		list1:             NewHeightIndexedPriorityList(*o.logger),
+		list2:             NewHeightIndexedPriorityList(*o.logger),
+		hashMemo1:         o.hashMemo1,
+		hashMemo2:         o.hashMemo2,
+		candidateMappings: make(mappingsType, 0),
+		uniqueMappings:    make(mappingsType, 0),
+		minDice:           o.config.MinDice,
+		minHeight:         o.config.MinHeight,
+		maxSize:           o.config.MaxSize,
+		limits:            o.config.Limits,
+		logger:            *o.logger,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package comparator_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test the default config matches GumTree", func(t *testing.T) {
+		t.Parallel()
+
+		config := comparator.DefaultConfig()
+		if config.MinHeight != 2 || config.MaxSize != 1000 || config.MinDice != 0.5 {
+			t.Errorf("Expected minHeight 2, maxSize 1000 and minDice 0.5, got %+v", config)
+		}
+		if err := config.Validate(); err != nil {
+			t.Errorf("Expected the default config to be valid, got %v", err)
+		}
+	})
+
+	invalid := map[string]comparator.Option{
+		"negative minHeight": comparator.WithMinHeight(-1),
+		"negative maxSize":   comparator.WithMaxSize(-1),
+		"minDice above 1":    comparator.WithMinDice(1.5),
+		"negative limits":    comparator.WithLimits(comparator.Limits{MaxRecoveryCells: -1}),
+	}
+	for name, option := range invalid {
+		t.Run("Test New rejects a "+name, func(t *testing.T) {
+			t.Parallel()
+
+			src, dst := statementsTree("1"), statementsTree("2")
+			c, err := comparator.New(&src, &dst, option)
+			if !errors.Is(err, comparator.ErrInvalidConfig) {
+				t.Errorf("Expected an invalid config error, got %v", err)
+			}
+			if c != nil {
+				t.Errorf("Expected no comparator")
+			}
+		})
+	}
+
+	t.Run("Test New rejects nil trees", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := comparator.New(nil, nil); !errors.Is(err, comparator.ErrInvalidConfig) {
+			t.Errorf("Expected an invalid config error, got %v", err)
+		}
+	})
+
+	t.Run("Test New matches as NewComparator does", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "2", "3"), statementsTree("3", "4", "1")
+		c, err := comparator.New(&src, &dst, comparator.WithMinHeight(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		mappings := c.Compare()
+		assertConsistent(t, mappings)
+
+		srcStmts, dstStmts := src.Root().OrderedChildren(), dst.Root().OrderedChildren()
+		if !mappings.Has(srcStmts[0], dstStmts[2]) || !mappings.Has(srcStmts[2], dstStmts[0]) {
+			t.Errorf("Expected the statements f(1) and f(3) to be mapped to their copies")
+		}
+	})
+
+	t.Run("Test New given the hash memos matches as without them", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := generatedTrees(1_000)
+		expected := comparator.NewComparator(&src, &dst, comparator.DefaultMinHeight, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{}).Compare().Pairs()
+
+		c, err := comparator.New(&src, &dst, comparator.WithHashMemos(src.MakeHashMemo(), dst.MakeHashMemo()), comparator.WithLogger(&slog.Logger{}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		pairs := c.Compare().Pairs()
+		if len(pairs) != len(expected) {
+			t.Fatalf("Expected %d mappings, got %d", len(expected), len(pairs))
+		}
+		for i := range expected {
+			if pairs[i].Left() != expected[i].Left() || pairs[i].Right() != expected[i].Right() {
+				t.Fatalf("Expected the mapping %d to be %v, got %v", i, expected[i], pairs[i])
+			}
+		}
+	})
+
+	t.Run("Test LoadConfig keeps the defaults of missing parameters", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(`{"minHeight": 1, "limits": {"maxCandidatePairs": 10}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := comparator.LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := comparator.DefaultConfig()
+		expected.MinHeight = 1
+		expected.Limits.MaxCandidatePairs = 10
+		if config != expected {
+			t.Errorf("Expected %+v, got %+v", expected, config)
+		}
+	})
+
+	files := map[string]string{
+		"an unknown parameter":  `{"minHight": 1}`,
+		"an invalid parameter":  `{"minDice": 2}`,
+		"a malformed JSON file": `{"minHeight": `,
+	}
+	for name, content := range files {
+		t.Run("Test LoadConfig rejects "+name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := comparator.LoadConfig(path); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
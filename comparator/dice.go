@@ -1,6 +1,7 @@
 package comparator
 
 import (
+	"context"
 	"github.com/Xanonymous-GitHub/gumtree-go/ast"
 	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
 	"github.com/samber/lo"
@@ -20,13 +21,17 @@ func GetDiceValueOf(pair Pair[*ast.Node, *ast.Node]) float64 {
 	return float64(2.0*n1Degree) / float64(n1Degree+n2Degree)
 }
 
-func (c *comparator) handleCandidateMappings() {
+func (c *comparator) handleCandidateMappings(ctx context.Context) error {
 	// Sort the candidate mappings by their dice values in descending order.
 	sort.SliceStable(c.candidateMappings, func(i, j int) bool {
 		return GetDiceValueOf(c.candidateMappings[i]) > GetDiceValueOf(c.candidateMappings[j])
 	})
 
 	for len(c.candidateMappings) > 0 {
+		if err := budgetErrorOf(ctx); err != nil {
+			return err
+		}
+
 		// Pop the mapping with the highest dice value.
 		mapping := c.candidateMappings[0]
 		c.candidateMappings = c.candidateMappings[1:]
@@ -39,4 +44,20 @@ func (c *comparator) handleCandidateMappings() {
 			return !pair.Left().IsEqualTo(mapping.Left()) && !pair.Right().IsEqualTo(mapping.Right())
 		})
 	}
+	return nil
+}
+
+// diceOf returns the ratio of common descendants between `n1` and `n2` given the mappings,
+// i.e. dice(t1, t2, M) = 2 * |{t1' ∈ s(t1) | (t1', t2') ∈ M ∧ t2' ∈ s(t2)}| / (|s(t1)| + |s(t2)|).
+// `mapped` are the sorted preorder indices of the destination nodes mapped to the descendants of `n1`, see mappedIndicesOf:
+// the common descendants are the ones within the interval of the descendants of `n2`.
+func (c *comparator) diceOf(n1, n2 *ast.Node, mapped []int) float64 {
+	size1, size2 := c.order1.sizeOf(n1), c.order2.sizeOf(n2)
+	if size1 == 0 && size2 == 0 {
+		return 0
+	}
+
+	i := c.order2.index[n2]
+	common := sort.SearchInts(mapped, i+size2+1) - sort.SearchInts(mapped, i+1)
+	return float64(2*common) / float64(size1+size2)
 }
@@ -5,7 +5,6 @@ import (
 	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
 	. "github.com/deckarep/golang-set/v2"
 	"github.com/samber/lo"
-	"golang.org/x/exp/maps"
 )
 
 type mappingsType []Pair[*ast.Node, *ast.Node]
@@ -19,29 +18,76 @@ type IsomorphicMappings interface {
 
 type isomorphicMappings struct {
 	hashToNodePairs map[uint64]isomorphicNodesType
+
+	// hashes are the keys of hashToNodePairs in the order they have been added, so that the groups come in a stable order.
+	hashes []uint64
+}
+
+// groups returns the groups of nodes in the order their hashes have been added.
+func (i *isomorphicMappings) groups() []isomorphicNodesType {
+	groups := make([]isomorphicNodesType, 0, len(i.hashes))
+	for _, hash := range i.hashes {
+		groups = append(groups, i.hashToNodePairs[hash])
+	}
+	return groups
+}
+
+// groupOf returns the group of the nodes with the hash `hash`, creating it if needed.
+func (i *isomorphicMappings) groupOf(hash uint64) isomorphicNodesType {
+	group, ok := i.hashToNodePairs[hash]
+	if !ok {
+		group = NewPair(NewSet[*ast.Node](), NewSet[*ast.Node]())
+		i.hashToNodePairs[hash] = group
+		i.hashes = append(i.hashes, hash)
+	}
+	return group
 }
 
 func (i *isomorphicMappings) UniqueIsomorphicMappings() []isomorphicNodesType {
-	return lo.Filter(maps.Values(i.hashToNodePairs), func(sameHashPair isomorphicNodesType, idx int) bool {
+	return lo.Filter(i.groups(), func(sameHashPair isomorphicNodesType, idx int) bool {
 		return sameHashPair.Left().Cardinality() == 1 && sameHashPair.Right().Cardinality() == 1
 	})
 }
 
 func (i *isomorphicMappings) NonUniqueIsomorphicMappings() []isomorphicNodesType {
-	return lo.Filter(maps.Values(i.hashToNodePairs), func(sameHashPair isomorphicNodesType, idx int) bool {
+	return lo.Filter(i.groups(), func(sameHashPair isomorphicNodesType, idx int) bool {
 		return (sameHashPair.Left().Cardinality() > 1 && sameHashPair.Right().Cardinality() >= 1) ||
 			(sameHashPair.Left().Cardinality() >= 1 && sameHashPair.Right().Cardinality() > 1)
 	})
 }
 
 func (i *isomorphicMappings) NonIsomorphicMappings() []isomorphicNodesType {
-	return lo.Filter(maps.Values(i.hashToNodePairs), func(sameHashPair isomorphicNodesType, idx int) bool {
+	return lo.Filter(i.groups(), func(sameHashPair isomorphicNodesType, idx int) bool {
 		return sameHashPair.Left().Cardinality() == 0 || sameHashPair.Right().Cardinality() == 0
 	})
 }
 
+// GroupIsomorphic groups the nodes `nodes1` of the source tree and `nodes2` of the destination tree by their hash,
+// bucketing each side directly in O(n+m), rather than pairing every node of one side with every node of the other.
+// The groups come in the order of the first node of each hash, sources first.
+func GroupIsomorphic(memo1, memo2 ast.NodeHashMemo, nodes1, nodes2 []*ast.Node) IsomorphicMappings {
+	i := &isomorphicMappings{hashToNodePairs: make(map[uint64]isomorphicNodesType)}
+	for _, n := range nodes1 {
+		hash, ok := memo1[n.Id]
+		if !ok {
+			panic("nodes should be in the memo")
+		}
+		i.groupOf(hash).Left().Add(n)
+	}
+	for _, n := range nodes2 {
+		hash, ok := memo2[n.Id]
+		if !ok {
+			panic("nodes should be in the memo")
+		}
+		i.groupOf(hash).Right().Add(n)
+	}
+	return i
+}
+
+// NewIsomorphicMappings groups the nodes of the pairs `mappings` by their hash, as GroupIsomorphic does,
+// every node being added to the group of its own hash whatever the node it is paired with.
 func NewIsomorphicMappings(memo1, memo2 ast.NodeHashMemo, mappings mappingsType) IsomorphicMappings {
-	hashToNodePairs := make(map[uint64]isomorphicNodesType)
+	i := &isomorphicMappings{hashToNodePairs: make(map[uint64]isomorphicNodesType)}
 
 	for _, mapping := range mappings {
 		if mapping.Left() == nil || mapping.Right() == nil {
@@ -54,20 +100,11 @@ func NewIsomorphicMappings(memo1, memo2 ast.NodeHashMemo, mappings mappingsType)
 			panic("mapping should contain nodes in the memo")
 		}
 
-		if _, ok := hashToNodePairs[hashOfLeft]; !ok {
-			hashToNodePairs[hashOfLeft] = NewPair(NewSet[*ast.Node](), NewSet[*ast.Node]())
-		}
-		if _, ok := hashToNodePairs[hashOfRight]; !ok {
-			hashToNodePairs[hashOfRight] = NewPair(NewSet[*ast.Node](), NewSet[*ast.Node]())
-		}
-
-		hashToNodePairs[hashOfLeft].Left().Add(mapping.Left())
-		hashToNodePairs[hashOfLeft].Right().Add(mapping.Right())
+		i.groupOf(hashOfLeft).Left().Add(mapping.Left())
+		i.groupOf(hashOfRight).Right().Add(mapping.Right())
 	}
 
-	return &isomorphicMappings{
-		hashToNodePairs: hashToNodePairs,
-	}
+	return i
 }
 
 func forEachIsomorphicNodesPairOf(p Pair[*ast.Node, *ast.Node], f func(Pair[*ast.Node, *ast.Node])) {
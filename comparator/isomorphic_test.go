@@ -0,0 +1,117 @@
+package comparator_test
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
+	. "github.com/deckarep/golang-set/v2"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// layerOf builds a tree whose root has a subtree `Stmt(Lit: value)` per value, and returns these subtrees and the hash memo.
+func layerOf(values ...string) ([]*ast.Node, ast.NodeHashMemo) {
+	tree := ast.NewAST(slog.Logger{})
+	root, _ := tree.Add(nil, -1, "Block", "")
+	nodes := make([]*ast.Node, 0, len(values))
+	for i, value := range values {
+		stmt, _ := tree.Add(root, i, "Stmt", "")
+		_, _ = tree.Add(stmt, 0, "Lit", ast.NodeValueType(value))
+		nodes = append(nodes, stmt)
+	}
+	return nodes, tree.MakeHashMemo()
+}
+
+// describe summarizes groups of isomorphic nodes as `value:left:right`, i.e. the value of their subtrees
+// and the number of nodes of each side, sorted.
+func describe[G interface {
+	Left() Set[*ast.Node]
+	Right() Set[*ast.Node]
+}](groups []G) []string {
+	descriptions := make([]string, 0, len(groups))
+	for _, group := range groups {
+		nodes := append(group.Left().ToSlice(), group.Right().ToSlice()...)
+		value := nodes[0].OrderedChildren()[0].Value
+		descriptions = append(descriptions, fmt.Sprintf("%s:%d:%d", value, group.Left().Cardinality(), group.Right().Cardinality()))
+	}
+	slices.Sort(descriptions)
+	return descriptions
+}
+
+func TestIsomorphicMappings(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name                             string
+		left, right                      []string
+		unique, nonUnique, nonIsomorphic []string
+	}{
+		{
+			name:   "unique",
+			left:   []string{"a", "b"},
+			right:  []string{"b", "a"},
+			unique: []string{"a:1:1", "b:1:1"},
+		},
+		{
+			name:      "ambiguous",
+			left:      []string{"a", "a"},
+			right:     []string{"a"},
+			nonUnique: []string{"a:2:1"},
+		},
+		{
+			name:          "non-isomorphic",
+			left:          []string{"a"},
+			right:         []string{"b"},
+			nonIsomorphic: []string{"a:1:0", "b:0:1"},
+		},
+		{
+			name:          "mixed",
+			left:          []string{"a", "b", "c", "c"},
+			right:         []string{"c", "a", "d", "c", "c"},
+			unique:        []string{"a:1:1"},
+			nonUnique:     []string{"c:2:3"},
+			nonIsomorphic: []string{"b:1:0", "d:0:1"},
+		},
+	}
+
+	constructors := map[string]func(memo1, memo2 ast.NodeHashMemo, nodes1, nodes2 []*ast.Node) comparator.IsomorphicMappings{
+		"NewIsomorphicMappings": func(memo1, memo2 ast.NodeHashMemo, nodes1, nodes2 []*ast.Node) comparator.IsomorphicMappings {
+			return comparator.NewIsomorphicMappings(memo1, memo2, CrossPairOf(nodes1, nodes2))
+		},
+		"GroupIsomorphic": comparator.GroupIsomorphic,
+	}
+
+	for name, newMappings := range constructors {
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("Test %s with a %s layer", name, tc.name), func(t *testing.T) {
+				t.Parallel()
+
+				nodes1, memo1 := layerOf(tc.left...)
+				nodes2, memo2 := layerOf(tc.right...)
+				mappings := newMappings(memo1, memo2, nodes1, nodes2)
+
+				if got := describe(mappings.UniqueIsomorphicMappings()); !slices.Equal(got, tc.unique) {
+					t.Errorf("Expected unique groups %v, got %v", tc.unique, got)
+				}
+				if got := describe(mappings.NonUniqueIsomorphicMappings()); !slices.Equal(got, tc.nonUnique) {
+					t.Errorf("Expected non-unique groups %v, got %v", tc.nonUnique, got)
+				}
+				if got := describe(mappings.NonIsomorphicMappings()); !slices.Equal(got, tc.nonIsomorphic) {
+					t.Errorf("Expected non-isomorphic groups %v, got %v", tc.nonIsomorphic, got)
+				}
+			})
+		}
+	}
+
+	t.Run("Test GroupIsomorphic with an empty side", func(t *testing.T) {
+		t.Parallel()
+
+		nodes1, memo1 := layerOf("a", "a")
+		mappings := comparator.GroupIsomorphic(memo1, nil, nodes1, nil)
+		if got := describe(mappings.NonIsomorphicMappings()); !slices.Equal(got, []string{"a:2:0"}) {
+			t.Errorf("Expected the nodes to be non-isomorphic, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package comparator
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
+)
+
+// Limits bound the memory and time taken by the matching of large trees, e.g. generated files of millions of nodes,
+// at the cost of the quality of the mappings once a limit is reached.
+// The zero Limits sets no limit.
+type Limits struct {
+	// MaxCandidatePairs is the maximum number of candidate pairs of isomorphic subtrees that are not unique,
+	// kept by the top-down phase to be matched by their dice value.
+	// Past it, the isomorphic subtrees of a hash are paired in the order of the trees instead.
+	MaxCandidatePairs int
+
+	// MaxRecoveryCells is the maximum size of the table of the longest common subsequence
+	// of the unmatched children of two nodes, computed by the recovery of the bottom-up phase.
+	// Past it, only the children with a hash unique among the children of both nodes are matched.
+	MaxRecoveryCells int
+
+	// MaxContainerCandidates is the maximum number of containers of the destination tree
+	// whose dice value with a node of the source tree is computed by the bottom-up phase.
+	// Past it, the node is only matched to the best of the candidates found first,
+	// i.e. the closest ancestors of the nodes mapped to its first descendants.
+	MaxContainerCandidates int
+}
+
+// DefaultLimits returns limits keeping the matching of trees of millions of nodes within a few hundred megabytes
+// and its bottom-up phase within a bounded number of dice values per node.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxCandidatePairs: 1_000_000,
+		MaxRecoveryCells:  10_000_000,
+
+		MaxContainerCandidates: 1_000,
+	}
+}
+
+// Degradation tells that a limit has been reached during the matching, which may have missed some mappings.
+type Degradation struct {
+	// Limit is the name of the limit, e.g. "MaxCandidatePairs".
+	Limit string
+
+	// Count is the number of times the limit has been reached.
+	Count int
+}
+
+func (d Degradation) String() string {
+	return fmt.Sprintf("%s reached %d times", d.Limit, d.Count)
+}
+
+// degrade records that the limit named `limit` has been reached.
+func (c *comparator) degrade(limit string) {
+	for i := range c.degradations {
+		if c.degradations[i].Limit == limit {
+			c.degradations[i].Count++
+			return
+		}
+	}
+	c.degradations = append(c.degradations, Degradation{Limit: limit, Count: 1})
+}
+
+// addCandidates adds the pairs of the isomorphic nodes `left` and `right` to the candidate mappings,
+// or pairs them in order if that would exceed MaxCandidatePairs.
+func (c *comparator) addCandidates(left, right []*ast.Node) {
+	if c.limits.MaxCandidatePairs <= 0 || len(c.candidateMappings)+len(left)*len(right) <= c.limits.MaxCandidatePairs {
+		c.candidateMappings = append(c.candidateMappings, CrossPairOf(left, right)...)
+		return
+	}
+
+	c.degrade("MaxCandidatePairs")
+	for i := 0; i < min(len(left), len(right)); i++ {
+		forEachIsomorphicNodesPairOf(NewPair(left[i], right[i]), func(pair Pair[*ast.Node, *ast.Node]) {
+			c.uniqueMappings = append(c.uniqueMappings, pair)
+		})
+	}
+}
+
+// matchUniqueHashes is the bounded counterpart of the longest common subsequence of the recovery:
+// it matches the children of `children1` and `children2` whose hash is unique among both lists.
+func (c *comparator) matchUniqueHashes(children1, children2 []*ast.Node) {
+	count1, count2 := make(map[uint64]int), make(map[uint64]int)
+	byHash2 := make(map[uint64]*ast.Node)
+	for _, n := range children1 {
+		count1[c.hashMemo1[n.Id]]++
+	}
+	for _, n := range children2 {
+		hash := c.hashMemo2[n.Id]
+		count2[hash]++
+		byHash2[hash] = n
+	}
+
+	for _, n := range children1 {
+		hash := c.hashMemo1[n.Id]
+		if count1[hash] == 1 && count2[hash] == 1 {
+			forEachIsomorphicNodesPairOf(NewPair(n, byHash2[hash]), func(pair Pair[*ast.Node, *ast.Node]) {
+				c.mappings.Add(pair.Left(), pair.Right())
+			})
+		}
+	}
+}
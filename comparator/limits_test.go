@@ -0,0 +1,154 @@
+package comparator_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"log/slog"
+	"testing"
+)
+
+// statementsTree builds a block of statements `f(arg)`, one per argument.
+func statementsTree(args ...string) ast.AST {
+	tree := ast.NewAST(slog.Logger{})
+	root, _ := tree.Add(nil, -1, "Block", "")
+	for i, arg := range args {
+		stmt, _ := tree.Add(root, i, "ExprStmt", "")
+		call, _ := tree.Add(stmt, 0, "CallExpr", "")
+		_, _ = tree.Add(call, 0, "Ident", "f")
+		_, _ = tree.Add(call, 1, "BasicLit", ast.NodeValueType(arg))
+	}
+	return tree
+}
+
+// assertConsistent checks that the mappings only pair nodes with the same label.
+func assertConsistent(t *testing.T, mappings comparator.MappingStore) {
+	t.Helper()
+
+	for _, pair := range mappings.Pairs() {
+		if pair.Left().Label != pair.Right().Label {
+			t.Errorf("Expected mapped nodes to have the same label, got %s and %s", pair.Left().Label, pair.Right().Label)
+		}
+	}
+}
+
+// groupsTree builds a block of groups of statements `f(arg)`, one group per list of arguments.
+func groupsTree(groups ...[]string) ast.AST {
+	tree := ast.NewAST(slog.Logger{})
+	root, _ := tree.Add(nil, -1, "Block", "")
+	for i, args := range groups {
+		group, _ := tree.Add(root, i, "Group", "")
+		for j, arg := range args {
+			stmt, _ := tree.Add(group, j, "ExprStmt", "")
+			call, _ := tree.Add(stmt, 0, "CallExpr", "")
+			_, _ = tree.Add(call, 0, "Ident", "f")
+			_, _ = tree.Add(call, 1, "BasicLit", ast.NodeValueType(arg))
+		}
+	}
+	return tree
+}
+
+// compareBounded matches the trees within `limits`, with a minimum height of 1 unless `options` set another.
+func compareBounded(t *testing.T, src, dst ast.AST, limits comparator.Limits, options ...comparator.Option) comparator.Comparator {
+	t.Helper()
+
+	options = append([]comparator.Option{comparator.WithMinHeight(1), comparator.WithLimits(limits), comparator.WithLogger(&slog.Logger{})}, options...)
+	c, err := comparator.New(&src, &dst, options...)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	c.Compare()
+	return c
+}
+
+func TestBoundedComparator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test unbounded matching reports no degradation", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "1", "1"), statementsTree("1", "1", "1")
+		c := comparator.NewComparator(&src, &dst, 1, comparator.DefaultMaxSize, comparator.DefaultMinDice, slog.Logger{})
+		c.Compare()
+		if len(c.Degradations()) != 0 {
+			t.Errorf("Expected no degradation, got %v", c.Degradations())
+		}
+	})
+
+	t.Run("Test bounded matching joins unique isomorphic subtrees by hash", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "2", "3"), statementsTree("3", "4", "1")
+		c := compareBounded(t, src, dst, comparator.DefaultLimits())
+		mappings := c.Compare()
+		assertConsistent(t, mappings)
+		if len(c.Degradations()) != 0 {
+			t.Errorf("Expected no degradation, got %v", c.Degradations())
+		}
+
+		srcStmts, dstStmts := src.Root().OrderedChildren(), dst.Root().OrderedChildren()
+		if !mappings.Has(srcStmts[0], dstStmts[2]) || !mappings.Has(srcStmts[2], dstStmts[0]) {
+			t.Errorf("Expected the statements f(1) and f(3) to be mapped to their copies")
+		}
+	})
+
+	t.Run("Test ambiguous subtrees are paired in order past the candidate limit", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := statementsTree("1", "1", "1"), statementsTree("1", "1", "1", "1")
+		c := compareBounded(t, src, dst, comparator.Limits{MaxCandidatePairs: 4})
+		mappings := c.Compare()
+		assertConsistent(t, mappings)
+
+		degradations := c.Degradations()
+		if len(degradations) != 1 || degradations[0].Limit != "MaxCandidatePairs" || degradations[0].Count != 1 {
+			t.Fatalf("Expected the candidate limit to be reached once, got %v", degradations)
+		}
+		srcStmts, dstStmts := src.Root().OrderedChildren(), dst.Root().OrderedChildren()
+		for i := range srcStmts {
+			if !mappings.Has(srcStmts[i], dstStmts[i]) {
+				t.Errorf("Expected the statement %d to be mapped to the statement %d", i, i)
+			}
+		}
+	})
+
+	t.Run("Test containers are matched among the first candidates past the container limit", func(t *testing.T) {
+		t.Parallel()
+
+		// The statements of the source group are split between two destination groups, which are both candidates.
+		src, dst := groupsTree([]string{"1", "2"}), groupsTree([]string{"1"}, []string{"2"})
+		c := compareBounded(t, src, dst, comparator.Limits{MaxContainerCandidates: 1})
+		mappings := c.Compare()
+		assertConsistent(t, mappings)
+
+		degradations := c.Degradations()
+		if len(degradations) != 1 || degradations[0].Limit != "MaxContainerCandidates" || degradations[0].Count != 1 {
+			t.Fatalf("Expected the container limit to be reached once, got %v", degradations)
+		}
+		srcGroups, dstGroups := src.Root().OrderedChildren(), dst.Root().OrderedChildren()
+		if !mappings.Has(srcGroups[0], dstGroups[0]) {
+			t.Errorf("Expected the group to be mapped to the first candidate")
+		}
+	})
+
+	t.Run("Test recovery only matches unique children past the cell limit", func(t *testing.T) {
+		t.Parallel()
+
+		// The minimum height leaves every node to the bottom-up phase, which maps the roots then recovers their children.
+		src, dst := statementsTree("1", "2", "2"), statementsTree("2", "1", "2")
+		c := compareBounded(t, src, dst, comparator.Limits{MaxRecoveryCells: 4}, comparator.WithMinHeight(10))
+		mappings := c.Compare()
+		assertConsistent(t, mappings)
+
+		degradations := c.Degradations()
+		if len(degradations) != 1 || degradations[0].Limit != "MaxRecoveryCells" {
+			t.Fatalf("Expected the recovery limit to be reached, got %v", degradations)
+		}
+		srcStmts, dstStmts := src.Root().OrderedChildren(), dst.Root().OrderedChildren()
+		if !mappings.Has(srcStmts[0], dstStmts[1]) {
+			t.Errorf("Expected the unique statement f(1) to be mapped")
+		}
+		if mappings.IsSrcMapped(srcStmts[1]) || mappings.IsSrcMapped(srcStmts[2]) {
+			t.Errorf("Expected the duplicated statements f(2) to be left unmapped")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package comparator
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
+)
+
+// MappingStore is a one-to-one correspondence between the nodes of two trees,
+// the source tree and the destination tree.
+type MappingStore interface {
+	// Add maps the source node `n1` to the destination node `n2`.
+	// It returns false and does nothing if one of them is already mapped.
+	Add(n1, n2 *ast.Node) bool
+
+	// Has returns true if `n1` is mapped to `n2`.
+	Has(n1, n2 *ast.Node) bool
+
+	// DstOf returns the destination node mapped to the source node `n1`, or nil if there is none.
+	DstOf(n1 *ast.Node) *ast.Node
+
+	// SrcOf returns the source node mapped to the destination node `n2`, or nil if there is none.
+	SrcOf(n2 *ast.Node) *ast.Node
+
+	// IsSrcMapped returns true if the source node `n1` is mapped.
+	IsSrcMapped(n1 *ast.Node) bool
+
+	// IsDstMapped returns true if the destination node `n2` is mapped.
+	IsDstMapped(n2 *ast.Node) bool
+
+	// Pairs returns the mappings in the order they have been added.
+	Pairs() []Pair[*ast.Node, *ast.Node]
+
+	// Size returns the number of mappings.
+	Size() int
+}
+
+type mappingStore struct {
+	srcToDst map[*ast.Node]*ast.Node
+	dstToSrc map[*ast.Node]*ast.Node
+	pairs    mappingsType
+}
+
+func (m *mappingStore) Add(n1, n2 *ast.Node) bool {
+	if n1 == nil || n2 == nil || m.IsSrcMapped(n1) || m.IsDstMapped(n2) {
+		return false
+	}
+
+	m.srcToDst[n1] = n2
+	m.dstToSrc[n2] = n1
+	m.pairs = append(m.pairs, NewPair(n1, n2))
+	return true
+}
+
+func (m *mappingStore) Has(n1, n2 *ast.Node) bool {
+	dst, ok := m.srcToDst[n1]
+	return ok && dst == n2
+}
+
+func (m *mappingStore) DstOf(n1 *ast.Node) *ast.Node {
+	return m.srcToDst[n1]
+}
+
+func (m *mappingStore) SrcOf(n2 *ast.Node) *ast.Node {
+	return m.dstToSrc[n2]
+}
+
+func (m *mappingStore) IsSrcMapped(n1 *ast.Node) bool {
+	_, ok := m.srcToDst[n1]
+	return ok
+}
+
+func (m *mappingStore) IsDstMapped(n2 *ast.Node) bool {
+	_, ok := m.dstToSrc[n2]
+	return ok
+}
+
+func (m *mappingStore) Pairs() []Pair[*ast.Node, *ast.Node] {
+	pairs := make([]Pair[*ast.Node, *ast.Node], len(m.pairs))
+	copy(pairs, m.pairs)
+	return pairs
+}
+
+func (m *mappingStore) Size() int {
+	return len(m.pairs)
+}
+
+// NewMappingStore creates an empty MappingStore.
+func NewMappingStore() MappingStore {
+	return &mappingStore{
+		srcToDst: make(map[*ast.Node]*ast.Node),
+		dstToSrc: make(map[*ast.Node]*ast.Node),
+		pairs:    make(mappingsType, 0),
+	}
+}
@@ -0,0 +1,43 @@
+package comparator
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+)
+
+// preorder numbers the nodes of a tree in preorder, along with the number of their descendants,
+// so that the descendants of a node are the interval of the nodes following it, which is not rebuilt for every query.
+type preorder struct {
+	nodes []*ast.Node
+	index map[*ast.Node]int
+
+	// sizes are the numbers of descendants of the nodes, by index.
+	sizes []int
+}
+
+func preorderOf(tree ast.AST) *preorder {
+	nodes := tree.PreOrderNodes()
+	p := &preorder{
+		nodes: nodes,
+		index: make(map[*ast.Node]int, len(nodes)),
+		sizes: make([]int, len(nodes)),
+	}
+	for i, n := range nodes {
+		p.index[n] = i
+	}
+	// Children follow their parent, so the size of every child is known when it is added to the size of its parent.
+	for i := len(nodes) - 1; i > 0; i-- {
+		p.sizes[p.index[nodes[i].Parent]] += p.sizes[i] + 1
+	}
+	return p
+}
+
+// descendantsOf returns all the nodes of the subtree rooted at `n` in preorder, except `n` itself.
+func (p *preorder) descendantsOf(n *ast.Node) []*ast.Node {
+	i := p.index[n]
+	return p.nodes[i+1 : i+1+p.sizes[i]]
+}
+
+// sizeOf returns the number of descendants of `n`.
+func (p *preorder) sizeOf(n *ast.Node) int {
+	return p.sizes[p.index[n]]
+}
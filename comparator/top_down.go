@@ -1,19 +1,24 @@
 package comparator
 
 import (
+	"context"
 	"github.com/Xanonymous-GitHub/gumtree-go/ast"
 	. "github.com/Xanonymous-GitHub/gumtree-go/datastructures"
 	"github.com/samber/lo"
+	"sort"
 )
 
-func (c *comparator) topDown() {
-	tree1HashMemo := (*c.tree1).MakeHashMemo()
-	tree2HashMemo := (*c.tree2).MakeHashMemo()
+func (c *comparator) topDown(ctx context.Context) error {
+	c.hashTrees()
 
 	c.list1.Push((*c.tree1).Root())
 	c.list2.Push((*c.tree2).Root())
 
 	for {
+		if err := budgetErrorOf(ctx); err != nil {
+			return err
+		}
+
 		maxHeightOfL1 := c.list1.PeekMax()
 		maxHeightOfL2 := c.list2.PeekMax()
 
@@ -34,11 +39,9 @@ func (c *comparator) topDown() {
 				}
 			}
 		} else {
-			h1 := c.list1.Pop()
-			h2 := c.list2.Pop()
-
-			sameHeightNodePairs := CrossPairOf(h1.ToSlice(), h2.ToSlice())
-			mappings := NewIsomorphicMappings(tree1HashMemo, tree2HashMemo, sameHeightNodePairs)
+			h1 := c.inTreeOrder(c.list1.Pop().ToSlice(), c.order1)
+			h2 := c.inTreeOrder(c.list2.Pop().ToSlice(), c.order2)
+			mappings := GroupIsomorphic(c.hashMemo1, c.hashMemo2, h1, h2)
 
 			for _, uniqueIsomorphicMapping := range mappings.UniqueIsomorphicMappings() {
 				// The length of the left and right sets should be 1, since they are unique.
@@ -50,12 +53,9 @@ func (c *comparator) topDown() {
 
 			for _, nonUniqueIsomorphicMapping := range mappings.NonUniqueIsomorphicMappings() {
 				// Only add mappings that are isomorphic but not unique.
-				c.candidateMappings = append(
-					c.candidateMappings,
-					CrossPairOf(
-						nonUniqueIsomorphicMapping.Left().ToSlice(),
-						nonUniqueIsomorphicMapping.Right().ToSlice(),
-					)...,
+				c.addCandidates(
+					c.inTreeOrder(nonUniqueIsomorphicMapping.Left().ToSlice(), c.order1),
+					c.inTreeOrder(nonUniqueIsomorphicMapping.Right().ToSlice(), c.order2),
 				)
 			}
 
@@ -70,5 +70,25 @@ func (c *comparator) topDown() {
 		}
 	}
 
-	c.handleCandidateMappings()
+	return c.handleCandidateMappings(ctx)
+}
+
+// hashTrees computes the missing hash memos of both trees and numbers their nodes in preorder.
+func (c *comparator) hashTrees() {
+	if c.hashMemo1 == nil {
+		c.hashMemo1 = (*c.tree1).MakeHashMemo()
+	}
+	if c.hashMemo2 == nil {
+		c.hashMemo2 = (*c.tree2).MakeHashMemo()
+	}
+	c.order1 = preorderOf(*c.tree1)
+	c.order2 = preorderOf(*c.tree2)
+}
+
+// inTreeOrder sorts `nodes` by their index in `order` and returns them, so that the matching is deterministic.
+func (c *comparator) inTreeOrder(nodes []*ast.Node, order *preorder) []*ast.Node {
+	sort.Slice(nodes, func(i, j int) bool {
+		return order.index[nodes[i]] < order.index[nodes[j]]
+	})
+	return nodes
 }
@@ -0,0 +1,218 @@
+package diff
+
+import (
+	"context"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/cache"
+	"github.com/Xanonymous-GitHub/gumtree-go/changes"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"github.com/Xanonymous-GitHub/gumtree-go/transform"
+	"log/slog"
+	"os"
+)
+
+// Options configure how two files are compared.
+type Options struct {
+	// Registry picks the frontend used to parse each file.
+	Registry frontend.Registry
+
+	// Pipeline normalizes the trees before they are matched.
+	Pipeline transform.Pipeline
+
+	// Cache keeps the normalized trees of the parsed files, so that the same contents are not parsed again.
+	// It is disabled if nil.
+	Cache *cache.Store
+
+	// Config holds the parameters of the matching, e.g. MinHeight or Limits, see comparator.Config.
+	comparator.Config
+
+	Logger slog.Logger
+}
+
+// DefaultOptions returns the options parsing files with the default frontends,
+// without normalization, and matching them with the default parameters of GumTree on as many goroutines as CPUs.
+func DefaultOptions(logger slog.Logger) Options {
+	return Options{
+		Registry: frontend.NewDefaultRegistry(logger),
+		Pipeline: make(transform.Pipeline, 0),
+		Config:   comparator.DefaultConfig(),
+		Logger:   logger,
+	}
+}
+
+// Result is the structural difference between two versions of a source file.
+type Result struct {
+	// SrcPath and DstPath are the paths of the compared files. They may be empty.
+	SrcPath, DstPath string
+
+	// SrcContent and DstContent are the contents of the compared files.
+	// The positions of the nodes of Src and Dst refer to them.
+	SrcContent, DstContent []byte
+
+	Src, Dst ast.AST
+	Mappings comparator.MappingStore
+	Script   editscript.Script
+
+	// Degradations are the limits of the options reached by the matching, whose mappings may then be worse.
+	Degradations []comparator.Degradation
+}
+
+// Similarity returns the ratio of the nodes of `src` and `dst` that are mapped by `mappings`, from 0 to 1.
+// Two empty trees are identical.
+func Similarity(src, dst ast.AST, mappings comparator.MappingStore) float64 {
+	size := len(src.PreOrderNodes()) + len(dst.PreOrderNodes())
+	if size == 0 {
+		return 1
+	}
+	return float64(2*mappings.Size()) / float64(size)
+}
+
+// Similarity returns the ratio of the nodes of the compared trees that are mapped, see Similarity.
+func (r *Result) Similarity() float64 {
+	return Similarity(r.Src, r.Dst, r.Mappings)
+}
+
+// Changes groups the actions of the edit script into changes of the source code, see changes.Group.
+func (r *Result) Changes() changes.ChangeSet {
+	return changes.Group(r.Script)
+}
+
+// Trees matches `src` against `dst` and computes the edit script between them.
+// The trees are not normalized by the pipeline of the options.
+// It returns an error wrapping comparator.ErrInvalidConfig if the parameters of the matching are invalid.
+func Trees(src, dst ast.AST, opts Options) (*Result, error) {
+	return HashedTrees(src, dst, nil, nil, opts)
+}
+
+// HashedTrees compares the trees as Trees does, given their hash memos, see comparator.WithHashMemos.
+func HashedTrees(src, dst ast.AST, srcHashes, dstHashes ast.NodeHashMemo, opts Options) (*Result, error) {
+	return HashedTreesContext(context.Background(), src, dst, srcHashes, dstHashes, opts)
+}
+
+// HashedTreesContext compares the trees as HashedTrees does, and stops the matching when `ctx` is done.
+// The result is then computed from the mappings found so far, and returned with an error wrapping comparator.ErrBudgetExceeded:
+// its edit script is still valid, but may report most nodes as deleted and inserted,
+// so that callers may rather fall back to a textual difference.
+// If the parameters of the matching are invalid, only an error wrapping comparator.ErrInvalidConfig is returned.
+func HashedTreesContext(ctx context.Context, src, dst ast.AST, srcHashes, dstHashes ast.NodeHashMemo, opts Options) (*Result, error) {
+	c, err := comparator.New(&src, &dst, comparator.WithConfig(opts.Config), comparator.WithHashMemos(srcHashes, dstHashes), comparator.WithLogger(&opts.Logger))
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := c.CompareContext(ctx)
+	for _, degradation := range c.Degradations() {
+		opts.Logger.Warn("the matching reached a limit, some changes may be reported as deletions and insertions", "limit", degradation.Limit, "count", degradation.Count)
+	}
+	return &Result{
+		Src:          src,
+		Dst:          dst,
+		Mappings:     mappings,
+		Script:       editscript.Generate(src, dst, mappings),
+		Degradations: c.Degradations(),
+	}, err
+}
+
+// Contents parses and normalizes the two versions of a file, then compares them.
+// The paths are only used to pick the frontend and may be fake.
+// A nil content stands for a missing file, e.g. an added or removed one, and is compared as an empty tree.
+func Contents(srcPath string, srcContent []byte, dstPath string, dstContent []byte, opts Options) (*Result, error) {
+	return ContentsContext(context.Background(), srcPath, srcContent, dstPath, dstContent, opts)
+}
+
+// ContentsContext compares the contents as Contents does, and stops the matching when `ctx` is done,
+// returning then both a result and an error wrapping comparator.ErrBudgetExceeded, see HashedTreesContext.
+func ContentsContext(ctx context.Context, srcPath string, srcContent []byte, dstPath string, dstContent []byte, opts Options) (*Result, error) {
+	src, srcHashes, err := parseOrEmpty(srcPath, srcContent, opts)
+	if err != nil {
+		return nil, err
+	}
+	dst, dstHashes, err := parseOrEmpty(dstPath, dstContent, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := HashedTreesContext(ctx, src, dst, srcHashes, dstHashes, opts)
+	if result == nil {
+		return nil, err
+	}
+	result.SrcPath, result.DstPath = srcPath, dstPath
+	result.SrcContent, result.DstContent = srcContent, dstContent
+	return result, err
+}
+
+// Files reads the two files at the given paths and compares them.
+func Files(srcPath, dstPath string, opts Options) (*Result, error) {
+	srcContent, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	dstContent, err := os.ReadFile(dstPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return Contents(srcPath, srcContent, dstPath, dstContent, opts)
+}
+
+// Parse builds the normalized AST of the file at `path` with the content `content`.
+func Parse(path string, content []byte, opts Options) (ast.AST, error) {
+	if opts.Cache != nil {
+		tree, _, err := ParseHashed(path, content, opts)
+		return tree, err
+	}
+
+	tree, err := opts.Registry.Parse(path, content)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Pipeline.Apply(tree)
+}
+
+// ParseHashed parses and normalizes a file as Parse does, and also returns the hash memo of its tree.
+// With a cache, both are loaded from it if the same content has been parsed with the same pipeline before,
+// and saved into it otherwise. Failing to save them is only logged.
+func ParseHashed(path string, content []byte, opts Options) (ast.AST, ast.NodeHashMemo, error) {
+	var key cache.Key
+	if opts.Cache != nil {
+		key = cache.KeyOf(path, content, opts.Pipeline)
+		entry, ok, err := opts.Cache.Load(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			return entry.Tree, entry.Hashes, nil
+		}
+	}
+
+	tree, err := opts.Registry.Parse(path, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err = opts.Pipeline.Apply(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := tree.MakeHashMemo()
+
+	if opts.Cache != nil {
+		if err := opts.Cache.Save(key, cache.Entry{Tree: tree, Hashes: hashes}); err != nil {
+			opts.Logger.Warn("failed to cache a parsed file", "path", path, "error", err)
+		}
+	}
+	return tree, hashes, nil
+}
+
+// parseOrEmpty parses a file, or returns an empty tree for a nil content.
+// The hash memo of the tree is only returned if it comes for free, i.e. with a cache.
+func parseOrEmpty(path string, content []byte, opts Options) (ast.AST, ast.NodeHashMemo, error) {
+	if content == nil {
+		return ast.NewAST(opts.Logger), nil, nil
+	}
+	if opts.Cache != nil {
+		return ParseHashed(path, content, opts)
+	}
+	tree, err := Parse(path, content, opts)
+	return tree, nil, err
+}
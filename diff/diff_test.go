@@ -0,0 +1,101 @@
+package diff_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+// blockOf builds a block of statements `f(arg)`, one per argument.
+func blockOf(args ...string) ast.AST {
+	tree := ast.NewAST(*logger)
+	root, _ := tree.Add(nil, -1, "Block", "")
+	for i, arg := range args {
+		stmt, _ := tree.Add(root, i, "ExprStmt", "")
+		call, _ := tree.Add(stmt, 0, "CallExpr", "")
+		_, _ = tree.Add(call, 0, "Ident", "f")
+		_, _ = tree.Add(call, 1, "BasicLit", ast.NodeValueType(arg))
+	}
+	return tree
+}
+
+func TestTrees(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name                    string
+		src, dst                []string
+		updates, moves, inserts int
+	}{
+		{name: "Test identical trees", src: []string{"1", "2"}, dst: []string{"1", "2"}},
+		{name: "Test an updated value", src: []string{"1", "2"}, dst: []string{"1", "3"}, updates: 1},
+		{name: "Test reordered statements", src: []string{"1", "2", "3"}, dst: []string{"2", "1", "3"}, moves: 1},
+		{name: "Test an inserted statement", src: []string{"1"}, dst: []string{"1", "2"}, inserts: 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, dst := blockOf(tc.src...), blockOf(tc.dst...)
+			result, err := diff.Trees(src, dst, diff.DefaultOptions(*logger))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			script := result.Script
+			if script.CountOf(editscript.Update) != tc.updates || script.CountOf(editscript.Move) != tc.moves || script.CountOf(editscript.Insert) != tc.inserts {
+				t.Errorf("Expected %d updates, %d moves and %d inserts, got %v", tc.updates, tc.moves, tc.inserts, script)
+			}
+			if script.CountOf(editscript.Delete) != 0 {
+				t.Errorf("Expected no delete, got %v", script)
+			}
+			if err := editscript.Verify(src, dst, script, *logger); err != nil {
+				t.Errorf("Expected the script to turn the source tree into the destination tree, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Test the similarity of identical trees", func(t *testing.T) {
+		t.Parallel()
+
+		src, dst := blockOf("1", "2"), blockOf("1", "2")
+		result, err := diff.Trees(src, dst, diff.DefaultOptions(*logger))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if similarity := result.Similarity(); similarity != 1 {
+			t.Errorf("Expected a similarity of 1, got %v", similarity)
+		}
+	})
+
+	t.Run("Test comparing empty trees", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := diff.Trees(ast.NewAST(*logger), ast.NewAST(*logger), diff.DefaultOptions(*logger))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result.Script) != 0 || result.Similarity() != 1 {
+			t.Errorf("Expected no action and a similarity of 1, got %v and %v", result.Script, result.Similarity())
+		}
+	})
+
+	t.Run("Test an invalid config is returned as an error", func(t *testing.T) {
+		t.Parallel()
+
+		opts := diff.DefaultOptions(*logger)
+		opts.MinDice = 2
+		result, err := diff.Trees(blockOf("1"), blockOf("2"), opts)
+		if !errors.Is(err, comparator.ErrInvalidConfig) || result != nil {
+			t.Errorf("Expected only an invalid config error, got %v and %v", result, err)
+		}
+	})
+}
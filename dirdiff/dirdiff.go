@@ -0,0 +1,215 @@
+package dirdiff
+
+import (
+	"bytes"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultRenameThreshold is the default minimum similarity of a removed and an added file to be reported as a rename.
+const DefaultRenameThreshold = 0.5
+
+// Status tells how a file changed between the two compared directories.
+type Status string
+
+const (
+	Added    Status = "added"
+	Removed  Status = "removed"
+	Renamed  Status = "renamed"
+	Modified Status = "modified"
+)
+
+// FilePair is a changed file of two compared directories.
+// SrcPath is empty for an added file, and DstPath is empty for a removed one.
+// The paths returned by Pair include the directory they belong to.
+type FilePair struct {
+	SrcPath, DstPath string
+	Status           Status
+
+	// Similarity is the ratio of the nodes of a renamed file that are mapped, see diff.Similarity.
+	// It is 1 for files renamed without any structural change.
+	Similarity float64
+}
+
+// Options configure how two directories are compared.
+type Options struct {
+	// Diff are the options comparing a pair of files.
+	Diff diff.Options
+
+	// RenameThreshold is the minimum similarity of a removed and an added file to be paired as a rename.
+	// Renames are not detected if it is greater than 1.
+	RenameThreshold float64
+}
+
+// DefaultOptions returns the options comparing files with diff.DefaultOptions and the default rename threshold.
+func DefaultOptions(logger slog.Logger) Options {
+	return Options{
+		Diff:            diff.DefaultOptions(logger),
+		RenameThreshold: DefaultRenameThreshold,
+	}
+}
+
+// Pair returns the changed files between `srcDir` and `dstDir`, sorted by path.
+// Files are first paired by their path relative to the directories, and identical files are left out.
+// The remaining removed and added files are then paired as renames:
+// first the ones whose trees have the same root hash, i.e., files moved without any structural change,
+// then greedily the most similar ones above the rename threshold.
+// Hidden files and directories, e.g. `.git`, are skipped.
+func Pair(srcDir, dstDir string, opts Options) ([]FilePair, error) {
+	if err := opts.Diff.Validate(); err != nil {
+		return nil, err
+	}
+
+	srcFiles, err := relativeFilesOf(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	dstFiles, err := relativeFilesOf(dstDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]FilePair, 0)
+	removed := make([]string, 0)
+	for _, name := range srcFiles {
+		if !contains(dstFiles, name) {
+			removed = append(removed, filepath.Join(srcDir, name))
+			continue
+		}
+
+		srcPath, dstPath := filepath.Join(srcDir, name), filepath.Join(dstDir, name)
+		same, err := sameContent(srcPath, dstPath)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			pairs = append(pairs, FilePair{SrcPath: srcPath, DstPath: dstPath, Status: Modified})
+		}
+	}
+
+	added := make([]string, 0)
+	for _, name := range dstFiles {
+		if !contains(srcFiles, name) {
+			added = append(added, filepath.Join(dstDir, name))
+		}
+	}
+
+	renames, err := newRenameDetector(opts).detect(removed, added)
+	if err != nil {
+		return nil, err
+	}
+	pairs = append(pairs, renames...)
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pathOf(pairs[i], srcDir, dstDir) < pathOf(pairs[j], srcDir, dstDir)
+	})
+	return pairs, nil
+}
+
+// FileDiff is the structural diff of a changed file.
+type FileDiff struct {
+	FilePair
+
+	// Result is the diff of the file, where a missing side is an empty tree. It is nil if Err is set.
+	Result *diff.Result
+
+	// Err is the error that prevented the comparison of the file, e.g. frontend.ErrUnsupportedFile for binary files.
+	Err error
+}
+
+// Compare pairs the changed files between `srcDir` and `dstDir` as Pair does, and compares each pair.
+// Files that cannot be compared are reported with their error rather than failing the whole comparison.
+func Compare(srcDir, dstDir string, opts Options) ([]FileDiff, error) {
+	pairs, err := Pair(srcDir, dstDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]FileDiff, 0, len(pairs))
+	for _, pair := range pairs {
+		result, err := CompareFiles(pair, opts.Diff)
+		diffs = append(diffs, FileDiff{FilePair: pair, Result: result, Err: err})
+	}
+	return diffs, nil
+}
+
+// CompareFiles compares the files of `pair`, a missing side being compared as an empty tree.
+func CompareFiles(pair FilePair, opts diff.Options) (*diff.Result, error) {
+	srcContent, err := readIfPresent(pair.SrcPath)
+	if err != nil {
+		return nil, err
+	}
+	dstContent, err := readIfPresent(pair.DstPath)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Contents(pair.SrcPath, srcContent, pair.DstPath, dstContent, opts)
+}
+
+// pathOf returns the path of `pair` relative to its directory, used to sort the pairs.
+func pathOf(pair FilePair, srcDir, dstDir string) string {
+	if pair.DstPath != "" {
+		rel, _ := filepath.Rel(dstDir, pair.DstPath)
+		return rel
+	}
+	rel, _ := filepath.Rel(srcDir, pair.SrcPath)
+	return rel
+}
+
+// relativeFilesOf returns the sorted paths of the regular files under `dir`, relative to it.
+func relativeFilesOf(dir string) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func contains(sorted []string, name string) bool {
+	i := sort.SearchStrings(sorted, name)
+	return i < len(sorted) && sorted[i] == name
+}
+
+func sameContent(a, b string) (bool, error) {
+	aContent, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+	bContent, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aContent, bContent), nil
+}
+
+// readIfPresent reads the file at `path`, or returns nil if `path` is empty.
+func readIfPresent(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,151 @@
+package dirdiff_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const manifest = `{"name": "gumtree", "version": 3, "tags": ["diff", "ast"], "deps": {"a": 1, "b": 2}}`
+
+const manifestEdited = `{"name": "gumtree", "version": 4, "tags": ["diff", "ast"], "deps": {"a": 1, "b": 2}}`
+
+func testOptions() dirdiff.Options {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	return dirdiff.DefaultOptions(*logger)
+}
+
+func TestPair(t *testing.T) {
+	t.Parallel()
+
+	src := writeFiles(t, map[string]string{
+		"same.txt":          "unchanged\n",
+		"edited.txt":        "before\n",
+		"removed.txt":       "gone\n",
+		"moved/data.bin":    "\x00\x01\x02",
+		"config.json":       `{"a": 1, "b": [true, null]}`,
+		"meta/package.json": manifest,
+		".git/HEAD":         "ref: refs/heads/main\n",
+		"unrelated/x.json":  `{"name": "x"}`,
+	})
+	dst := writeFiles(t, map[string]string{
+		"same.txt":      "unchanged\n",
+		"edited.txt":    "after\n",
+		"added.txt":     "a new file\nwith several lines,\n  some of them (nested)\n",
+		"data.bin":      "\x00\x01\x02",
+		"settings.json": "{\n  \"a\": 1,\n  \"b\": [true, null]\n}\n",
+		"package.json":  manifestEdited,
+		".git/HEAD":     "ref: refs/heads/other\n",
+		"other/y.go":    "package other\n",
+	})
+
+	pairs, err := dirdiff.Pair(src, dst, testOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	byStatus := make(map[dirdiff.Status][]dirdiff.FilePair)
+	for _, pair := range pairs {
+		byStatus[pair.Status] = append(byStatus[pair.Status], pair)
+	}
+	rel := func(dir, path string) string {
+		if path == "" {
+			return ""
+		}
+		name, _ := filepath.Rel(dir, path)
+		return filepath.ToSlash(name)
+	}
+
+	t.Run("Test modified files", func(t *testing.T) {
+		modified := byStatus[dirdiff.Modified]
+		if len(modified) != 1 || rel(src, modified[0].SrcPath) != "edited.txt" {
+			t.Errorf("Expected only edited.txt to be modified, got %+v", modified)
+		}
+	})
+
+	t.Run("Test renamed files", func(t *testing.T) {
+		expected := map[string]string{
+			"moved/data.bin":    "data.bin",
+			"config.json":       "settings.json",
+			"meta/package.json": "package.json",
+		}
+		renamed := byStatus[dirdiff.Renamed]
+		if len(renamed) != len(expected) {
+			t.Fatalf("Expected %d renames, got %+v", len(expected), renamed)
+		}
+		for _, pair := range renamed {
+			if expected[rel(src, pair.SrcPath)] != rel(dst, pair.DstPath) {
+				t.Errorf("Expected %s to be renamed to %s, got %s", rel(src, pair.SrcPath), expected[rel(src, pair.SrcPath)], rel(dst, pair.DstPath))
+			}
+			if pair.Similarity < dirdiff.DefaultRenameThreshold || pair.Similarity > 1 {
+				t.Errorf("Expected %s to be similar to %s, got a similarity of %v", rel(src, pair.SrcPath), rel(dst, pair.DstPath), pair.Similarity)
+			}
+		}
+	})
+
+	t.Run("Test added and removed files", func(t *testing.T) {
+		added, removed := byStatus[dirdiff.Added], byStatus[dirdiff.Removed]
+		if len(added) != 2 || rel(dst, added[0].DstPath) != "added.txt" || rel(dst, added[1].DstPath) != "other/y.go" {
+			t.Errorf("Expected added.txt and other/y.go to be added, got %+v", added)
+		}
+		if len(removed) != 2 || rel(src, removed[0].SrcPath) != "removed.txt" || rel(src, removed[1].SrcPath) != "unrelated/x.json" {
+			t.Errorf("Expected removed.txt and unrelated/x.json to be removed, got %+v", removed)
+		}
+	})
+
+	t.Run("Test rename detection can be disabled", func(t *testing.T) {
+		opts := testOptions()
+		opts.RenameThreshold = 1.1
+		pairs, err := dirdiff.Pair(src, dst, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		renames := 0
+		for _, pair := range pairs {
+			if pair.Status == dirdiff.Renamed {
+				renames++
+			}
+		}
+		// Only the moved binary file and the reformatted configuration remain paired, as they are identical.
+		if renames != 2 {
+			t.Errorf("Expected only the 2 identical files to be renamed, got %d renames", renames)
+		}
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	src := writeFiles(t, map[string]string{"a.json": `[1, 2]`, "b.bin": "\x00"})
+	dst := writeFiles(t, map[string]string{"a.json": `[1, 3]`, "b.bin": "\x01"})
+
+	diffs, err := dirdiff.Compare(src, dst, testOptions())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 changed files, got %d", len(diffs))
+	}
+	if diffs[0].Err != nil || diffs[0].Result == nil || len(diffs[0].Result.Script) == 0 {
+		t.Errorf("Expected the diff of a.json, got %+v", diffs[0])
+	}
+	if diffs[1].Err == nil {
+		t.Errorf("Expected binary files not to be compared")
+	}
+}
@@ -0,0 +1,168 @@
+package dirdiff
+
+import (
+	"bytes"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// candidate is a removed or an added file that may be part of a rename.
+type candidate struct {
+	path    string
+	content []byte
+
+	// tree is the AST of the file, or nil if it cannot be parsed, e.g. because it is binary.
+	// Such files are only paired when their contents are equal.
+	tree     ast.AST
+	rootHash uint64
+	size     int
+
+	paired bool
+}
+
+// renameDetector pairs removed and added files as renames.
+type renameDetector struct {
+	opts Options
+}
+
+func (d *renameDetector) detect(removed, added []string) ([]FilePair, error) {
+	srcs, err := d.candidatesOf(removed)
+	if err != nil {
+		return nil, err
+	}
+	dsts, err := d.candidatesOf(added)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]FilePair, 0)
+	pairs = append(pairs, d.pairIdentical(srcs, dsts)...)
+	if d.opts.RenameThreshold <= 1 {
+		similar, err := d.pairSimilar(srcs, dsts)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, similar...)
+	}
+
+	for _, src := range srcs {
+		if !src.paired {
+			pairs = append(pairs, FilePair{SrcPath: src.path, Status: Removed})
+		}
+	}
+	for _, dst := range dsts {
+		if !dst.paired {
+			pairs = append(pairs, FilePair{DstPath: dst.path, Status: Added})
+		}
+	}
+	return pairs, nil
+}
+
+func (d *renameDetector) candidatesOf(paths []string) ([]*candidate, error) {
+	candidates := make([]*candidate, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		c := &candidate{path: path, content: content}
+		if tree, err := diff.Parse(path, content, d.opts.Diff); err == nil {
+			c.tree = tree
+			c.rootHash = ast.RootHash(tree)
+			c.size = len(tree.PreOrderNodes())
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// pairIdentical pairs the files with equal contents or, if they are parsed, with equal root hashes.
+func (d *renameDetector) pairIdentical(srcs, dsts []*candidate) []FilePair {
+	pairs := make([]FilePair, 0)
+	for _, src := range srcs {
+		for _, dst := range dsts {
+			if dst.paired || !identical(src, dst) {
+				continue
+			}
+
+			src.paired, dst.paired = true, true
+			pairs = append(pairs, FilePair{SrcPath: src.path, DstPath: dst.path, Status: Renamed, Similarity: 1})
+			break
+		}
+	}
+	return pairs
+}
+
+func identical(src, dst *candidate) bool {
+	if bytes.Equal(src.content, dst.content) {
+		return true
+	}
+	return src.tree != nil && dst.tree != nil && src.rootHash == dst.rootHash
+}
+
+// pairSimilar greedily pairs the most similar parsed files with the same extension,
+// as long as their similarity reaches the rename threshold.
+func (d *renameDetector) pairSimilar(srcs, dsts []*candidate) ([]FilePair, error) {
+	type scoredPair struct {
+		src, dst   *candidate
+		similarity float64
+	}
+
+	scored := make([]scoredPair, 0)
+	for _, src := range srcs {
+		for _, dst := range dsts {
+			if src.paired || dst.paired || src.tree == nil || dst.tree == nil {
+				continue
+			}
+			if filepath.Ext(src.path) != filepath.Ext(dst.path) {
+				continue
+			}
+			// The similarity cannot exceed the one of the smallest tree being entirely mapped.
+			if float64(2*min(src.size, dst.size))/float64(src.size+dst.size) < d.opts.RenameThreshold {
+				continue
+			}
+
+			similarity, err := d.similarityOf(src, dst)
+			if err != nil {
+				return nil, err
+			}
+			if similarity >= d.opts.RenameThreshold {
+				scored = append(scored, scoredPair{src: src, dst: dst, similarity: similarity})
+			}
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].similarity > scored[j].similarity
+	})
+
+	pairs := make([]FilePair, 0)
+	for _, p := range scored {
+		if p.src.paired || p.dst.paired {
+			continue
+		}
+		p.src.paired, p.dst.paired = true, true
+		pairs = append(pairs, FilePair{SrcPath: p.src.path, DstPath: p.dst.path, Status: Renamed, Similarity: p.similarity})
+	}
+	return pairs, nil
+}
+
+// similarityOf matches the trees of `src` and `dst` with the same parameters as the diff of the files, e.g. its limits,
+// and returns their similarity.
+func (d *renameDetector) similarityOf(src, dst *candidate) (float64, error) {
+	opts := d.opts.Diff
+	c, err := comparator.New(&src.tree, &dst.tree, comparator.WithConfig(opts.Config), comparator.WithLogger(&opts.Logger))
+	if err != nil {
+		return 0, err
+	}
+	return diff.Similarity(src.tree, dst.tree, c.Compare()), nil
+}
+
+func newRenameDetector(opts Options) *renameDetector {
+	return &renameDetector{opts: opts}
+}
@@ -0,0 +1,117 @@
+package editscript
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+)
+
+type ActionType int
+
+const (
+	// Insert adds a new leaf node to the tree.
+	Insert ActionType = iota
+
+	// Delete removes a leaf node from the tree.
+	Delete
+
+	// Update changes the label and the value of a node.
+	Update
+
+	// Move moves a whole subtree under a new parent.
+	Move
+
+	// TreeInsert adds a copy of a whole subtree to the tree. It is only found in simplified scripts, see Simplify.
+	TreeInsert
+
+	// TreeDelete removes a whole subtree from the tree. It is only found in simplified scripts, see Simplify.
+	TreeDelete
+)
+
+func (t ActionType) String() string {
+	switch t {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	case Move:
+		return "move"
+	case TreeInsert:
+		return "insert-tree"
+	case TreeDelete:
+		return "delete-tree"
+	default:
+		return fmt.Sprintf("ActionType(%d)", int(t))
+	}
+}
+
+// Action is one edit operation of an edit script.
+type Action struct {
+	Type ActionType
+
+	// Node is the node affected by the action, or the root of the affected subtree for TreeInsert and TreeDelete.
+	// It is a node of the destination tree for Insert and TreeInsert, and a node of the source tree otherwise.
+	Node *ast.Node
+
+	// Parent is the new parent of Node for Insert, TreeInsert and Move.
+	// It is either a node of the source tree, or a node of the destination tree inserted by a previous action of the script.
+	// A nil Parent makes Node the new root of the tree.
+	Parent *ast.Node
+
+	// Pos is the index of Node among the children of Parent for Insert, TreeInsert and Move,
+	// once the previous actions of the script have been applied.
+	// For Move, it is an index among the other children of Parent, i.e. once Node has been removed from its old place,
+	// so that moving the first of the children `x y z` after `y` has the position 1.
+	Pos int
+
+	// Label and Value are the new label and value of Node for Update.
+	Label ast.NodeLabelType
+	Value ast.NodeValueType
+}
+
+func (a *Action) String() string {
+	switch a.Type {
+	case Insert, TreeInsert, Move:
+		return fmt.Sprintf("%s %s to %s at %d", a.Type, DescribeNode(a.Node), DescribeNode(a.Parent), a.Pos)
+	case Update:
+		return fmt.Sprintf("%s %s to %s: %s", a.Type, DescribeNode(a.Node), a.Label, a.Value)
+	default:
+		return fmt.Sprintf("%s %s", a.Type, DescribeNode(a.Node))
+	}
+}
+
+// DescribeNode describes `n` by its label, its value and the range of its position, e.g. `Ident: main [5,9)`.
+// A nil node, e.g. the parent of an inserted root, is described as `<root>`.
+func DescribeNode(n *ast.Node) string {
+	if n == nil {
+		return "<root>"
+	}
+	return DescribeNamedNode(n, string(n.Value))
+}
+
+// DescribeNamedNode describes `n` as DescribeNode does, with `name` in place of its value, e.g. the names it declares.
+func DescribeNamedNode(n *ast.Node, name string) string {
+	description := string(n.Label)
+	if name != "" {
+		description += ": " + name
+	}
+	if n.Pos.IsValid() {
+		description += fmt.Sprintf(" [%d,%d)", n.Pos.Start, n.Pos.End)
+	}
+	return description
+}
+
+// Script is a sequence of actions turning a source tree into a destination tree.
+type Script []Action
+
+// CountOf returns the number of actions of the given type in the script.
+func (s Script) CountOf(t ActionType) int {
+	count := 0
+	for _, action := range s {
+		if action.Type == t {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,117 @@
+package editscript
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"log/slog"
+	"slices"
+)
+
+// Invert returns the script turning the destination tree back into the source tree `src`,
+// given the script turning `src` into the destination tree and the mappings it was generated from.
+//
+// The inverse is built action by action, in reverse order: inserts become deletes, deletes become inserts,
+// and updates and moves restore the previous label, value, parent and position of their node.
+// Following the conventions of Action, the nodes kept by `script` are referred to by their partner in the destination tree,
+// and the nodes deleted by `script` are inserted back as themselves.
+// The nodes deleted by Delete actions must be leaves when they are deleted, as in the scripts made by Generate,
+// while TreeDelete actions are inverted into TreeInsert actions of the deleted subtrees, as in the scripts made by Simplify.
+func Invert(src ast.AST, script Script, mappings comparator.MappingStore, logger slog.Logger) (Script, error) {
+	working, copies := ast.Copy(src, logger)
+	originals := make(map[*ast.Node]*ast.Node, len(copies))
+	for original, c := range copies {
+		originals[c] = original
+	}
+
+	p, err := newPatcher(working)
+	if err != nil {
+		return nil, err
+	}
+
+	// originalOf returns the node of the scripts a node of the working tree stands for.
+	originalOf := func(w *ast.Node) *ast.Node {
+		if w == p.fakeRoot {
+			return nil
+		}
+		if original, ok := originals[w]; ok {
+			return original
+		}
+		return w
+	}
+
+	inverse := make(Script, 0, len(script))
+	for i, action := range script.Translate(copies) {
+		n := p.nodeOf(action.Node)
+		switch action.Type {
+		case Insert:
+			inverse = append(inverse, Action{Type: Delete, Node: script[i].Node})
+		case TreeInsert:
+			inverse = append(inverse, Action{Type: TreeDelete, Node: script[i].Node})
+		case TreeDelete:
+			inverse = append(inverse, Action{Type: TreeInsert, Node: script[i].Node, Parent: originalOf(n.Parent), Pos: positionOf(n)})
+		case Delete:
+			if n.Degree() > 0 {
+				return nil, fmt.Errorf("action %d (%s): the deleted node has %d children", i, script[i].String(), n.Degree())
+			}
+			inverse = append(inverse, Action{Type: Insert, Node: script[i].Node, Parent: originalOf(n.Parent), Pos: positionOf(n)})
+		case Update:
+			inverse = append(inverse, Action{Type: Update, Node: script[i].Node, Label: n.Label, Value: n.Value})
+		case Move:
+			inverse = append(inverse, Action{Type: Move, Node: script[i].Node, Parent: originalOf(n.Parent), Pos: positionOf(n)})
+		}
+
+		if err := p.apply(&action); err != nil {
+			return nil, fmt.Errorf("action %d (%s): %w", i, script[i].String(), err)
+		}
+		// Nodes created by inserts stand for the destination nodes they were inserted for.
+		switch action.Type {
+		case Insert:
+			originals[p.nodeOf(action.Node)] = script[i].Node
+		case TreeInsert:
+			for _, n := range preOrderOf(script[i].Node) {
+				originals[p.nodeOf(n)] = n
+			}
+		}
+	}
+
+	slices.Reverse(inverse)
+	partnerOf := func(n *ast.Node) *ast.Node {
+		if partner := mappings.DstOf(n); partner != nil {
+			return partner
+		}
+		return n
+	}
+	for i := range inverse {
+		if inverse[i].Type != Insert && inverse[i].Type != TreeInsert {
+			inverse[i].Node = partnerOf(inverse[i].Node)
+		}
+		if inverse[i].Parent != nil {
+			inverse[i].Parent = partnerOf(inverse[i].Parent)
+		}
+	}
+	return inverse, nil
+}
+
+// Compose returns the script turning a tree A into a tree C,
+// given the script `first` turning A into B with the mappings `firstMappings` it was generated from,
+// and the script `second` turning B into C.
+//
+// The composed script is the first script followed by the second one, where the nodes of B kept by the first script
+// are referred to by their partner in A. It is valid but not minimal, e.g. a node inserted by the first script
+// and deleted by the second one is kept in both; Generate on A and C gives a minimal script if needed.
+func Compose(first Script, firstMappings comparator.MappingStore, second Script) Script {
+	partners := make(map[*ast.Node]*ast.Node)
+	for _, pair := range firstMappings.Pairs() {
+		partners[pair.Right()] = pair.Left()
+	}
+
+	composed := make(Script, 0, len(first)+len(second))
+	composed = append(composed, first...)
+	return append(composed, second.Translate(partners)...)
+}
+
+// positionOf returns the index of `n` among the children of its parent.
+func positionOf(n *ast.Node) int {
+	return slices.Index(n.Parent.OrderedChildren(), n)
+}
@@ -0,0 +1,132 @@
+package editscript_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestInvert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test the inverse turns the destination tree back into the source tree", func(t *testing.T) {
+		src := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "a", "leaf", "a").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c").
+			Add("r", "d", "block", "").
+			Add("d", "x", "leaf", "x")
+		dst := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c2").
+			Add("b", "a", "leaf", "a").
+			Add("r", "e", "leaf", "e")
+		mappings := mappingsOf(src, dst, "r", "a", "b", "c")
+		script := editscript.Generate(src.Tree, dst.Tree, mappings)
+
+		inverse, err := editscript.Invert(src.Tree, script, mappings, slog.Logger{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(inverse) != len(script) {
+			t.Errorf("Expected %d actions, got %d", len(script), len(inverse))
+		}
+		if err := editscript.Verify(dst.Tree, src.Tree, inverse, slog.Logger{}); err != nil {
+			t.Errorf("Expected the inverse to be verified, got %v", err)
+		}
+	})
+
+	t.Run("Test inverting a root replacement", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "old", "").Add("r", "a", "leaf", "a").Add("r", "b", "leaf", "b")
+		dst := asttest.NewBuilder().Add("", "r", "new", "").Add("r", "b", "leaf", "b").Add("r", "c", "leaf", "c")
+		mappings := mappingsOf(src, dst, "b")
+		script := editscript.Generate(src.Tree, dst.Tree, mappings)
+
+		inverse, err := editscript.Invert(src.Tree, script, mappings, slog.Logger{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := editscript.Verify(dst.Tree, src.Tree, inverse, slog.Logger{}); err != nil {
+			t.Errorf("Expected the inverse to be verified, got %v", err)
+		}
+	})
+}
+
+func TestCompose(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test composing scripts of three trees", func(t *testing.T) {
+		a := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "x", "leaf", "x").
+			Add("r", "y", "leaf", "y")
+		b := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "y", "leaf", "y").
+			Add("r", "blk", "block", "").
+			Add("blk", "x", "leaf", "x2")
+		c := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "blk", "block", "").
+			Add("blk", "x", "leaf", "x3").
+			Add("blk", "z", "leaf", "z")
+
+		abMappings := mappingsOf(a, b, "r", "x", "y")
+		ab := editscript.Generate(a.Tree, b.Tree, abMappings)
+		bc := editscript.Generate(b.Tree, c.Tree, mappingsOf(b, c, "r", "blk", "x"))
+
+		composed := editscript.Compose(ab, abMappings, bc)
+		if len(composed) != len(ab)+len(bc) {
+			t.Errorf("Expected %d actions, got %d", len(ab)+len(bc), len(composed))
+		}
+		if err := editscript.Verify(a.Tree, c.Tree, composed, slog.Logger{}); err != nil {
+			t.Errorf("Expected the composed script to be verified, got %v", err)
+		}
+	})
+
+	t.Run("Test chaining the diffs of three revisions", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		opts := diff.DefaultOptions(*logger)
+		revisions := []string{
+			"package p\n\nfunc a(x int) int {\n\treturn x\n}\n",
+			"package p\n\nfunc a(x int) int {\n\tif x < 0 {\n\t\treturn -x\n\t}\n\treturn x\n}\n\nfunc b() {}\n",
+			"package p\n\nfunc b() {}\n\nfunc abs(x int) int {\n\tif x < 0 {\n\t\tx = -x\n\t}\n\treturn x\n}\n",
+		}
+
+		trees := make([]ast.AST, 0, len(revisions))
+		for _, revision := range revisions {
+			tree, err := diff.Parse("a.go", []byte(revision), opts)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			trees = append(trees, tree)
+		}
+		first, err := diff.Trees(trees[0], trees[1], opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		second, err := diff.Trees(trees[1], trees[2], opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		composed := editscript.Compose(first.Script, first.Mappings, second.Script)
+		if err := editscript.Verify(trees[0], trees[2], composed, *logger); err != nil {
+			t.Errorf("Expected the composed script to be verified, got %v", err)
+		}
+
+		inverse, err := editscript.Invert(trees[1], second.Script, second.Mappings, *logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := editscript.Verify(trees[2], trees[1], inverse, *logger); err != nil {
+			t.Errorf("Expected the inverse to be verified, got %v", err)
+		}
+	})
+}
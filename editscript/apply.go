@@ -0,0 +1,208 @@
+package editscript
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+)
+
+// ErrVerificationFailed is returned by Verify when the replayed script does not turn the source tree into the destination tree.
+var ErrVerificationFailed = errors.New("the patched tree is not isomorphic to the destination tree")
+
+// Apply executes the actions of `script` on `tree`, which must be the source tree the script was generated from,
+// through the operations of ast.AST. The tree is modified in place and its nodes keep their identity,
+// while inserted nodes are new nodes with the label and the value of the corresponding destination nodes.
+//
+// As in the script generation, the actions with a nil Parent are applied under a temporary root,
+// which is removed once the script has been applied, leaving its only child as the root of the tree.
+func Apply(tree ast.AST, script Script) error {
+	p, err := newPatcher(tree)
+	if err != nil {
+		return err
+	}
+
+	for i := range script {
+		if err := p.apply(&script[i]); err != nil {
+			return fmt.Errorf("action %d (%s): %w", i, script[i].String(), err)
+		}
+	}
+	return p.finish()
+}
+
+// Verify replays `script` on a copy of `src` and checks that the result is isomorphic to `dst`,
+// i.e., that their roots have the same hash. It returns ErrVerificationFailed if they differ.
+// The trees are left untouched.
+func Verify(src, dst ast.AST, script Script, logger slog.Logger) error {
+	patched, copies := ast.Copy(src, logger)
+	if err := Apply(patched, script.Translate(copies)); err != nil {
+		return err
+	}
+
+	patchedHash, dstHash := ast.RootHash(patched), ast.RootHash(dst)
+	if patchedHash != dstHash {
+		return fmt.Errorf("%w: root hashes %x and %x", ErrVerificationFailed, patchedHash, dstHash)
+	}
+	return nil
+}
+
+// Translate returns a copy of the script where the nodes in `nodes` are replaced by their values,
+// e.g. to apply the script on a copy of its source tree made by ast.Copy.
+func (s Script) Translate(nodes map[*ast.Node]*ast.Node) Script {
+	translate := func(n *ast.Node) *ast.Node {
+		if t, ok := nodes[n]; ok {
+			return t
+		}
+		return n
+	}
+
+	result := make(Script, 0, len(s))
+	for _, action := range s {
+		action.Node = translate(action.Node)
+		action.Parent = translate(action.Parent)
+		result = append(result, action)
+	}
+	return result
+}
+
+// patcher holds the state of the application of a script.
+type patcher struct {
+	tree     ast.AST
+	fakeRoot *ast.Node
+
+	// inserted maps the destination nodes inserted by the script to the nodes created in the tree.
+	inserted map[*ast.Node]*ast.Node
+}
+
+// nodeOf returns the node of the tree an action refers to.
+func (p *patcher) nodeOf(n *ast.Node) *ast.Node {
+	if n == nil {
+		return p.fakeRoot
+	}
+	if created, ok := p.inserted[n]; ok {
+		return created
+	}
+	return n
+}
+
+func (p *patcher) apply(action *Action) error {
+	switch action.Type {
+	case Insert:
+		parent := p.nodeOf(action.Parent)
+		idx, err := p.freeIndexAt(parent, action.Pos, nil)
+		if err != nil {
+			return err
+		}
+		created, err := p.tree.Add(parent, idx, action.Node.Label, action.Node.Value)
+		if err != nil {
+			return err
+		}
+		created.Pos = action.Node.Pos
+		p.inserted[action.Node] = created
+		return nil
+	case TreeInsert:
+		parent := p.nodeOf(action.Parent)
+		idx, err := p.freeIndexAt(parent, action.Pos, nil)
+		if err != nil {
+			return err
+		}
+		return p.insertSubtree(parent, idx, action.Node)
+	case Delete, TreeDelete:
+		return p.tree.Delete(p.nodeOf(action.Node))
+	case Update:
+		n := p.nodeOf(action.Node)
+		if err := p.tree.UpdateLabel(n, action.Label); err != nil {
+			return err
+		}
+		return p.tree.UpdateValue(n, action.Value)
+	case Move:
+		n, parent := p.nodeOf(action.Node), p.nodeOf(action.Parent)
+		idx, err := p.freeIndexAt(parent, action.Pos, n)
+		if err != nil {
+			return err
+		}
+		return p.tree.Move(n, parent, idx)
+	default:
+		return fmt.Errorf("unknown action type %v", action.Type)
+	}
+}
+
+// insertSubtree adds a copy of the subtree of the destination node `n` under `parent` at the index `idx`.
+func (p *patcher) insertSubtree(parent *ast.Node, idx int, n *ast.Node) error {
+	created, err := p.tree.Add(parent, idx, n.Label, n.Value)
+	if err != nil {
+		return err
+	}
+	created.Pos = n.Pos
+	p.inserted[n] = created
+
+	for i, child := range n.OrderedChildren() {
+		if err := p.insertSubtree(created, i, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish removes the temporary root, leaving its only child as the root of the tree.
+func (p *patcher) finish() error {
+	switch p.fakeRoot.Degree() {
+	case 0:
+	case 1:
+		if err := p.tree.Move(p.fakeRoot.OrderedChildren()[0], nil, -1); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("the script leaves %d root nodes", p.fakeRoot.Degree())
+	}
+	return p.tree.Delete(p.fakeRoot)
+}
+
+// freeIndexAt returns a free index of the children map of `parent`
+// placing a new child at the position `pos` among the children other than `excluded`.
+// As the children are keyed by index, the following children may be re-indexed to make room for the new one.
+func (p *patcher) freeIndexAt(parent *ast.Node, pos int, excluded *ast.Node) (int, error) {
+	children := parent.OrderedChildren()
+	others := make([]*ast.Node, 0, len(children))
+	maxIdx := -1
+	for _, child := range children {
+		maxIdx = max(maxIdx, child.IdxToParent())
+		if child != excluded {
+			others = append(others, child)
+		}
+	}
+
+	pos = min(max(pos, 0), len(others))
+	if pos == len(others) {
+		return maxIdx + 1, nil
+	}
+
+	// First move every child beyond the largest index, so that the final indices are all free.
+	for i, child := range children {
+		if err := p.tree.Move(child, parent, maxIdx+1+i); err != nil {
+			return 0, err
+		}
+	}
+	for i, child := range others {
+		idx := i
+		if i >= pos {
+			idx++
+		}
+		if child.IdxToParent() == idx {
+			continue
+		}
+		if err := p.tree.Move(child, parent, idx); err != nil {
+			return 0, err
+		}
+	}
+	return pos, nil
+}
+
+// newPatcher creates a patcher for `tree`, adding the temporary root above its root.
+func newPatcher(tree ast.AST) (*patcher, error) {
+	fakeRoot, err := tree.Add(nil, -1, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return &patcher{tree: tree, fakeRoot: fakeRoot, inserted: make(map[*ast.Node]*ast.Node)}, nil
+}
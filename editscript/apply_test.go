@@ -0,0 +1,173 @@
+package editscript_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	newTrees := func() (*asttest.Builder, *asttest.Builder) {
+		src := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "a", "leaf", "a").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c").
+			Add("r", "d", "leaf", "d")
+		dst := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c2").
+			Add("b", "a", "leaf", "a").
+			Add("r", "e", "leaf", "e").
+			Add("r", "f", "block", "").
+			Add("f", "g", "leaf", "g")
+		return src, dst
+	}
+
+	t.Run("Test the patched tree is isomorphic to the destination tree", func(t *testing.T) {
+		src, dst := newTrees()
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a", "b", "c"))
+
+		if err := editscript.Apply(src.Tree, script); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if ast.RootHash(src.Tree) != ast.RootHash(dst.Tree) {
+			t.Errorf("Expected the patched tree to be isomorphic to the destination tree")
+		}
+		if src.Tree.Root() != src.Nodes["r"] || src.Nodes["a"].Parent != src.Nodes["b"] {
+			t.Errorf("Expected the mapped nodes to keep their identity")
+		}
+		if len(src.Tree.PreOrderNodes()) != len(dst.Tree.PreOrderNodes()) {
+			t.Errorf("Expected %d nodes, got %d", len(dst.Tree.PreOrderNodes()), len(src.Tree.PreOrderNodes()))
+		}
+	})
+
+	t.Run("Test the verification leaves the source tree untouched", func(t *testing.T) {
+		src, dst := newTrees()
+		hash := ast.RootHash(src.Tree)
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a", "b", "c"))
+
+		if err := editscript.Verify(src.Tree, dst.Tree, script, slog.Logger{}); err != nil {
+			t.Errorf("Expected the script to be verified, got %v", err)
+		}
+		if ast.RootHash(src.Tree) != hash {
+			t.Errorf("Expected the source tree not to be modified")
+		}
+	})
+
+	t.Run("Test an incomplete script fails the verification", func(t *testing.T) {
+		src, dst := newTrees()
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a", "b", "c"))
+
+		err := editscript.Verify(src.Tree, dst.Tree, script[1:], slog.Logger{})
+		if !errors.Is(err, editscript.ErrVerificationFailed) {
+			t.Errorf("Expected ErrVerificationFailed, got %v", err)
+		}
+	})
+
+	reorders := map[string][]string{
+		"Test moving a sibling to the right": {"y", "x", "z", "w"},
+		"Test moving a sibling to the end":   {"y", "z", "w", "x"},
+		"Test moving a sibling to the left":  {"x", "w", "y", "z"},
+		"Test reversing the siblings":        {"w", "z", "y", "x"},
+	}
+	for name, order := range reorders {
+		t.Run(name, func(t *testing.T) {
+			src := asttest.NewBuilder().Add("", "r", "root", "")
+			for _, leaf := range []string{"x", "y", "z", "w"} {
+				src.Add("r", leaf, "leaf", ast.NodeValueType(leaf))
+			}
+			dst := asttest.NewBuilder().Add("", "r", "root", "")
+			for _, leaf := range order {
+				dst.Add("r", leaf, "leaf", ast.NodeValueType(leaf))
+			}
+			script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "x", "y", "z", "w"))
+
+			if err := editscript.Verify(src.Tree, dst.Tree, script, slog.Logger{}); err != nil {
+				t.Errorf("Expected the script to be verified, got %v", err)
+			}
+			if err := editscript.Apply(src.Tree, script); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for i, child := range src.Nodes["r"].OrderedChildren() {
+				if child != src.Nodes[order[i]] {
+					t.Errorf("Expected %s at %d, got %s", order[i], i, child.Value)
+				}
+			}
+		})
+	}
+
+	t.Run("Test applying a move to the right within the same parent", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "x", "leaf", "x").Add("r", "y", "leaf", "y").Add("r", "z", "leaf", "z")
+		script := editscript.Script{{Type: editscript.Move, Node: src.Nodes["x"], Parent: src.Nodes["r"], Pos: 1}}
+
+		if err := editscript.Apply(src.Tree, script); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		children := src.Nodes["r"].OrderedChildren()
+		if children[0] != src.Nodes["y"] || children[1] != src.Nodes["x"] || children[2] != src.Nodes["z"] {
+			t.Errorf("Expected x to be placed between y and z")
+		}
+	})
+
+	t.Run("Test replacing the root", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "old", "").Add("r", "a", "leaf", "a").Add("r", "b", "leaf", "b")
+		dst := asttest.NewBuilder().Add("", "r", "new", "").Add("r", "b", "leaf", "b").Add("r", "c", "leaf", "c")
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "b"))
+
+		if err := editscript.Apply(src.Tree, script); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if src.Tree.Root().Label != "new" || ast.RootHash(src.Tree) != ast.RootHash(dst.Tree) {
+			t.Errorf("Expected the root to be replaced")
+		}
+		if src.Nodes["b"].Parent != src.Tree.Root() {
+			t.Errorf("Expected b to be moved under the new root")
+		}
+	})
+
+	t.Run("Test patching an empty tree", func(t *testing.T) {
+		src := asttest.NewBuilder()
+		dst := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst))
+
+		if err := editscript.Verify(src.Tree, dst.Tree, script, slog.Logger{}); err != nil {
+			t.Errorf("Expected the script to be verified, got %v", err)
+		}
+	})
+
+	t.Run("Test emptying a tree", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+		dst := asttest.NewBuilder()
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst))
+
+		if err := editscript.Apply(src.Tree, script); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if src.Tree.Root() != nil {
+			t.Errorf("Expected an empty tree")
+		}
+	})
+
+	t.Run("Test replaying the diff of source files", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+		src := []byte("package p\n\nfunc a(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn -x\n}\n\nfunc b() {}\n")
+		dst := []byte("package p\n\nfunc b() {}\n\nfunc a(x int) int {\n\tif x < 0 {\n\t\tx = -x\n\t}\n\treturn x\n}\n")
+
+		result, err := diff.Contents("a.go", src, "b.go", dst, diff.DefaultOptions(*logger))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := editscript.Verify(result.Src, result.Dst, result.Script, *logger); err != nil {
+			t.Errorf("Expected the script to be verified, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+package editscript
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/utils"
+	"slices"
+)
+
+// workingNode is a node of the copy of the source tree that the script generation transforms step by step.
+type workingNode struct {
+	// orig is the node of the source tree this node is a copy of,
+	// or the node of the destination tree for nodes created by an Insert action.
+	orig *ast.Node
+
+	label    ast.NodeLabelType
+	value    ast.NodeValueType
+	parent   *workingNode
+	children []*workingNode
+}
+
+func (w *workingNode) indexInParent() int {
+	return slices.Index(w.parent.children, w)
+}
+
+func (w *workingNode) insertChild(child *workingNode, pos int) {
+	pos = min(max(pos, 0), len(w.children))
+	w.children = slices.Insert(w.children, pos, child)
+	child.parent = w
+}
+
+func (w *workingNode) detach() {
+	w.parent.children = slices.Delete(w.parent.children, w.indexInParent(), w.indexInParent()+1)
+	w.parent = nil
+}
+
+// chawathe holds the state of the script generation.
+type chawathe struct {
+	script Script
+
+	// srcFakeRoot is the parent of the root of the working tree, standing for a nil parent.
+	srcFakeRoot *workingNode
+
+	// workingOf and dstOf map the destination nodes and the working nodes to each other.
+	workingOf map[*ast.Node]*workingNode
+	dstOf     map[*workingNode]*ast.Node
+
+	srcInOrder map[*workingNode]struct{}
+	dstInOrder map[*ast.Node]struct{}
+}
+
+// Generate computes the edit script turning `src` into `dst` given the mappings between their nodes,
+// with the algorithm of Chawathe et al., "Change detection in hierarchically structured information".
+// The script is made of updates, inserts and moves in breadth-first order of the destination tree, followed by deletes in post-order.
+// The trees are left untouched.
+func Generate(src, dst ast.AST, mappings comparator.MappingStore) Script {
+	g := &chawathe{
+		script:      make(Script, 0),
+		srcFakeRoot: &workingNode{children: make([]*workingNode, 0)},
+		workingOf:   make(map[*ast.Node]*workingNode),
+		dstOf:       make(map[*workingNode]*ast.Node),
+		srcInOrder:  make(map[*workingNode]struct{}),
+		dstInOrder:  make(map[*ast.Node]struct{}),
+	}
+
+	origToWorking := make(map[*ast.Node]*workingNode)
+	if src.Root() != nil {
+		g.srcFakeRoot.insertChild(copyToWorking(src.Root(), origToWorking), 0)
+	}
+	for _, pair := range mappings.Pairs() {
+		if w, ok := origToWorking[pair.Left()]; ok {
+			g.workingOf[pair.Right()] = w
+			g.dstOf[w] = pair.Right()
+		}
+	}
+
+	if dst.Root() != nil {
+		queue := []*ast.Node{dst.Root()}
+		for len(queue) > 0 {
+			x := queue[0]
+			queue = queue[1:]
+			g.handle(x)
+			queue = append(queue, x.OrderedChildren()...)
+		}
+	}
+
+	for _, w := range postOrderOf(g.srcFakeRoot) {
+		if _, ok := g.dstOf[w]; !ok && w != g.srcFakeRoot {
+			g.script = append(g.script, Action{Type: Delete, Node: w.orig})
+		}
+	}
+
+	return g.script
+}
+
+// handle generates the actions bringing the destination node `x` to the working tree.
+func (g *chawathe) handle(x *ast.Node) {
+	z := g.srcFakeRoot
+	if x.Parent != nil {
+		z = g.workingOf[x.Parent]
+	}
+
+	w, ok := g.workingOf[x]
+	if !ok {
+		k := g.findPos(x)
+		w = &workingNode{orig: x, label: x.Label, value: x.Value, children: make([]*workingNode, 0)}
+		g.script = append(g.script, Action{Type: Insert, Node: x, Parent: z.orig, Pos: k})
+		z.insertChild(w, k)
+		g.workingOf[x] = w
+		g.dstOf[w] = x
+	} else {
+		if w.label != x.Label || w.value != x.Value {
+			g.script = append(g.script, Action{Type: Update, Node: w.orig, Label: x.Label, Value: x.Value})
+			w.label, w.value = x.Label, x.Value
+		}
+		if w.parent != z {
+			// The position is computed once the node is detached, as an index among the other children.
+			w.detach()
+			k := g.findPos(x)
+			g.script = append(g.script, Action{Type: Move, Node: w.orig, Parent: z.orig, Pos: k})
+			z.insertChild(w, k)
+		}
+	}
+
+	g.srcInOrder[w] = struct{}{}
+	g.dstInOrder[x] = struct{}{}
+	g.alignChildren(w, x)
+}
+
+// alignChildren moves the children of `w` so that they are in the same order as their partners among the children of `x`.
+func (g *chawathe) alignChildren(w *workingNode, x *ast.Node) {
+	dstChildren := x.OrderedChildren()
+	for _, child := range w.children {
+		delete(g.srcInOrder, child)
+	}
+	for _, child := range dstChildren {
+		delete(g.dstInOrder, child)
+	}
+
+	s1 := make([]*workingNode, 0)
+	for _, child := range w.children {
+		if partner, ok := g.dstOf[child]; ok && partner.Parent == x {
+			s1 = append(s1, child)
+		}
+	}
+	s2 := make([]*ast.Node, 0)
+	for _, child := range dstChildren {
+		if partner, ok := g.workingOf[child]; ok && partner.parent == w {
+			s2 = append(s2, child)
+		}
+	}
+
+	isMapped := func(a *workingNode, b *ast.Node) bool {
+		return g.dstOf[a] == b
+	}
+	lcs := utils.LongestCommonSubsequence(s1, s2, isMapped)
+	for _, idxPair := range lcs {
+		g.srcInOrder[s1[idxPair[0]]] = struct{}{}
+		g.dstInOrder[s2[idxPair[1]]] = struct{}{}
+	}
+
+	for _, b := range s2 {
+		if _, ok := g.dstInOrder[b]; ok {
+			continue
+		}
+
+		// The position is computed once the node is detached, so that moving it to the right within `w` is not off by one.
+		a := g.workingOf[b]
+		a.detach()
+		k := g.findPos(b)
+		g.script = append(g.script, Action{Type: Move, Node: a.orig, Parent: w.orig, Pos: k})
+		w.insertChild(a, k)
+		g.srcInOrder[a] = struct{}{}
+		g.dstInOrder[b] = struct{}{}
+	}
+}
+
+// findPos returns the index at which the partner of the destination node `x` should be inserted among its siblings,
+// i.e. right after the partner of the rightmost sibling of `x` on its left that is in order.
+func (g *chawathe) findPos(x *ast.Node) int {
+	if x.Parent == nil {
+		return 0
+	}
+
+	siblings := x.Parent.OrderedChildren()
+	for _, sibling := range siblings {
+		if _, ok := g.dstInOrder[sibling]; ok {
+			if sibling == x {
+				return 0
+			}
+			break
+		}
+	}
+
+	var v *ast.Node
+	for _, sibling := range siblings {
+		if sibling == x {
+			break
+		}
+		if _, ok := g.dstInOrder[sibling]; ok {
+			v = sibling
+		}
+	}
+	if v == nil {
+		return 0
+	}
+
+	return g.workingOf[v].indexInParent() + 1
+}
+
+func copyToWorking(n *ast.Node, origToWorking map[*ast.Node]*workingNode) *workingNode {
+	w := &workingNode{orig: n, label: n.Label, value: n.Value, children: make([]*workingNode, 0, n.Degree())}
+	origToWorking[n] = w
+	for _, child := range n.OrderedChildren() {
+		w.insertChild(copyToWorking(child, origToWorking), len(w.children))
+	}
+	return w
+}
+
+func postOrderOf(w *workingNode) []*workingNode {
+	result := make([]*workingNode, 0)
+	for _, child := range w.children {
+		result = append(result, postOrderOf(child)...)
+	}
+	return append(result, w)
+}
@@ -0,0 +1,139 @@
+package editscript_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"testing"
+)
+
+func mappingsOf(src, dst *asttest.Builder, names ...string) comparator.MappingStore {
+	mappings := comparator.NewMappingStore()
+	for _, name := range names {
+		mappings.Add(src.Nodes[name], dst.Nodes[name])
+	}
+	return mappings
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test identical trees", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+		dst := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a"))
+		if len(script) != 0 {
+			t.Errorf("Expected an empty script, got %v", script)
+		}
+	})
+
+	t.Run("Test update", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "foo")
+		dst := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "bar")
+
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a"))
+		if len(script) != 1 || script[0].Type != editscript.Update {
+			t.Fatalf("Expected a single update, got %v", script)
+		}
+		if script[0].Node != src.Nodes["a"] || script[0].Value != "bar" {
+			t.Errorf("Expected the update of foo to bar, got %v", script[0].String())
+		}
+	})
+
+	t.Run("Test insert, delete and move", func(t *testing.T) {
+		src := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "a", "leaf", "a").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c").
+			Add("r", "d", "leaf", "d")
+		dst := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c").
+			Add("b", "a", "leaf", "a").
+			Add("r", "e", "leaf", "e")
+
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "a", "b", "c"))
+		if script.CountOf(editscript.Insert) != 1 || script.CountOf(editscript.Delete) != 1 || script.CountOf(editscript.Move) != 1 {
+			t.Fatalf("Expected 1 insert, 1 delete and 1 move, got %v", script)
+		}
+
+		for _, action := range script {
+			switch action.Type {
+			case editscript.Insert:
+				if action.Node != dst.Nodes["e"] || action.Parent != src.Nodes["r"] || action.Pos != 2 {
+					t.Errorf("Expected e to be inserted in the root at 2, after b and before d, got %v", action.String())
+				}
+			case editscript.Delete:
+				if action.Node != src.Nodes["d"] {
+					t.Errorf("Expected d to be deleted, got %v", action.String())
+				}
+			case editscript.Move:
+				if action.Node != src.Nodes["a"] || action.Parent != src.Nodes["b"] || action.Pos != 1 {
+					t.Errorf("Expected a to be moved in b at 1, got %v", action.String())
+				}
+			}
+		}
+	})
+
+	reorders := []struct {
+		name     string
+		dst      []string
+		moved    string
+		expected int
+	}{
+		{name: "Test swapping the first two children", dst: []string{"y", "x", "z", "w"}, moved: "x", expected: 1},
+		{name: "Test moving a child to the right", dst: []string{"y", "z", "x", "w"}, moved: "x", expected: 2},
+		{name: "Test moving a child to the left", dst: []string{"x", "w", "y", "z"}, moved: "w", expected: 1},
+	}
+	for _, tc := range reorders {
+		t.Run(tc.name, func(t *testing.T) {
+			src := asttest.NewBuilder().Add("", "r", "root", "")
+			for _, name := range []string{"x", "y", "z", "w"} {
+				src.Add("r", name, "leaf", ast.NodeValueType(name))
+			}
+			dst := asttest.NewBuilder().Add("", "r", "root", "")
+			for _, name := range tc.dst {
+				dst.Add("r", name, "leaf", ast.NodeValueType(name))
+			}
+
+			script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "x", "y", "z", "w"))
+			if len(script) != 1 || script[0].Type != editscript.Move {
+				t.Fatalf("Expected a single move, got %v", script)
+			}
+			// The position is an index among the children other than the moved one.
+			if script[0].Node != src.Nodes[tc.moved] || script[0].Parent != src.Nodes["r"] || script[0].Pos != tc.expected {
+				t.Errorf("Expected %s to be moved in the root at %d, got %v", tc.moved, tc.expected, script[0].String())
+			}
+		})
+	}
+
+	t.Run("Test inserted subtree", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "")
+		dst := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "b", "block", "").
+			Add("b", "c", "leaf", "c")
+
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r"))
+		if len(script) != 2 {
+			t.Fatalf("Expected 2 inserts, got %v", script)
+		}
+		if script[1].Node != dst.Nodes["c"] || script[1].Parent != dst.Nodes["b"] {
+			t.Errorf("Expected c to be inserted under the inserted b, got %v", script[1].String())
+		}
+	})
+
+	t.Run("Test the source tree is left untouched", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+		dst := asttest.NewBuilder().Add("", "r", "root", "x").Add("r", "b", "leaf", "b")
+
+		_ = editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r"))
+		if src.Nodes["r"].Value != "" || src.Nodes["r"].Degree() != 1 {
+			t.Errorf("Expected the source tree not to be modified")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package editscript_test
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"log/slog"
+	"math/rand/v2"
+	"testing"
+)
+
+// permutedTrees builds a random tree of `size` nodes named n0, n1... and a copy of it
+// where the children of every node are shuffled and a few nodes are moved under another parent.
+// It returns both trees with the names of their nodes, which map each node to its copy.
+func permutedTrees(rng *rand.Rand, size int) (*asttest.Builder, *asttest.Builder, []string) {
+	names := make([]string, size)
+	srcParents, dstParents := make([]int, size), make([]int, size)
+	for i := range names {
+		names[i] = fmt.Sprintf("n%d", i)
+		srcParents[i], dstParents[i] = -1, -1
+		if i > 0 {
+			// Parents are created before their children, so that moving a node under an earlier one makes no cycle.
+			srcParents[i] = rng.IntN(i)
+			dstParents[i] = srcParents[i]
+			if rng.IntN(10) == 0 {
+				dstParents[i] = rng.IntN(i)
+			}
+		}
+	}
+
+	build := func(parents []int, shuffle bool) *asttest.Builder {
+		children := make([][]int, size)
+		for i := 1; i < size; i++ {
+			children[parents[i]] = append(children[parents[i]], i)
+		}
+		b := asttest.NewBuilder().Add("", names[0], "node", ast.NodeValueType(names[0]))
+		queue := []int{0}
+		for len(queue) > 0 {
+			parent := queue[0]
+			queue = queue[1:]
+			if shuffle {
+				rng.Shuffle(len(children[parent]), func(i, j int) {
+					children[parent][i], children[parent][j] = children[parent][j], children[parent][i]
+				})
+			}
+			for _, child := range children[parent] {
+				b.Add(names[parent], names[child], "node", ast.NodeValueType(names[child]))
+				queue = append(queue, child)
+			}
+		}
+		return b
+	}
+
+	return build(srcParents, false), build(dstParents, true), names
+}
+
+func TestGenerateApplyProperty(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 200; i++ {
+		src, dst, names := permutedTrees(rng, 2+rng.IntN(30))
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, names...))
+
+		if err := editscript.Verify(src.Tree, dst.Tree, script, slog.Logger{}); err != nil {
+			t.Fatalf("Expected the script of the permutation %d to be verified, got %v in %v", i, err, script)
+		}
+		if err := editscript.Apply(src.Tree, script); err != nil {
+			t.Fatalf("Expected the script of the permutation %d to be applied, got %v", i, err)
+		}
+		if ast.RootHash(src.Tree) != ast.RootHash(dst.Tree) {
+			t.Fatalf("Expected the patched tree of the permutation %d to be isomorphic to the destination tree", i)
+		}
+	}
+}
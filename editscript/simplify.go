@@ -0,0 +1,88 @@
+package editscript
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+)
+
+// Simplify returns a shorter script equivalent to `script`, as made by Generate,
+// where the actions inserting or deleting a whole subtree are collapsed into a single TreeInsert or TreeDelete action,
+// as done by the SimplifiedChawatheScriptGenerator of GumTree.
+//
+// A subtree is whole when its root and all its descendants are inserted, respectively deleted, by the script,
+// so that no node is moved into or out of it. Only the highest such subtrees are collapsed,
+// and the inserts and deletes of leaves are kept as they are.
+// The tree actions take the place of the action inserting or deleting the root of their subtree.
+func Simplify(script Script) Script {
+	inserted, deleted := make(map[*ast.Node]struct{}), make(map[*ast.Node]struct{})
+	for _, action := range script {
+		switch action.Type {
+		case Insert:
+			inserted[action.Node] = struct{}{}
+		case Delete:
+			deleted[action.Node] = struct{}{}
+		}
+	}
+	insertedWhole, deletedWhole := wholeSubtrees(inserted), wholeSubtrees(deleted)
+
+	simplified := make(Script, 0, len(script))
+	for _, action := range script {
+		var whole map[*ast.Node]bool
+		switch action.Type {
+		case Insert:
+			whole = insertedWhole
+		case Delete:
+			whole = deletedWhole
+		default:
+			simplified = append(simplified, action)
+			continue
+		}
+
+		if action.Node.Parent != nil && whole[action.Node.Parent] {
+			continue
+		}
+		if whole[action.Node] && action.Node.Degree() > 0 {
+			if action.Type == Insert {
+				action.Type = TreeInsert
+			} else {
+				action.Type = TreeDelete
+			}
+		}
+		simplified = append(simplified, action)
+	}
+	return simplified
+}
+
+// wholeSubtrees tells for each node of `nodes` whether all its descendants are in `nodes` too.
+func wholeSubtrees(nodes map[*ast.Node]struct{}) map[*ast.Node]bool {
+	whole := make(map[*ast.Node]bool, len(nodes))
+	var isWhole func(n *ast.Node) bool
+	isWhole = func(n *ast.Node) bool {
+		if result, ok := whole[n]; ok {
+			return result
+		}
+		if _, ok := nodes[n]; !ok {
+			return false
+		}
+
+		result := true
+		for _, child := range n.OrderedChildren() {
+			// Every child is checked, to memoize the whole subtree.
+			result = isWhole(child) && result
+		}
+		whole[n] = result
+		return result
+	}
+
+	for n := range nodes {
+		isWhole(n)
+	}
+	return whole
+}
+
+func preOrderOf(n *ast.Node) []*ast.Node {
+	result := []*ast.Node{n}
+	for _, child := range n.OrderedChildren() {
+		result = append(result, preOrderOf(child)...)
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package editscript_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"log/slog"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	t.Parallel()
+
+	newTrees := func() (*asttest.Builder, *asttest.Builder) {
+		src := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "a", "block", "").
+			Add("a", "a1", "leaf", "a1").
+			Add("a", "a2", "block", "").
+			Add("a2", "a3", "leaf", "a3").
+			Add("r", "b", "block", "").
+			Add("b", "b1", "leaf", "b1").
+			Add("b", "m", "leaf", "m").
+			Add("r", "d", "leaf", "d")
+		dst := asttest.NewBuilder().
+			Add("", "r", "root", "").
+			Add("r", "m", "leaf", "m").
+			Add("r", "c", "block", "").
+			Add("c", "c1", "leaf", "c1").
+			Add("c", "c2", "block", "").
+			Add("c2", "c3", "leaf", "c3").
+			Add("r", "e", "block", "").
+			Add("e", "e1", "leaf", "e1").
+			Add("r", "f", "leaf", "f")
+		return src, dst
+	}
+
+	t.Run("Test whole subtrees are collapsed", func(t *testing.T) {
+		src, dst := newTrees()
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r", "m"))
+		simplified := editscript.Simplify(script)
+
+		expected := []struct {
+			actionType editscript.ActionType
+			node       string
+		}{
+			{editscript.Move, "m"},
+			{editscript.TreeInsert, "c"},
+			{editscript.TreeInsert, "e"},
+			{editscript.Insert, "f"},
+			{editscript.TreeDelete, "a"},
+			{editscript.Delete, "b1"},
+			{editscript.Delete, "b"},
+			{editscript.Delete, "d"},
+		}
+		if len(simplified) != len(expected) {
+			t.Fatalf("Expected %d actions, got %d: %v", len(expected), len(simplified), simplified)
+		}
+		for i, action := range simplified {
+			node := src.Nodes[expected[i].node]
+			if expected[i].actionType == editscript.Insert || expected[i].actionType == editscript.TreeInsert {
+				node = dst.Nodes[expected[i].node]
+			}
+			if action.Type != expected[i].actionType || action.Node != node {
+				t.Errorf("Expected action %d to be a %s of %s, got %s", i, expected[i].actionType, expected[i].node, action.String())
+			}
+		}
+	})
+
+	t.Run("Test the simplified script is verified and inverted", func(t *testing.T) {
+		src, dst := newTrees()
+		mappings := mappingsOf(src, dst, "r", "m")
+		simplified := editscript.Simplify(editscript.Generate(src.Tree, dst.Tree, mappings))
+
+		if err := editscript.Verify(src.Tree, dst.Tree, simplified, slog.Logger{}); err != nil {
+			t.Errorf("Expected the simplified script to be verified, got %v", err)
+		}
+
+		inverse, err := editscript.Invert(src.Tree, simplified, mappings, slog.Logger{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if inverse.CountOf(editscript.TreeInsert) != 1 || inverse.CountOf(editscript.TreeDelete) != 2 {
+			t.Errorf("Expected the tree actions to be inverted, got %v", inverse)
+		}
+		if err := editscript.Verify(dst.Tree, src.Tree, inverse, slog.Logger{}); err != nil {
+			t.Errorf("Expected the inverse to be verified, got %v", err)
+		}
+	})
+
+	t.Run("Test scripts without whole subtrees are unchanged", func(t *testing.T) {
+		src := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "a", "leaf", "a")
+		dst := asttest.NewBuilder().Add("", "r", "root", "").Add("r", "b", "leaf", "b")
+		script := editscript.Generate(src.Tree, dst.Tree, mappingsOf(src, dst, "r"))
+
+		if simplified := editscript.Simplify(script); len(simplified) != len(script) {
+			t.Errorf("Expected %d actions, got %d", len(script), len(simplified))
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package frontend
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+)
+
+// ErrUnsupportedFile is returned when no TreeGenerator is able to handle a file.
+var ErrUnsupportedFile = errors.New("unsupported file")
+
+// TreeGenerator is the interface that wraps the basic operation of a frontend:
+// turning the content of a source file into an AST.
+// Every node of the generated AST should carry the position of the text it comes from.
+type TreeGenerator interface {
+	// Generate builds the AST of `src`.
+	Generate(src []byte) (ast.AST, error)
+}
+
+// Sniffer tells whether the content of a file looks like something a TreeGenerator understands.
+type Sniffer func(src []byte) bool
@@ -0,0 +1,192 @@
+package frontend
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"reflect"
+)
+
+// GoTreeGenerator builds the AST of Go source files with the standard go/parser.
+// Labels of nodes are the names of the go/ast types (e.g. `FuncDecl`, `Ident`),
+// and values are the identifiers, literals, operators and comments.
+// The comment groups that are neither doc nor line comments, e.g. a comment between two statements,
+// are children of the innermost node enclosing them, in the order of the file.
+type GoTreeGenerator struct {
+	logger slog.Logger
+}
+
+// Generate builds the AST of the Go source file `src`.
+func (g *GoTreeGenerator) Generate(src []byte) (ast.AST, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := ast.NewAST(g.logger)
+	stack := make([]*ast.Node, 0)
+	goStack := make([]goast.Node, 0)
+
+	// The comment groups that are not the doc or the line comment of a node are only listed by the file,
+	// so they are added as they are met, under the innermost node enclosing them.
+	loose := looseCommentsOf(file)
+	addLoose := func(parent *ast.Node, until token.Pos) {
+		for ; err == nil && len(loose) > 0 && loose[0].Pos() < until; loose = loose[1:] {
+			err = g.addCommentGroup(tree, fset, parent, loose[0])
+		}
+	}
+
+	goast.Inspect(file, func(n goast.Node) bool {
+		if err != nil {
+			return false
+		}
+		if n == nil {
+			end := goStack[len(goStack)-1].End()
+			if file, ok := goStack[len(goStack)-1].(*goast.File); ok {
+				end = file.FileEnd
+			}
+			addLoose(stack[len(stack)-1], end)
+			stack, goStack = stack[:len(stack)-1], goStack[:len(goStack)-1]
+			return err == nil
+		}
+
+		var parent *ast.Node
+		idx := -1
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+			if n.Pos().IsValid() {
+				addLoose(parent, n.Pos())
+			}
+			idx = parent.Degree()
+		}
+
+		var node *ast.Node
+		node, err = tree.Add(parent, idx, goLabelOf(n), goValueOf(n))
+		if err != nil {
+			return false
+		}
+		node.Pos = goPosOf(fset, n)
+		// The `func` keyword of a function declaration precedes its name, so its type starts with its parameters.
+		if funcType, ok := n.(*goast.FuncType); ok && parent != nil && parent.Label == "FuncDecl" && node.Pos.IsValid() {
+			opening := funcType.Params.Opening
+			if funcType.TypeParams != nil {
+				opening = funcType.TypeParams.Opening
+			}
+			node.Pos.Start = fset.Position(opening).Offset
+		}
+
+		stack = append(stack, node)
+		goStack = append(goStack, n)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// looseCommentsOf returns the comment groups of `file` that are neither the doc nor the line comment of a node,
+// e.g. a comment between two statements, in the order of the file.
+func looseCommentsOf(file *goast.File) []*goast.CommentGroup {
+	attached := make(map[*goast.CommentGroup]bool)
+	goast.Inspect(file, func(n goast.Node) bool {
+		if group, ok := n.(*goast.CommentGroup); ok {
+			attached[group] = true
+		}
+		return true
+	})
+
+	loose := make([]*goast.CommentGroup, 0, len(file.Comments))
+	for _, group := range file.Comments {
+		if !attached[group] {
+			loose = append(loose, group)
+		}
+	}
+	return loose
+}
+
+// addCommentGroup adds the comment group `group` and its comments as the last child of `parent`.
+func (g *GoTreeGenerator) addCommentGroup(tree ast.AST, fset *token.FileSet, parent *ast.Node, group *goast.CommentGroup) error {
+	node, err := tree.Add(parent, parent.Degree(), goLabelOf(group), goValueOf(group))
+	if err != nil {
+		return err
+	}
+	node.Pos = goPosOf(fset, group)
+	for _, comment := range group.List {
+		child, err := tree.Add(node, node.Degree(), goLabelOf(comment), goValueOf(comment))
+		if err != nil {
+			return err
+		}
+		child.Pos = goPosOf(fset, comment)
+	}
+	return nil
+}
+
+// goLabelOf returns the name of the go/ast type of `n`, e.g. `FuncDecl`.
+func goLabelOf(n goast.Node) ast.NodeLabelType {
+	return ast.NodeLabelType(reflect.Indirect(reflect.ValueOf(n)).Type().Name())
+}
+
+// goValueOf returns the token carried by `n`, if any.
+func goValueOf(n goast.Node) ast.NodeValueType {
+	switch n := n.(type) {
+	case *goast.Ident:
+		return ast.NodeValueType(n.Name)
+	case *goast.BasicLit:
+		return ast.NodeValueType(n.Value)
+	case *goast.Comment:
+		return ast.NodeValueType(n.Text)
+	case *goast.BinaryExpr:
+		return ast.NodeValueType(n.Op.String())
+	case *goast.UnaryExpr:
+		return ast.NodeValueType(n.Op.String())
+	case *goast.AssignStmt:
+		return ast.NodeValueType(n.Tok.String())
+	case *goast.IncDecStmt:
+		return ast.NodeValueType(n.Tok.String())
+	case *goast.BranchStmt:
+		return ast.NodeValueType(n.Tok.String())
+	case *goast.GenDecl:
+		return ast.NodeValueType(n.Tok.String())
+	case *goast.RangeStmt:
+		if n.Tok != token.ILLEGAL {
+			return ast.NodeValueType(n.Tok.String())
+		}
+	case *goast.ChanType:
+		switch n.Dir {
+		case goast.SEND:
+			return "chan<-"
+		case goast.RECV:
+			return "<-chan"
+		}
+		return "chan"
+	}
+	return ""
+}
+
+func goPosOf(fset *token.FileSet, n goast.Node) ast.NodePos {
+	if !n.Pos().IsValid() || !n.End().IsValid() {
+		return ast.NoPos
+	}
+	return ast.NodePos{
+		Start: fset.Position(n.Pos()).Offset,
+		End:   fset.Position(n.End()).Offset,
+	}
+}
+
+// IsGoSource reports whether `src` starts with a Go package clause.
+func IsGoSource(src []byte) bool {
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly)
+	return err == nil
+}
+
+// NewGoTreeGenerator creates a GoTreeGenerator.
+func NewGoTreeGenerator(logger slog.Logger) *GoTreeGenerator {
+	return &GoTreeGenerator{
+		logger: logger,
+	}
+}
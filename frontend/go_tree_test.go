@@ -0,0 +1,99 @@
+package frontend_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"log/slog"
+	"testing"
+)
+
+func labelsOf(nodes []*ast.Node) []ast.NodeLabelType {
+	labels := make([]ast.NodeLabelType, 0, len(nodes))
+	for _, n := range nodes {
+		labels = append(labels, n.Label)
+	}
+	return labels
+}
+
+func TestGoTreeGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	const src = `// Copyright header, not a doc comment.
+
+// Package p is documented.
+package p
+
+// f is documented.
+func f() {
+	a() // after a
+	// before b
+	b()
+	// at the end
+}
+
+// after the declarations
+`
+
+	tree, err := frontend.NewGoTreeGenerator(slog.Logger{}).Generate([]byte(src))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	commentsOf := func(n *ast.Node) []ast.NodeValueType {
+		comments := make([]ast.NodeValueType, 0)
+		for _, child := range n.OrderedChildren() {
+			if child.Label == "CommentGroup" {
+				comments = append(comments, valuesOf(child.OrderedChildren())...)
+			}
+		}
+		return comments
+	}
+
+	t.Run("Test every comment is in the tree", func(t *testing.T) {
+		count := 0
+		for _, n := range tree.PreOrderNodes() {
+			if n.Label == "Comment" {
+				count++
+			}
+		}
+		if count != 7 {
+			t.Errorf("Expected 7 comments, got %d", count)
+		}
+	})
+
+	t.Run("Test comments around the declarations are children of the file", func(t *testing.T) {
+		expected := []ast.NodeValueType{"// Copyright header, not a doc comment.", "// Package p is documented.", "// after the declarations"}
+		if comments := commentsOf(tree.Root()); len(comments) != len(expected) || comments[0] != expected[0] || comments[1] != expected[1] || comments[2] != expected[2] {
+			t.Errorf("Expected the comments %q, got %q", expected, comments)
+		}
+
+		children := tree.Root().OrderedChildren()
+		if last := children[len(children)-1]; last.Label != "CommentGroup" {
+			t.Errorf("Expected the last comment to follow the declarations, got %s", last.Label)
+		}
+	})
+
+	t.Run("Test comments in a block are children of the block in order", func(t *testing.T) {
+		var block *ast.Node
+		for _, n := range tree.PreOrderNodes() {
+			if n.Label == "BlockStmt" {
+				block = n
+			}
+		}
+
+		labels := labelsOf(block.OrderedChildren())
+		expected := []ast.NodeLabelType{"ExprStmt", "CommentGroup", "CommentGroup", "ExprStmt", "CommentGroup"}
+		if len(labels) != len(expected) {
+			t.Fatalf("Expected the children %v, got %v", expected, labels)
+		}
+		for i := range expected {
+			if labels[i] != expected[i] {
+				t.Errorf("Expected the children %v, got %v", expected, labels)
+				break
+			}
+		}
+		if comments := commentsOf(block); comments[0] != "// after a" || comments[2] != "// at the end" {
+			t.Errorf("Expected the comments of the block in order, got %q", comments)
+		}
+	})
+}
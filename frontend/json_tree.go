@@ -0,0 +1,202 @@
+package frontend
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+)
+
+// Labels of the nodes produced by the JSONTreeGenerator.
+const (
+	JSONObjectLabel  ast.NodeLabelType = "object"
+	JSONMemberLabel  ast.NodeLabelType = "member"
+	JSONArrayLabel   ast.NodeLabelType = "array"
+	JSONStringLabel  ast.NodeLabelType = "string"
+	JSONNumberLabel  ast.NodeLabelType = "number"
+	JSONBooleanLabel ast.NodeLabelType = "boolean"
+	JSONNullLabel    ast.NodeLabelType = "null"
+)
+
+// JSONTreeGenerator builds the AST of JSON documents.
+// Objects hold one `member` node per key, whose value is the decoded key and whose only child is the value of the key.
+// Scalars are leaves whose values are their literal text.
+type JSONTreeGenerator struct {
+	logger slog.Logger
+}
+
+type jsonTreeBuilder struct {
+	tree ast.AST
+	src  []byte
+	pos  int
+}
+
+// Generate builds the AST of the JSON document `src`.
+func (g *JSONTreeGenerator) Generate(src []byte) (ast.AST, error) {
+	var document any
+	if err := json.Unmarshal(src, &document); err != nil {
+		return nil, err
+	}
+
+	b := &jsonTreeBuilder{
+		tree: ast.NewAST(g.logger),
+		src:  src,
+		pos:  0,
+	}
+	if _, err := b.addValue(nil, -1); err != nil {
+		return nil, err
+	}
+	return b.tree, nil
+}
+
+// addValue adds the value starting at the current position as the `idx`th child of `parent`.
+// The document has been validated beforehand, so the scanning does not check the syntax again.
+func (b *jsonTreeBuilder) addValue(parent *ast.Node, idx int) (*ast.Node, error) {
+	b.skipSpaces()
+	start := b.pos
+
+	switch b.src[b.pos] {
+	case '{':
+		return b.addComposite(parent, idx, JSONObjectLabel, '}', b.addMember)
+	case '[':
+		return b.addComposite(parent, idx, JSONArrayLabel, ']', b.addValue)
+	case '"':
+		b.skipString()
+		return b.addLeaf(parent, idx, JSONStringLabel, start)
+	case 't', 'f':
+		b.skipWord()
+		return b.addLeaf(parent, idx, JSONBooleanLabel, start)
+	case 'n':
+		b.skipWord()
+		return b.addLeaf(parent, idx, JSONNullLabel, start)
+	default:
+		b.skipWord()
+		return b.addLeaf(parent, idx, JSONNumberLabel, start)
+	}
+}
+
+// addComposite adds an object or an array, whose elements are added by `addElement`.
+func (b *jsonTreeBuilder) addComposite(
+	parent *ast.Node,
+	idx int,
+	label ast.NodeLabelType,
+	closing byte,
+	addElement func(parent *ast.Node, idx int) (*ast.Node, error),
+) (*ast.Node, error) {
+	start := b.pos
+	node, err := b.tree.Add(parent, idx, label, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the opening bracket.
+	b.pos++
+	for {
+		b.skipSpaces()
+		if b.src[b.pos] == closing {
+			b.pos++
+			break
+		}
+		if b.src[b.pos] == ',' {
+			b.pos++
+			continue
+		}
+		if _, err := addElement(node, node.Degree()); err != nil {
+			return nil, err
+		}
+	}
+
+	node.Pos = ast.NodePos{Start: start, End: b.pos}
+	return node, nil
+}
+
+func (b *jsonTreeBuilder) addMember(parent *ast.Node, idx int) (*ast.Node, error) {
+	start := b.pos
+	b.skipString()
+
+	var key string
+	if err := json.Unmarshal(b.src[start:b.pos], &key); err != nil {
+		return nil, err
+	}
+
+	member, err := b.tree.Add(parent, idx, JSONMemberLabel, ast.NodeValueType(key))
+	if err != nil {
+		return nil, err
+	}
+
+	b.skipSpaces()
+	// Skip the colon.
+	b.pos++
+
+	value, err := b.addValue(member, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Pos = ast.NodePos{Start: start, End: value.Pos.End}
+	return member, nil
+}
+
+func (b *jsonTreeBuilder) addLeaf(parent *ast.Node, idx int, label ast.NodeLabelType, start int) (*ast.Node, error) {
+	node, err := b.tree.Add(parent, idx, label, ast.NodeValueType(b.src[start:b.pos]))
+	if err != nil {
+		return nil, err
+	}
+	node.Pos = ast.NodePos{Start: start, End: b.pos}
+	return node, nil
+}
+
+func (b *jsonTreeBuilder) skipSpaces() {
+	for b.pos < len(b.src) {
+		switch b.src[b.pos] {
+		case ' ', '\t', '\n', '\r':
+			b.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (b *jsonTreeBuilder) skipString() {
+	// Skip the opening quote.
+	b.pos++
+	for b.pos < len(b.src) {
+		switch b.src[b.pos] {
+		case '\\':
+			b.pos += 2
+		case '"':
+			b.pos++
+			return
+		default:
+			b.pos++
+		}
+	}
+}
+
+// skipWord skips a number or a literal name.
+func (b *jsonTreeBuilder) skipWord() {
+	for b.pos < len(b.src) {
+		switch b.src[b.pos] {
+		case ',', ']', '}', ' ', '\t', '\n', '\r':
+			return
+		default:
+			b.pos++
+		}
+	}
+}
+
+// IsJSONDocument reports whether `src` is a JSON object or array.
+func IsJSONDocument(src []byte) bool {
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	return json.Valid(trimmed)
+}
+
+// NewJSONTreeGenerator creates a JSONTreeGenerator.
+func NewJSONTreeGenerator(logger slog.Logger) *JSONTreeGenerator {
+	return &JSONTreeGenerator{
+		logger: logger,
+	}
+}
@@ -0,0 +1,372 @@
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Labels of the nodes produced by the LineTreeGenerator.
+const (
+	LineTreeFileLabel    ast.NodeLabelType = "file"
+	LineTreeLineLabel    ast.NodeLabelType = "line"
+	LineTreeBlockLabel   ast.NodeLabelType = "block"
+	LineTreeWordLabel    ast.NodeLabelType = "word"
+	LineTreeNumberLabel  ast.NodeLabelType = "number"
+	LineTreeStringLabel  ast.NodeLabelType = "string"
+	LineTreeBracketLabel ast.NodeLabelType = "bracket"
+	LineTreeSymbolLabel  ast.NodeLabelType = "symbol"
+)
+
+const defaultTabWidth = 4
+
+// LineTreeGenerator builds an AST out of any text file by looking at its layout only.
+// It is meant for languages we have no parser for (shell scripts, Makefiles, DSLs...),
+// so that the GumTree pipeline degrades gracefully instead of refusing the file.
+//
+// Every non-blank line becomes a `line` node whose children are the tokens of the line.
+// A line that opens more brackets than it closes, or that is followed by more indented lines,
+// owns a `block` node holding the lines nested under it.
+// A block opened by brackets ends when its brackets are closed, whatever the indentation is;
+// a block opened by indentation ends at the first line which is not more indented than its owner.
+type LineTreeGenerator struct {
+	// TabWidth is the number of columns a tab character counts for when measuring indentation.
+	TabWidth int
+
+	logger slog.Logger
+}
+
+// lineTreeFrame is a block that is still open while the lines are scanned.
+type lineTreeFrame struct {
+	// owner is the line node which the block belongs to. It is nil for the file node.
+	owner *ast.Node
+
+	// block is the node collecting the nested lines. It is created lazily on the first nested line.
+	block *ast.Node
+
+	// indent is the indentation of the owner line.
+	indent int
+
+	// depth is the bracket depth right after the owner line, when the block is opened by brackets.
+	// It is negative for blocks opened by indentation.
+	depth int
+}
+
+func (f *lineTreeFrame) isBracketBlock() bool {
+	return f.depth >= 0
+}
+
+type lineTreeBuilder struct {
+	tree   ast.AST
+	src    []byte
+	frames []*lineTreeFrame
+
+	// depth is the current number of unclosed brackets.
+	depth int
+
+	// prevLine and prevIndent describe the last line that may own an indentation block.
+	prevLine   *ast.Node
+	prevIndent int
+
+	tabWidth int
+	logger   slog.Logger
+}
+
+// Generate builds the line tree of `src`.
+func (g *LineTreeGenerator) Generate(src []byte) (ast.AST, error) {
+	tabWidth := g.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = defaultTabWidth
+	}
+
+	tree := ast.NewAST(g.logger)
+	root, err := tree.Add(nil, -1, LineTreeFileLabel, "")
+	if err != nil {
+		return nil, err
+	}
+	root.Pos = ast.NodePos{Start: 0, End: len(src)}
+
+	b := &lineTreeBuilder{
+		tree:     tree,
+		src:      src,
+		frames:   []*lineTreeFrame{{owner: nil, block: root, indent: -1, depth: -1}},
+		tabWidth: tabWidth,
+		logger:   g.logger,
+	}
+
+	offset := 0
+	for offset < len(src) {
+		end := bytes.IndexByte(src[offset:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += offset
+		}
+
+		if err := b.addLine(offset, end); err != nil {
+			return nil, err
+		}
+		offset = end + 1
+	}
+
+	return tree, nil
+}
+
+// addLine adds the line spanning src[start:end] (without its line break) to the tree.
+func (b *lineTreeBuilder) addLine(start, end int) error {
+	line := b.src[start:end]
+	contentStart, indent := b.indentationOf(line)
+	contentEnd := len(bytes.TrimRightFunc(line, unicode.IsSpace))
+	if contentStart >= contentEnd {
+		// Blank lines carry no structure.
+		return nil
+	}
+
+	tokens := tokenizeLine(line[contentStart:contentEnd], start+contentStart)
+
+	// Leading closing brackets belong to the enclosing level, e.g. `}` or `) else {`.
+	leadingClosers := 0
+	for _, tok := range tokens {
+		if tok.label != LineTreeBracketLabel || !isClosingBracket(tok.text) {
+			break
+		}
+		leadingClosers++
+	}
+	b.closeBracketBlocks(max(0, b.depth-leadingClosers))
+	b.closeIndentBlocks(indent)
+
+	if b.prevLine != nil && indent > b.prevIndent && b.top().owner != b.prevLine {
+		b.frames = append(b.frames, &lineTreeFrame{owner: b.prevLine, indent: b.prevIndent, depth: -1})
+	}
+
+	container, err := b.containerOf(b.top())
+	if err != nil {
+		return err
+	}
+	lineNode, err := b.tree.Add(container, container.Degree(), LineTreeLineLabel, "")
+	if err != nil {
+		return err
+	}
+	lineNode.Pos = ast.NodePos{Start: start + contentStart, End: start + contentEnd}
+	extendPos(container, lineNode.Pos)
+
+	depthBefore := max(0, b.depth-leadingClosers)
+	for _, tok := range tokens {
+		tokNode, err := b.tree.Add(lineNode, lineNode.Degree(), tok.label, ast.NodeValueType(tok.text))
+		if err != nil {
+			return err
+		}
+		tokNode.Pos = tok.pos
+
+		if tok.label == LineTreeBracketLabel {
+			if isClosingBracket(tok.text) {
+				b.depth = max(0, b.depth-1)
+			} else {
+				b.depth++
+			}
+		}
+	}
+
+	b.prevLine, b.prevIndent = lineNode, indent
+	if b.depth > depthBefore {
+		b.frames = append(b.frames, &lineTreeFrame{owner: lineNode, indent: indent, depth: b.depth})
+	} else if owner := b.closeBracketBlocks(b.depth); owner != nil {
+		// The line ends a bracket block, e.g. the last line of a multi-line call,
+		// so the owner of the block is the line that later lines are indented against.
+		b.prevLine, b.prevIndent = owner, b.indentOfOwner(owner)
+	}
+
+	return nil
+}
+
+func (b *lineTreeBuilder) top() *lineTreeFrame {
+	return b.frames[len(b.frames)-1]
+}
+
+// closeBracketBlocks closes every block opened by brackets that are no longer open at `depth`,
+// along with the blocks nested in them.
+// It returns the owner of the outermost closed block, or nil if nothing was closed.
+func (b *lineTreeBuilder) closeBracketBlocks(depth int) *ast.Node {
+	var owner *ast.Node
+	for {
+		idx := -1
+		for i := len(b.frames) - 1; i > 0; i-- {
+			if b.frames[i].isBracketBlock() {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || b.frames[idx].depth <= depth {
+			return owner
+		}
+
+		owner = b.frames[idx].owner
+		b.frames = b.frames[:idx]
+	}
+}
+
+// closeIndentBlocks closes the innermost blocks opened by indentation
+// whose owner is indented at least as much as `indent`.
+func (b *lineTreeBuilder) closeIndentBlocks(indent int) {
+	for len(b.frames) > 1 {
+		frame := b.top()
+		if frame.isBracketBlock() || frame.indent < indent {
+			return
+		}
+		b.frames = b.frames[:len(b.frames)-1]
+	}
+}
+
+func (b *lineTreeBuilder) indentOfOwner(owner *ast.Node) int {
+	lineStart := bytes.LastIndexByte(b.src[:owner.Pos.Start], '\n') + 1
+	_, indent := b.indentationOf(b.src[lineStart:owner.Pos.End])
+	return indent
+}
+
+// containerOf returns the node that the lines of the block should be added to,
+// creating the block node under its owner line if needed.
+func (b *lineTreeBuilder) containerOf(frame *lineTreeFrame) (*ast.Node, error) {
+	if frame.block != nil {
+		return frame.block, nil
+	}
+
+	block, err := b.tree.Add(frame.owner, frame.owner.Degree(), LineTreeBlockLabel, "")
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		msg := "failed to create a block node"
+		b.logger.Error(msg)
+		return nil, fmt.Errorf(msg)
+	}
+
+	frame.block = block
+	return block, nil
+}
+
+// indentationOf returns the offset of the first non-blank character of the line and its indentation width.
+func (b *lineTreeBuilder) indentationOf(line []byte) (int, int) {
+	width := 0
+	for i, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			width += b.tabWidth - width%b.tabWidth
+		default:
+			return i, width
+		}
+	}
+	return len(line), width
+}
+
+// extendPos grows the position of `n` and its ancestors so that they cover `pos`.
+func extendPos(n *ast.Node, pos ast.NodePos) {
+	for ; n != nil; n = n.Parent {
+		if !n.Pos.IsValid() {
+			n.Pos = pos
+			continue
+		}
+		n.Pos.Start = min(n.Pos.Start, pos.Start)
+		n.Pos.End = max(n.Pos.End, pos.End)
+	}
+}
+
+type lineToken struct {
+	label ast.NodeLabelType
+	text  string
+	pos   ast.NodePos
+}
+
+// tokenizeLine splits the content of a line into words, numbers, string literals, brackets and symbols.
+// `base` is the offset of the content in the whole source text.
+func tokenizeLine(content []byte, base int) []lineToken {
+	tokens := make([]lineToken, 0)
+	emit := func(label ast.NodeLabelType, start, end int) {
+		tokens = append(tokens, lineToken{
+			label: label,
+			text:  string(content[start:end]),
+			pos:   ast.NodePos{Start: base + start, End: base + end},
+		})
+	}
+
+	i := 0
+	for i < len(content) {
+		r, size := utf8.DecodeRune(content[i:])
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '"' || r == '\'' || r == '`':
+			i = endOfString(content, i, byte(r))
+			emit(LineTreeStringLabel, start, i)
+		case unicode.IsDigit(r):
+			i = endOfRun(content, i, func(r rune) bool {
+				return r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+			})
+			emit(LineTreeNumberLabel, start, i)
+		case r == '_' || unicode.IsLetter(r):
+			i = endOfRun(content, i, func(r rune) bool {
+				return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+			})
+			emit(LineTreeWordLabel, start, i)
+		case isOpeningBracket(string(r)) || isClosingBracket(string(r)):
+			i += size
+			emit(LineTreeBracketLabel, start, i)
+		default:
+			i = endOfRun(content, i, func(r rune) bool {
+				return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' &&
+					r != '"' && r != '\'' && r != '`' && !isOpeningBracket(string(r)) && !isClosingBracket(string(r))
+			})
+			emit(LineTreeSymbolLabel, start, i)
+		}
+	}
+
+	return tokens
+}
+
+// endOfRun returns the offset right after the longest run of runes starting at `i` that satisfy `accept`.
+func endOfRun(content []byte, i int, accept func(rune) bool) int {
+	for i < len(content) {
+		r, size := utf8.DecodeRune(content[i:])
+		if !accept(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// endOfString returns the offset right after the string literal starting at `i`.
+// Unterminated literals extend to the end of the line.
+func endOfString(content []byte, i int, quote byte) int {
+	for j := i + 1; j < len(content); j++ {
+		switch content[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		}
+	}
+	return len(content)
+}
+
+func isOpeningBracket(s string) bool {
+	return s == "(" || s == "[" || s == "{"
+}
+
+func isClosingBracket(s string) bool {
+	return s == ")" || s == "]" || s == "}"
+}
+
+// NewLineTreeGenerator creates a LineTreeGenerator with the default tab width.
+func NewLineTreeGenerator(logger slog.Logger) *LineTreeGenerator {
+	return &LineTreeGenerator{
+		TabWidth: defaultTabWidth,
+		logger:   logger,
+	}
+}
@@ -0,0 +1,125 @@
+package frontend_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"log/slog"
+	"testing"
+)
+
+func valuesOf(nodes []*ast.Node) []ast.NodeValueType {
+	values := make([]ast.NodeValueType, 0, len(nodes))
+	for _, n := range nodes {
+		values = append(values, n.Value)
+	}
+	return values
+}
+
+func TestLineTreeGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test blank input", func(t *testing.T) {
+		tree, err := frontend.NewLineTreeGenerator(slog.Logger{}).Generate([]byte("\n  \n"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root().Label != frontend.LineTreeFileLabel {
+			t.Errorf("Expected root label %s, got %s", frontend.LineTreeFileLabel, tree.Root().Label)
+		}
+		if tree.Root().Degree() != 0 {
+			t.Errorf("Expected no lines, got %d", tree.Root().Degree())
+		}
+	})
+
+	t.Run("Test line tokens are leaves", func(t *testing.T) {
+		src := `echo "a b" 42 >> out.txt`
+		tree, _ := frontend.NewLineTreeGenerator(slog.Logger{}).Generate([]byte(src))
+
+		line := tree.Root().OrderedChildren()[0]
+		tokens := line.OrderedChildren()
+		expectedLabels := []ast.NodeLabelType{
+			frontend.LineTreeWordLabel,
+			frontend.LineTreeStringLabel,
+			frontend.LineTreeNumberLabel,
+			frontend.LineTreeSymbolLabel,
+			frontend.LineTreeWordLabel,
+			frontend.LineTreeSymbolLabel,
+			frontend.LineTreeWordLabel,
+		}
+		if len(tokens) != len(expectedLabels) {
+			t.Fatalf("Expected %d tokens, got %d: %v", len(expectedLabels), len(tokens), valuesOf(tokens))
+		}
+		for i, tok := range tokens {
+			if tok.Label != expectedLabels[i] {
+				t.Errorf("Expected token %d to be a %s, got %s", i, expectedLabels[i], tok.Label)
+			}
+			if string(tok.Value) != src[tok.Pos.Start:tok.Pos.End] {
+				t.Errorf("Expected token %d to span %q, got %q", i, tok.Value, src[tok.Pos.Start:tok.Pos.End])
+			}
+		}
+	})
+
+	t.Run("Test nesting by brackets", func(t *testing.T) {
+		src := "if x {\n  foo(\n      y)\n  bar\n}\nbaz\n"
+		tree, _ := frontend.NewLineTreeGenerator(slog.Logger{}).Generate([]byte(src))
+
+		lines := tree.Root().OrderedChildren()
+		if len(lines) != 3 {
+			t.Fatalf("Expected 3 top-level lines, got %d", len(lines))
+		}
+
+		ifLine := lines[0].OrderedChildren()
+		block := ifLine[len(ifLine)-1]
+		if block.Label != frontend.LineTreeBlockLabel {
+			t.Fatalf("Expected the if line to own a block, got %s", block.Label)
+		}
+		if block.Degree() != 2 {
+			t.Errorf("Expected the block to hold 2 lines, got %d", block.Degree())
+		}
+
+		closing := lines[1].OrderedChildren()
+		if len(closing) != 1 || closing[0].Value != "}" {
+			t.Errorf("Expected the closing bracket to be a top-level line, got %v", valuesOf(closing))
+		}
+	})
+
+	t.Run("Test nesting by indentation", func(t *testing.T) {
+		src := "all: build\n\tgo build ./...\n\tgo vet ./...\n\nclean:\n\trm -rf bin\n"
+		tree, _ := frontend.NewLineTreeGenerator(slog.Logger{}).Generate([]byte(src))
+
+		targets := tree.Root().OrderedChildren()
+		if len(targets) != 2 {
+			t.Fatalf("Expected 2 top-level lines, got %d", len(targets))
+		}
+
+		for i, expectedRecipeLen := range []int{2, 1} {
+			children := targets[i].OrderedChildren()
+			block := children[len(children)-1]
+			if block.Label != frontend.LineTreeBlockLabel {
+				t.Fatalf("Expected target %d to own a block, got %s", i, block.Label)
+			}
+			if block.Degree() != expectedRecipeLen {
+				t.Errorf("Expected target %d to hold %d lines, got %d", i, expectedRecipeLen, block.Degree())
+			}
+		}
+	})
+
+	t.Run("Test lines after a multi-line bracket are indented against its owner", func(t *testing.T) {
+		src := "def f(a,\n      b):\n    return a\nprint(f)\n"
+		tree, _ := frontend.NewLineTreeGenerator(slog.Logger{}).Generate([]byte(src))
+
+		lines := tree.Root().OrderedChildren()
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 top-level lines, got %d", len(lines))
+		}
+
+		defChildren := lines[0].OrderedChildren()
+		body := defChildren[len(defChildren)-1]
+		if body.Label != frontend.LineTreeBlockLabel || body.Degree() != 1 {
+			t.Fatalf("Expected the def line to own a block with the return line")
+		}
+		if body.Pos.Start != len("def f(a,\n      b):\n    ") {
+			t.Errorf("Expected the body block to start at the return line, got %d", body.Pos.Start)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Registry is the single entry point to turn files into ASTs.
+// Generators are registered for file extensions, MIME types or content sniffers,
+// and the registry picks the one to use for each file.
+type Registry interface {
+	// RegisterExtensions makes `generator` handle the files with one of the given extensions, e.g. ".go".
+	// Extensions are case-insensitive, and later registrations win over earlier ones.
+	RegisterExtensions(generator TreeGenerator, extensions ...string)
+
+	// RegisterMIMETypes makes `generator` handle the files whose MIME type is one of the given ones.
+	// The MIME type of a file is guessed from its extension first, and from its content otherwise.
+	RegisterMIMETypes(generator TreeGenerator, mimeTypes ...string)
+
+	// RegisterSniffer makes `generator` handle the files whose content is accepted by `sniffer`.
+	// Sniffers are only consulted when neither the extension nor the MIME type of a file is known,
+	// in the order they have been registered.
+	RegisterSniffer(generator TreeGenerator, sniffer Sniffer)
+
+	// SetFallback sets the generator used for text files that nothing else handles.
+	// A nil fallback makes such files unsupported.
+	SetFallback(generator TreeGenerator)
+
+	// GeneratorFor returns the generator that should handle the file at `path` with the content `src`.
+	// It returns ErrUnsupportedFile if there is none.
+	GeneratorFor(path string, src []byte) (TreeGenerator, error)
+
+	// Parse builds the AST of the file at `path` with the content `src`.
+	Parse(path string, src []byte) (ast.AST, error)
+}
+
+type sniffingGenerator struct {
+	generator TreeGenerator
+	sniffer   Sniffer
+}
+
+type registry struct {
+	byExtension map[string]TreeGenerator
+	byMIMEType  map[string]TreeGenerator
+	sniffers    []sniffingGenerator
+	fallback    TreeGenerator
+}
+
+func (r *registry) RegisterExtensions(generator TreeGenerator, extensions ...string) {
+	for _, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		r.byExtension[strings.ToLower(ext)] = generator
+	}
+}
+
+func (r *registry) RegisterMIMETypes(generator TreeGenerator, mimeTypes ...string) {
+	for _, mimeType := range mimeTypes {
+		r.byMIMEType[baseMIMEType(mimeType)] = generator
+	}
+}
+
+func (r *registry) RegisterSniffer(generator TreeGenerator, sniffer Sniffer) {
+	r.sniffers = append(r.sniffers, sniffingGenerator{generator: generator, sniffer: sniffer})
+}
+
+func (r *registry) SetFallback(generator TreeGenerator) {
+	r.fallback = generator
+}
+
+func (r *registry) GeneratorFor(path string, src []byte) (TreeGenerator, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if generator, ok := r.byExtension[ext]; ok {
+		return generator, nil
+	}
+
+	if ext != "" {
+		if generator, ok := r.byMIMEType[baseMIMEType(mime.TypeByExtension(ext))]; ok {
+			return generator, nil
+		}
+	}
+
+	for _, s := range r.sniffers {
+		if s.sniffer(src) {
+			return s.generator, nil
+		}
+	}
+
+	if generator, ok := r.byMIMEType[baseMIMEType(http.DetectContentType(src))]; ok {
+		return generator, nil
+	}
+
+	if r.fallback != nil && isText(src) {
+		return r.fallback, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, path)
+}
+
+func (r *registry) Parse(path string, src []byte) (ast.AST, error) {
+	generator, err := r.GeneratorFor(path, src)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := generator.Generate(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tree, nil
+}
+
+// baseMIMEType strips the parameters from a MIME type, e.g. "text/plain; charset=utf-8" becomes "text/plain".
+func baseMIMEType(mimeType string) string {
+	if idx := strings.IndexByte(mimeType, ';'); idx >= 0 {
+		mimeType = mimeType[:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// isText reports whether `src` looks like text rather than binary data.
+func isText(src []byte) bool {
+	return utf8.Valid(src) && bytes.IndexByte(src, 0) < 0
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() Registry {
+	return &registry{
+		byExtension: make(map[string]TreeGenerator),
+		byMIMEType:  make(map[string]TreeGenerator),
+		sniffers:    make([]sniffingGenerator, 0),
+		fallback:    nil,
+	}
+}
+
+// NewDefaultRegistry creates a Registry knowing the frontends of this package.
+// Go and JSON files get their own frontends, and any other text file falls back to the line tree.
+func NewDefaultRegistry(logger slog.Logger) Registry {
+	r := NewRegistry()
+
+	goGenerator := NewGoTreeGenerator(logger)
+	r.RegisterExtensions(goGenerator, ".go")
+	r.RegisterMIMETypes(goGenerator, "text/x-go")
+	r.RegisterSniffer(goGenerator, IsGoSource)
+
+	jsonGenerator := NewJSONTreeGenerator(logger)
+	r.RegisterExtensions(jsonGenerator, ".json")
+	r.RegisterMIMETypes(jsonGenerator, "application/json")
+	r.RegisterSniffer(jsonGenerator, IsJSONDocument)
+
+	r.SetFallback(NewLineTreeGenerator(logger))
+	return r
+}
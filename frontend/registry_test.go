@@ -0,0 +1,115 @@
+package frontend_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"log/slog"
+	"testing"
+)
+
+type constantGenerator struct {
+	label ast.NodeLabelType
+}
+
+func (g *constantGenerator) Generate(_ []byte) (ast.AST, error) {
+	tree := ast.NewAST(slog.Logger{})
+	_, err := tree.Add(nil, -1, g.label, "")
+	return tree, err
+}
+
+func TestRegistry_Parse(t *testing.T) {
+	t.Parallel()
+
+	registry := frontend.NewDefaultRegistry(slog.Logger{})
+
+	t.Run("Test picking the generator by extension", func(t *testing.T) {
+		tree, err := registry.Parse("main.go", []byte("package main\n\nfunc main() {}\n"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root().Label != "File" {
+			t.Errorf("Expected the Go frontend to be used, got root label %s", tree.Root().Label)
+		}
+	})
+
+	t.Run("Test picking the generator by content", func(t *testing.T) {
+		tree, err := registry.Parse("config", []byte(`{"name": "gumtree", "tags": [1, true, null]}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root().Label != frontend.JSONObjectLabel {
+			t.Errorf("Expected the JSON frontend to be used, got root label %s", tree.Root().Label)
+		}
+
+		members := tree.Root().OrderedChildren()
+		if len(members) != 2 || members[0].Value != "name" || members[1].Value != "tags" {
+			t.Errorf("Expected members name and tags, got %v", valuesOf(members))
+		}
+	})
+
+	t.Run("Test falling back to the line tree", func(t *testing.T) {
+		tree, err := registry.Parse("build.sh", []byte("#!/bin/sh\nmake all\n"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tree.Root().Label != frontend.LineTreeFileLabel {
+			t.Errorf("Expected the line tree frontend to be used, got root label %s", tree.Root().Label)
+		}
+	})
+
+	t.Run("Test refusing binary files", func(t *testing.T) {
+		_, err := registry.Parse("image.bin", []byte{0x89, 0x00, 0xff, 0x10})
+		if !errors.Is(err, frontend.ErrUnsupportedFile) {
+			t.Errorf("Expected ErrUnsupportedFile, got %v", err)
+		}
+	})
+
+	t.Run("Test reporting syntax errors", func(t *testing.T) {
+		_, err := registry.Parse("broken.json", []byte(`{"a": `))
+		if err == nil {
+			t.Errorf("Expected a syntax error, got nil")
+		}
+	})
+}
+
+func TestRegistry_GeneratorFor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test empty registry", func(t *testing.T) {
+		registry := frontend.NewRegistry()
+		if _, err := registry.GeneratorFor("a.txt", []byte("text")); !errors.Is(err, frontend.ErrUnsupportedFile) {
+			t.Errorf("Expected ErrUnsupportedFile, got %v", err)
+		}
+	})
+
+	t.Run("Test user generators override the defaults", func(t *testing.T) {
+		registry := frontend.NewDefaultRegistry(slog.Logger{})
+		custom := &constantGenerator{label: "custom"}
+		registry.RegisterExtensions(custom, "GO", "mk")
+
+		for _, path := range []string{"main.go", "rules.MK"} {
+			generator, err := registry.GeneratorFor(path, []byte("package main\n"))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if generator != custom {
+				t.Errorf("Expected the custom generator for %s", path)
+			}
+		}
+	})
+
+	t.Run("Test MIME types", func(t *testing.T) {
+		registry := frontend.NewRegistry()
+		custom := &constantGenerator{label: "custom"}
+		registry.RegisterMIMETypes(custom, "text/html; charset=utf-8")
+
+		generator, err := registry.GeneratorFor("index", []byte("<!DOCTYPE html><html></html>"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if generator != custom {
+			t.Errorf("Expected the generator registered for text/html")
+		}
+	})
+}
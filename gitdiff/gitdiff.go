@@ -0,0 +1,172 @@
+package gitdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Repository reads the changes between revisions of a local git repository with the `git` binary.
+// The paths of the files are relative to the root of the repository.
+type Repository interface {
+	// ChangedFiles returns the files changed between the revisions `from` and `to`,
+	// restricted to the files matching `paths` if any, which are relative to the directory given to NewRepository.
+	// Renames are detected by git, and their similarity is the one computed by git.
+	ChangedFiles(from, to string, paths ...string) ([]dirdiff.FilePair, error)
+
+	// Read returns the content of the file at `path` in the revision `rev`.
+	Read(rev, path string) ([]byte, error)
+}
+
+type gitRepository struct {
+	dir string
+}
+
+// ErrInvalidRevision is returned for revisions that git would parse as options, e.g. `--output=file`.
+var ErrInvalidRevision = errors.New("invalid revision")
+
+// checkRevisions returns an error wrapping ErrInvalidRevision if a revision is empty or starts with a dash,
+// since it is given to git among its options.
+func checkRevisions(revs ...string) error {
+	for _, rev := range revs {
+		if rev == "" || strings.HasPrefix(rev, "-") {
+			return fmt.Errorf("%w: %q", ErrInvalidRevision, rev)
+		}
+	}
+	return nil
+}
+
+func (r *gitRepository) ChangedFiles(from, to string, paths ...string) ([]dirdiff.FilePair, error) {
+	if err := checkRevisions(from, to); err != nil {
+		return nil, err
+	}
+	args := append([]string{"diff", "--name-status", "-z", "-M", from, to, "--"}, paths...)
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseNameStatus(out)
+}
+
+func (r *gitRepository) Read(rev, path string) ([]byte, error) {
+	if err := checkRevisions(rev); err != nil {
+		return nil, err
+	}
+	content, err := r.git("cat-file", "blob", rev+":"+path)
+	if content == nil && err == nil {
+		// An empty file is not a missing one, see diff.Contents.
+		content = make([]byte, 0)
+	}
+	return content, err
+}
+
+// git runs git in the repository with `args` and returns its standard output.
+func (r *gitRepository) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseNameStatus parses the output of `git diff --name-status -z`,
+// made of a status followed by one path, or two paths for renames and copies, all separated by NUL bytes.
+func parseNameStatus(out []byte) ([]dirdiff.FilePair, error) {
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return make([]dirdiff.FilePair, 0), nil
+	}
+
+	pairs := make([]dirdiff.FilePair, 0)
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		if status == "" || i+1 >= len(fields) {
+			return nil, fmt.Errorf("unexpected output of git diff: %q", out)
+		}
+
+		switch status[0] {
+		case 'A', 'C':
+			// Copies leave their source untouched, so they are reported as added files.
+			path := fields[i+1]
+			if status[0] == 'C' {
+				if i+2 >= len(fields) {
+					return nil, fmt.Errorf("unexpected output of git diff: %q", out)
+				}
+				path = fields[i+2]
+				i++
+			}
+			pairs = append(pairs, dirdiff.FilePair{DstPath: path, Status: dirdiff.Added})
+		case 'D':
+			pairs = append(pairs, dirdiff.FilePair{SrcPath: fields[i+1], Status: dirdiff.Removed})
+		case 'R':
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("unexpected output of git diff: %q", out)
+			}
+			score, _ := strconv.Atoi(status[1:])
+			pairs = append(pairs, dirdiff.FilePair{SrcPath: fields[i+1], DstPath: fields[i+2], Status: dirdiff.Renamed, Similarity: float64(score) / 100})
+			i++
+		default:
+			// Modified files, type changes and unmerged files are compared in place.
+			pairs = append(pairs, dirdiff.FilePair{SrcPath: fields[i+1], DstPath: fields[i+1], Status: dirdiff.Modified})
+		}
+		i += 2
+	}
+	return pairs, nil
+}
+
+// Compare compares the files changed between the revisions `from` and `to` of `repo`, restricted to `paths` if any.
+// Files that cannot be compared, e.g. binary files, are reported with their error rather than failing the whole comparison.
+func Compare(repo Repository, from, to string, paths []string, opts diff.Options) ([]dirdiff.FileDiff, error) {
+	pairs, err := repo.ChangedFiles(from, to, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]dirdiff.FileDiff, 0, len(pairs))
+	for _, pair := range pairs {
+		result, err := compareRevisions(repo, from, to, pair, opts)
+		diffs = append(diffs, dirdiff.FileDiff{FilePair: pair, Result: result, Err: err})
+	}
+	return diffs, nil
+}
+
+func compareRevisions(repo Repository, from, to string, pair dirdiff.FilePair, opts diff.Options) (*diff.Result, error) {
+	var srcContent, dstContent []byte
+	var err error
+	if pair.SrcPath != "" {
+		if srcContent, err = repo.Read(from, pair.SrcPath); err != nil {
+			return nil, err
+		}
+	}
+	if pair.DstPath != "" {
+		if dstContent, err = repo.Read(to, pair.DstPath); err != nil {
+			return nil, err
+		}
+	}
+	return diff.Contents(pair.SrcPath, srcContent, pair.DstPath, dstContent, opts)
+}
+
+// ErrNotARepository is returned by NewRepository for directories outside of any git repository.
+var ErrNotARepository = errors.New("not a git repository")
+
+// NewRepository creates a Repository for the git repository containing `dir`.
+func NewRepository(dir string) (Repository, error) {
+	if err := exec.Command("git", "-C", dir, "rev-parse", "--git-dir").Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNotARepository)
+		}
+		return nil, err
+	}
+	return &gitRepository{dir: dir}, nil
+}
@@ -0,0 +1,143 @@
+package gitdiff_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/gitdiff"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newTestRepository creates a repository with two commits, tagged v1 and v2.
+func newTestRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("config.json", `{"port": 80, "hosts": ["a", "b"]}`)
+	write("notes.txt", "some notes\nabout the project\n")
+	write("old.txt", "to be removed\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "v1")
+	run("tag", "v1")
+
+	write("config.json", `{"port": 8080, "hosts": ["a", "b"]}`)
+	write("docs/notes.txt", "some notes\nabout the project\n")
+	write("empty.txt", "")
+	run("rm", "-q", "notes.txt", "old.txt")
+	run("add", ".")
+	run("commit", "-q", "-m", "v2")
+	run("tag", "v2")
+	return dir
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+	dir := newTestRepository(t)
+
+	repo, err := gitdiff.NewRepository(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	opts := diff.DefaultOptions(*logger)
+
+	t.Run("Test the changed files", func(t *testing.T) {
+		diffs, err := gitdiff.Compare(repo, "v1", "v2", nil, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := map[string]dirdiff.FilePair{
+			"config.json":    {SrcPath: "config.json", DstPath: "config.json", Status: dirdiff.Modified},
+			"docs/notes.txt": {SrcPath: "notes.txt", DstPath: "docs/notes.txt", Status: dirdiff.Renamed, Similarity: 1},
+			"empty.txt":      {DstPath: "empty.txt", Status: dirdiff.Added},
+			"old.txt":        {SrcPath: "old.txt", Status: dirdiff.Removed},
+		}
+		if len(diffs) != len(expected) {
+			t.Fatalf("Expected %d changed files, got %+v", len(expected), diffs)
+		}
+		for _, d := range diffs {
+			key := d.DstPath
+			if key == "" {
+				key = d.SrcPath
+			}
+			if d.FilePair != expected[key] {
+				t.Errorf("Expected %+v, got %+v", expected[key], d.FilePair)
+			}
+			if d.Err != nil {
+				t.Errorf("Expected %s to be compared, got %v", key, d.Err)
+			}
+		}
+	})
+
+	t.Run("Test the edit script of a modified file", func(t *testing.T) {
+		diffs, err := gitdiff.Compare(repo, "v1", "v2", []string{"config.json"}, opts)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(diffs) != 1 || diffs[0].Result == nil {
+			t.Fatalf("Expected the diff of config.json, got %+v", diffs)
+		}
+		script := diffs[0].Result.Script
+		if len(script) != 1 || script[0].Value != "8080" {
+			t.Errorf("Expected the port to be updated, got %v", script)
+		}
+	})
+
+	t.Run("Test unknown revisions", func(t *testing.T) {
+		if _, err := gitdiff.Compare(repo, "v1", "v3", nil, opts); err == nil {
+			t.Errorf("Expected an error")
+		}
+	})
+
+	t.Run("Test revisions parsed as options are rejected", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "output")
+		for _, rev := range []string{"--output=" + output, "--no-index", ""} {
+			if _, err := gitdiff.Compare(repo, "v1", rev, nil, opts); !errors.Is(err, gitdiff.ErrInvalidRevision) {
+				t.Errorf("Expected ErrInvalidRevision for %q, got %v", rev, err)
+			}
+			if _, err := repo.Read(rev, "config.json"); !errors.Is(err, gitdiff.ErrInvalidRevision) {
+				t.Errorf("Expected ErrInvalidRevision when reading %q, got %v", rev, err)
+			}
+		}
+		if _, err := os.Stat(output); !os.IsNotExist(err) {
+			t.Errorf("Expected git not to write the output file, got %v", err)
+		}
+	})
+}
+
+func TestNewRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	if _, err := gitdiff.NewRepository(dir); !errors.Is(err, gitdiff.ErrNotARepository) {
+		t.Errorf("Expected ErrNotARepository, got %v", err)
+	}
+}
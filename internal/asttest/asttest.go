@@ -0,0 +1,34 @@
+// Package asttest provides helpers to build the trees of tests.
+package asttest
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+)
+
+// Builder builds trees from chained calls, keeping the nodes by name.
+type Builder struct {
+	Tree  ast.AST
+	Nodes map[string]*ast.Node
+}
+
+func NewBuilder() *Builder {
+	return &Builder{Tree: ast.NewAST(slog.Logger{}), Nodes: make(map[string]*ast.Node)}
+}
+
+// Add adds a node named `name` as the last child of the node named `parent` (or as the root if it is empty).
+// It panics if the node cannot be added, e.g. because `parent` is unknown.
+func (b *Builder) Add(parent, name string, label ast.NodeLabelType, value ast.NodeValueType) *Builder {
+	var parentNode *ast.Node
+	idx := -1
+	if parent != "" {
+		parentNode = b.Nodes[parent]
+		idx = parentNode.Degree()
+	}
+	node, err := b.Tree.Add(parentNode, idx, label, value)
+	if err != nil {
+		panic(err)
+	}
+	b.Nodes[name] = node
+	return b
+}
@@ -1,5 +1,10 @@
-package gumtree
+package main
 
-func main() {
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/cli"
+	"os"
+)
 
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
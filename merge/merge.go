@@ -0,0 +1,189 @@
+// Package merge combines two concurrent versions of a tree derived from a common base version.
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/unparse"
+	"log/slog"
+)
+
+type ConflictKind int
+
+const (
+	// UpdateConflict is reported when both versions change the label or the value of a node differently.
+	UpdateConflict ConflictKind = iota
+
+	// MoveConflict is reported when both versions move a node under different parents,
+	// or when their moves together would make a node its own ancestor.
+	MoveConflict
+
+	// DeleteConflict is reported when a version deletes a node the other one changes, moves,
+	// or inserts or moves nodes under.
+	DeleteConflict
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case UpdateConflict:
+		return "update"
+	case MoveConflict:
+		return "move"
+	case DeleteConflict:
+		return "delete"
+	default:
+		return fmt.Sprintf("ConflictKind(%d)", int(k))
+	}
+}
+
+// Conflict is a pair of actions of the two edit scripts that cannot be both applied to a node of the base tree.
+type Conflict struct {
+	Kind ConflictKind
+
+	// Base is the node of the base tree the conflicting actions apply to.
+	Base *ast.Node
+
+	// Left and Right are the conflicting actions of the scripts turning the base tree into the left and the right trees.
+	// One of them is nil when its version keeps the node as it is in the base tree.
+	Left, Right *editscript.Action
+}
+
+func (c *Conflict) String() string {
+	return fmt.Sprintf("%s conflict on %s: left %s, right %s", c.Kind, editscript.DescribeNode(c.Base), describeAction(c.Left), describeAction(c.Right))
+}
+
+func describeAction(action *editscript.Action) string {
+	if action == nil {
+		return "keeps it"
+	}
+	return action.String()
+}
+
+// Result is the three-way merge of two versions of a base tree.
+type Result struct {
+	// Left and Right are the differences from the base tree to the left and the right trees.
+	Left, Right *diff.Result
+
+	// Merged is the tree combining the changes of both versions.
+	// Its nodes do not have positions as they come from several trees, see Origins.
+	Merged ast.AST
+
+	// Origins maps every node of the merged tree to the node it was built from:
+	// its version in the left tree if there is one, else in the right tree, else in the base tree.
+	Origins map[*ast.Node]*ast.Node
+
+	// Mappings maps the nodes of the base tree kept in the merged tree, and Script is the combined edit script
+	// turning the base tree into the merged tree.
+	Mappings comparator.MappingStore
+	Script   editscript.Script
+
+	// Conflicts are the conflicting actions of the two versions, in pre-order of the base tree.
+	// The merged tree resolves every conflict in a fixed way: changes win over deletions,
+	// and the left version wins over the right one.
+	Conflicts []Conflict
+}
+
+// HasConflicts returns true if the two versions conflict.
+func (r *Result) HasConflicts() bool {
+	return len(r.Conflicts) > 0
+}
+
+// Unparse returns the source text of the merged tree, see unparse.Unparse.
+// The text of the parts of the merged tree that are unchanged in a version is copied from that version,
+// which requires the contents of the versions, as set by Contents.
+func (r *Result) Unparse(printer unparse.Printer) ([]byte, error) {
+	contentOf := make(map[*ast.Node][]byte)
+	versions := []struct {
+		tree    ast.AST
+		content []byte
+	}{{r.Left.Src, r.Left.SrcContent}, {r.Left.Dst, r.Left.DstContent}, {r.Right.Dst, r.Right.DstContent}}
+	for _, version := range versions {
+		for _, n := range version.tree.PreOrderNodes() {
+			contentOf[n] = version.content
+		}
+	}
+
+	origins := func(n *ast.Node) (unparse.Origin, bool) {
+		origin, ok := r.Origins[n]
+		if !ok || contentOf[origin] == nil {
+			return unparse.Origin{}, false
+		}
+		return unparse.Origin{Node: origin, Content: contentOf[origin]}, true
+	}
+	return unparse.Unparse(r.Merged, origins, printer)
+}
+
+// Trees merges the changes from `base` to `left` and from `base` to `right`.
+//
+// Each version is matched against the base tree with the comparator. The nodes of the base tree mapped in a version
+// are kept by it, the unmapped nodes of the base tree are deleted and the unmapped nodes of a version are inserted.
+// The merged tree keeps the nodes that are kept by both versions or changed by one of them, and inserts the nodes of both.
+// A node takes the label, the value and the parent changed by either version,
+// and the children of a node are ordered by merging their order in the three trees, as `diff3` does with lines.
+func Trees(base, left, right ast.AST, opts diff.Options) (*Result, error) {
+	leftDiff, err := diff.Trees(base, left, opts)
+	if err != nil {
+		return nil, err
+	}
+	rightDiff, err := diff.Trees(base, right, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Diffs(leftDiff, rightDiff, opts.Logger)
+}
+
+// Diffs merges the changes of two differences from the same base tree, e.g. computed by diff.Trees, see Trees.
+func Diffs(left, right *diff.Result, logger slog.Logger) (*Result, error) {
+	if left.Src != right.Src {
+		return nil, errors.New("the differences do not have the same base tree")
+	}
+
+	m := newMerger(left, right)
+	m.resolve()
+
+	merged, origins, mappings, err := m.build(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Left:      left,
+		Right:     right,
+		Merged:    merged,
+		Origins:   origins,
+		Mappings:  mappings,
+		Script:    editscript.Generate(left.Src, merged, mappings),
+		Conflicts: m.conflicts,
+	}, nil
+}
+
+// Contents parses and normalizes the three versions of a file, then merges them.
+// The paths are only used to pick the frontend and may be fake.
+func Contents(basePath string, baseContent []byte, leftPath string, leftContent []byte, rightPath string, rightContent []byte, opts diff.Options) (*Result, error) {
+	base, err := diff.Parse(basePath, baseContent, opts)
+	if err != nil {
+		return nil, err
+	}
+	left, err := diff.Parse(leftPath, leftContent, opts)
+	if err != nil {
+		return nil, err
+	}
+	right, err := diff.Parse(rightPath, rightContent, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Trees(base, left, right, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Left.SrcPath, result.Left.DstPath = basePath, leftPath
+	result.Left.SrcContent, result.Left.DstContent = baseContent, leftContent
+	result.Right.SrcPath, result.Right.DstPath = basePath, rightPath
+	result.Right.SrcContent, result.Right.DstContent = baseContent, rightContent
+	return result, nil
+}
@@ -0,0 +1,414 @@
+package merge_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"github.com/Xanonymous-GitHub/gumtree-go/merge"
+	"github.com/Xanonymous-GitHub/gumtree-go/unparse"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+// diffOf returns the difference from `base` to `version`, mapping the nodes with the same name.
+func diffOf(base, version *asttest.Builder) *diff.Result {
+	mappings := comparator.NewMappingStore()
+	for name, n := range base.Nodes {
+		if partner, ok := version.Nodes[name]; ok {
+			mappings.Add(n, partner)
+		}
+	}
+	return &diff.Result{
+		Src:      base.Tree,
+		Dst:      version.Tree,
+		Mappings: mappings,
+		Script:   editscript.Generate(base.Tree, version.Tree, mappings),
+	}
+}
+
+// baseTree is a function declaration with two parameters and a body of three statements.
+func baseTree() *asttest.Builder {
+	return asttest.NewBuilder().
+		Add("", "f", "FuncDecl", "").
+		Add("f", "name", "Ident", "sum").
+		Add("f", "params", "FieldList", "").
+		Add("params", "a", "Ident", "a").
+		Add("params", "b", "Ident", "b").
+		Add("f", "body", "BlockStmt", "").
+		Add("body", "s1", "AssignStmt", "").
+		Add("s1", "x", "Ident", "x").
+		Add("body", "s2", "ExprStmt", "").
+		Add("s2", "call", "Ident", "log").
+		Add("body", "s3", "ReturnStmt", "").
+		Add("s3", "y", "Ident", "y")
+}
+
+func TestDiffs(t *testing.T) {
+	t.Parallel()
+
+	logger := *slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	mergeOf := func(t *testing.T, base, left, right *asttest.Builder) *merge.Result {
+		result, err := merge.Diffs(diffOf(base, left), diffOf(base, right), logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := editscript.Verify(base.Tree, result.Merged, result.Script, logger); err != nil {
+			t.Errorf("Expected the combined script to turn the base tree into the merged tree, got %v", err)
+		}
+		return result
+	}
+	assertMerged := func(t *testing.T, result *merge.Result, expected *asttest.Builder) {
+		if ast.RootHash(result.Merged) != ast.RootHash(expected.Tree) {
+			t.Errorf("Expected the merged tree to be the expected one")
+		}
+	}
+	assertConflicts := func(t *testing.T, result *merge.Result, kinds ...merge.ConflictKind) {
+		if len(result.Conflicts) != len(kinds) {
+			t.Fatalf("Expected %d conflicts, got %d: %v", len(kinds), len(result.Conflicts), result.Conflicts)
+		}
+		for i, kind := range kinds {
+			if result.Conflicts[i].Kind != kind {
+				t.Errorf("Expected a %s conflict, got %s", kind, result.Conflicts[i].String())
+			}
+		}
+	}
+
+	t.Run("Test merging unchanged versions", func(t *testing.T) {
+		t.Parallel()
+
+		base := baseTree()
+		result := mergeOf(t, base, baseTree(), baseTree())
+		assertConflicts(t, result)
+		assertMerged(t, result, base)
+		if len(result.Script) != 0 {
+			t.Errorf("Expected an empty script, got %d actions", len(result.Script))
+		}
+	})
+
+	t.Run("Test merging independent changes", func(t *testing.T) {
+		t.Parallel()
+
+		// The left version renames the function, the right one adds a parameter and deletes the log statement.
+		left := baseTree()
+		_ = left.Tree.UpdateValue(left.Nodes["name"], "add")
+		right := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("params", "c", "Ident", "c").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+		expected := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "add").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("params", "c", "Ident", "c").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result)
+		assertMerged(t, result, expected)
+	})
+
+	t.Run("Test merging the same change made by both versions", func(t *testing.T) {
+		t.Parallel()
+
+		left, right, expected := baseTree(), baseTree(), baseTree()
+		_ = left.Tree.UpdateValue(left.Nodes["name"], "add")
+		_ = right.Tree.UpdateValue(right.Nodes["name"], "add")
+		_ = expected.Tree.UpdateValue(expected.Nodes["name"], "add")
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result)
+		assertMerged(t, result, expected)
+	})
+
+	t.Run("Test merging insertions around the same statements", func(t *testing.T) {
+		t.Parallel()
+
+		// The left version inserts a statement at the beginning of the body, the right one before the return.
+		left := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "l", "DeferStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s2", "ExprStmt", "").
+			Add("s2", "call", "Ident", "log").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+		right := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s2", "ExprStmt", "").
+			Add("s2", "call", "Ident", "log").
+			Add("body", "r", "IfStmt", "").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+		expected := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "l", "DeferStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s2", "ExprStmt", "").
+			Add("s2", "call", "Ident", "log").
+			Add("body", "r", "IfStmt", "").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result)
+		assertMerged(t, result, expected)
+	})
+
+	t.Run("Test conflicting updates keep the left version", func(t *testing.T) {
+		t.Parallel()
+
+		base, left, right := baseTree(), baseTree(), baseTree()
+		_ = left.Tree.UpdateValue(left.Nodes["name"], "add")
+		_ = right.Tree.UpdateValue(right.Nodes["name"], "total")
+
+		result := mergeOf(t, base, left, right)
+		assertConflicts(t, result, merge.UpdateConflict)
+		assertMerged(t, result, left)
+
+		conflict := result.Conflicts[0]
+		if conflict.Base != base.Nodes["name"] {
+			t.Errorf("Expected the conflict to be on the name of the function, got %s", conflict.String())
+		}
+		if conflict.Left == nil || conflict.Left.Value != "add" || conflict.Right == nil || conflict.Right.Value != "total" {
+			t.Errorf("Expected the two updates, got %s", conflict.String())
+		}
+	})
+
+	t.Run("Test conflicting moves keep the left version", func(t *testing.T) {
+		t.Parallel()
+
+		// Both versions move the log statement out of the body, under different parents.
+		left := baseTree().Add("f", "l", "BlockStmt", "")
+		_ = left.Tree.Move(left.Nodes["s2"], left.Nodes["l"], 0)
+		right := baseTree().Add("f", "r", "IfStmt", "")
+		_ = right.Tree.Move(right.Nodes["s2"], right.Nodes["r"], 0)
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result, merge.MoveConflict)
+		statement := mergedNodeOf(result, left.Nodes["s2"])
+		if statement == nil || result.Origins[statement.Parent] != left.Nodes["l"] {
+			t.Errorf("Expected the statement to be under the left block")
+		}
+	})
+
+	t.Run("Test moves making a node its own ancestor conflict", func(t *testing.T) {
+		t.Parallel()
+
+		// The left version moves the first statement into the second one, and the right version does the opposite.
+		left, right := baseTree(), baseTree()
+		_ = left.Tree.Move(left.Nodes["s1"], left.Nodes["s2"], 1)
+		_ = right.Tree.Move(right.Nodes["s2"], right.Nodes["s1"], 1)
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result, merge.MoveConflict)
+		assertMerged(t, result, left)
+	})
+
+	t.Run("Test a deletion conflicting with an update keeps the updated node", func(t *testing.T) {
+		t.Parallel()
+
+		left := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "a", "Ident", "a").
+			Add("params", "b", "Ident", "b").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+		right := baseTree()
+		_ = right.Tree.UpdateValue(right.Nodes["call"], "print")
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result, merge.DeleteConflict)
+		assertMerged(t, result, right)
+
+		conflict := result.Conflicts[0]
+		if conflict.Left == nil || conflict.Left.Type != editscript.Delete || conflict.Right == nil || conflict.Right.Type != editscript.Update {
+			t.Errorf("Expected a deletion conflicting with an update, got %s", conflict.String())
+		}
+	})
+
+	t.Run("Test an insertion under a deleted node keeps the node", func(t *testing.T) {
+		t.Parallel()
+
+		// The left version deletes the parameters, the right one adds a parameter.
+		left := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s2", "ExprStmt", "").
+			Add("s2", "call", "Ident", "log").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+		right := baseTree().Add("params", "c", "Ident", "c")
+		expected := asttest.NewBuilder().
+			Add("", "f", "FuncDecl", "").
+			Add("f", "name", "Ident", "sum").
+			Add("f", "params", "FieldList", "").
+			Add("params", "c", "Ident", "c").
+			Add("f", "body", "BlockStmt", "").
+			Add("body", "s1", "AssignStmt", "").
+			Add("s1", "x", "Ident", "x").
+			Add("body", "s2", "ExprStmt", "").
+			Add("s2", "call", "Ident", "log").
+			Add("body", "s3", "ReturnStmt", "").
+			Add("s3", "y", "Ident", "y")
+
+		result := mergeOf(t, baseTree(), left, right)
+		assertConflicts(t, result, merge.DeleteConflict)
+		assertMerged(t, result, expected)
+
+		conflict := result.Conflicts[0]
+		if conflict.Right == nil || conflict.Right.Type != editscript.Insert || conflict.Right.Node != right.Nodes["c"] {
+			t.Errorf("Expected the insertion of the parameter to conflict, got %s", conflict.String())
+		}
+	})
+
+	t.Run("Test the origins of the merged nodes", func(t *testing.T) {
+		t.Parallel()
+
+		left, right := baseTree(), baseTree().Add("params", "c", "Ident", "c")
+		result := mergeOf(t, baseTree(), left, right)
+		for _, n := range result.Merged.PreOrderNodes() {
+			origin := result.Origins[n]
+			switch {
+			case n.Value == "c" && origin != right.Nodes["c"]:
+				t.Errorf("Expected the inserted parameter to come from the right tree")
+			case n.Value == "sum" && origin != left.Nodes["name"]:
+				t.Errorf("Expected the kept nodes to come from the left tree")
+			}
+		}
+	})
+
+	t.Run("Test merging differences of different base trees fails", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := merge.Diffs(diffOf(baseTree(), baseTree()), diffOf(baseTree(), baseTree()), logger); err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+}
+
+func TestTrees(t *testing.T) {
+	t.Parallel()
+
+	logger := *slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("Test an invalid config is returned as an error", func(t *testing.T) {
+		t.Parallel()
+
+		opts := diff.DefaultOptions(logger)
+		opts.MinDice = 2
+		result, err := merge.Trees(baseTree().Tree, baseTree().Tree, baseTree().Tree, opts)
+		if !errors.Is(err, comparator.ErrInvalidConfig) || result != nil {
+			t.Errorf("Expected only an invalid config error, got %v and %v", result, err)
+		}
+	})
+}
+
+// mergedNodeOf returns the node of the merged tree whose origin is `n`, or nil.
+func mergedNodeOf(result *merge.Result, n *ast.Node) *ast.Node {
+	for merged, origin := range result.Origins {
+		if origin == n {
+			return merged
+		}
+	}
+	return nil
+}
+
+func TestResult_Unparse(t *testing.T) {
+	t.Parallel()
+
+	logger := *slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	parse := func(src string) ast.AST {
+		tree, err := frontend.NewJSONTreeGenerator(logger).Generate([]byte(src))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return tree
+	}
+	// diffOfContents maps the nodes of the versions in pre-order, as they only differ by values and by appended nodes.
+	diffOfContents := func(base ast.AST, baseContent, version string) *diff.Result {
+		dst := parse(version)
+		mappings := comparator.NewMappingStore()
+		srcNodes, dstNodes := base.PreOrderNodes(), dst.PreOrderNodes()
+		for i := range min(len(srcNodes), len(dstNodes)) {
+			mappings.Add(srcNodes[i], dstNodes[i])
+		}
+		return &diff.Result{
+			SrcContent: []byte(baseContent),
+			DstContent: []byte(version),
+			Src:        base,
+			Dst:        dst,
+			Mappings:   mappings,
+			Script:     editscript.Generate(base, dst, mappings),
+		}
+	}
+
+	t.Run("Test the merged text keeps the formatting of the versions", func(t *testing.T) {
+		t.Parallel()
+
+		baseContent := "{\n  \"name\": \"gumtree\",\n  \"version\": \"1.0.0\",\n  \"tags\": [\"diff\", \"ast\"]\n}\n"
+		base := parse(baseContent)
+		left := diffOfContents(base, baseContent, "{\n  \"name\": \"gumtree\",\n  \"version\": \"1.1.0\",\n  \"tags\": [\"diff\", \"ast\"]\n}\n")
+		right := diffOfContents(base, baseContent, "{\n  \"name\": \"gumtree-go\",\n  \"version\": \"1.0.0\",\n  \"tags\": [\"diff\", \"ast\", \"merge\"]\n}\n")
+
+		result, err := merge.Diffs(left, right, logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		text, err := result.Unparse(unparse.NewJSONPrinter())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := "{\n  \"name\": \"gumtree-go\",\n  \"version\": \"1.1.0\",\n  \"tags\": [\"diff\", \"ast\", \"merge\"]\n}\n"
+		if string(text) != expected {
+			t.Errorf("Expected %q, got %q", expected, text)
+		}
+	})
+}
@@ -0,0 +1,395 @@
+package merge
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"log/slog"
+	"slices"
+)
+
+// state is what the merge decides for a node of the merged tree.
+// A node is identified by its version in the base tree, or by itself if it is inserted by the left or the right version.
+type state struct {
+	// base, left and right are the versions of the node in each tree, nil where it does not exist.
+	base, left, right *ast.Node
+
+	label ast.NodeLabelType
+	value ast.NodeValueType
+
+	// parent is the identity of the parent of the node in the merged tree, nil for the root.
+	// leftParent and rightParent are the identities of its parent in the left and the right trees.
+	parent, leftParent, rightParent *ast.Node
+	parentFromRight                 bool
+
+	exists bool
+}
+
+// origin returns the node the merged node is built from.
+func (s *state) origin() *ast.Node {
+	switch {
+	case s.left != nil:
+		return s.left
+	case s.right != nil:
+		return s.right
+	default:
+		return s.base
+	}
+}
+
+// merger holds the state of a three-way merge.
+type merger struct {
+	left, right *diff.Result
+
+	// states are indexed by the identity of the nodes, and order lists the identities in a deterministic order:
+	// the base tree in pre-order, then the nodes inserted by the left and the right versions.
+	states map[*ast.Node]*state
+	order  []*ast.Node
+
+	// leftActions and rightActions are the actions of the scripts indexed by the node they affect,
+	// which is the identity of that node.
+	leftActions, rightActions map[*ast.Node][]editscript.Action
+
+	conflicts []Conflict
+}
+
+// identityOf returns the identity of the node `n` of the tree compared to the base tree in `result`.
+func identityOf(result *diff.Result, n *ast.Node) *ast.Node {
+	if n == nil {
+		return nil
+	}
+	if base := result.Mappings.SrcOf(n); base != nil {
+		return base
+	}
+	return n
+}
+
+func actionOf(actions map[*ast.Node][]editscript.Action, n *ast.Node, types ...editscript.ActionType) *editscript.Action {
+	for i, action := range actions[n] {
+		if slices.Contains(types, action.Type) {
+			return &actions[n][i]
+		}
+	}
+	return nil
+}
+
+func (m *merger) conflict(kind ConflictKind, base *ast.Node, left, right *editscript.Action) {
+	m.conflicts = append(m.conflicts, Conflict{Kind: kind, Base: base, Left: left, Right: right})
+}
+
+// resolve decides the content, the parent and the existence of every node.
+func (m *merger) resolve() {
+	for _, id := range m.order {
+		s := m.states[id]
+		m.resolveContent(s)
+		m.resolveParent(s)
+		m.resolveExistence(s)
+	}
+
+	for changed := true; changed; {
+		changed = m.reviveParents() || m.breakCycles()
+	}
+}
+
+func (m *merger) resolveContent(s *state) {
+	switch {
+	case s.left != nil && s.right != nil:
+		leftChanged := s.left.Label != s.base.Label || s.left.Value != s.base.Value
+		rightChanged := s.right.Label != s.base.Label || s.right.Value != s.base.Value
+		if leftChanged && rightChanged && (s.left.Label != s.right.Label || s.left.Value != s.right.Value) {
+			m.conflict(UpdateConflict, s.base, actionOf(m.leftActions, s.base, editscript.Update), actionOf(m.rightActions, s.base, editscript.Update))
+		}
+		if rightChanged && !leftChanged {
+			s.label, s.value = s.right.Label, s.right.Value
+		} else {
+			s.label, s.value = s.left.Label, s.left.Value
+		}
+	case s.left != nil:
+		s.label, s.value = s.left.Label, s.left.Value
+	case s.right != nil:
+		s.label, s.value = s.right.Label, s.right.Value
+	}
+}
+
+func (m *merger) resolveParent(s *state) {
+	if s.left != nil {
+		s.leftParent = identityOf(m.left, s.left.Parent)
+	}
+	if s.right != nil {
+		s.rightParent = identityOf(m.right, s.right.Parent)
+	}
+
+	switch {
+	case s.left != nil && s.right != nil:
+		baseParent := s.base.Parent
+		switch {
+		case s.leftParent == baseParent && s.rightParent != baseParent:
+			s.parent, s.parentFromRight = s.rightParent, true
+		case s.rightParent == baseParent || s.leftParent == s.rightParent:
+			s.parent = s.leftParent
+		default:
+			m.conflict(MoveConflict, s.base, actionOf(m.leftActions, s.base, editscript.Move), actionOf(m.rightActions, s.base, editscript.Move))
+			s.parent = s.leftParent
+		}
+	case s.left != nil:
+		s.parent = s.leftParent
+	case s.right != nil:
+		s.parent, s.parentFromRight = s.rightParent, true
+	}
+}
+
+// resolveExistence keeps the nodes that are kept by both versions, inserted by one of them,
+// or deleted by one version but changed by the other one.
+func (m *merger) resolveExistence(s *state) {
+	if s.base == nil || (s.left != nil && s.right != nil) {
+		s.exists = true
+		return
+	}
+	if s.left == nil && s.right == nil {
+		return
+	}
+
+	kept, keptActions := s.left, m.leftActions
+	if kept == nil {
+		kept, keptActions = s.right, m.rightActions
+	}
+	parent := s.leftParent
+	if s.left == nil {
+		parent = s.rightParent
+	}
+	if kept.Label == s.base.Label && kept.Value == s.base.Value && parent == s.base.Parent {
+		return
+	}
+
+	s.exists = true
+	m.conflictWithDeletion(s.base, actionOf(keptActions, s.base, editscript.Update, editscript.Move))
+}
+
+// conflictWithDeletion reports that one version deletes `deleted` while the other one performs `action` on it or under it.
+func (m *merger) conflictWithDeletion(deleted *ast.Node, action *editscript.Action) {
+	if m.states[deleted].left == nil {
+		m.conflict(DeleteConflict, deleted, actionOf(m.leftActions, deleted, editscript.Delete), action)
+	} else {
+		m.conflict(DeleteConflict, deleted, action, actionOf(m.rightActions, deleted, editscript.Delete))
+	}
+}
+
+// reviveParents keeps the deleted nodes that are the parents of kept nodes,
+// i.e., nodes under which the other version inserts or moves nodes. It returns true if a node is revived.
+func (m *merger) reviveParents() bool {
+	revived := false
+	for _, id := range m.order {
+		s := m.states[id]
+		if !s.exists || s.parent == nil || m.states[s.parent].exists {
+			continue
+		}
+
+		parent := m.states[s.parent]
+		parent.exists = true
+		revived = true
+
+		// A node kept despite being deleted by the same version as its parent is already reported.
+		if s.base != nil && ((parent.left == nil && s.left == nil) || (parent.right == nil && s.right == nil)) {
+			continue
+		}
+		actions := m.leftActions
+		if s.parentFromRight {
+			actions = m.rightActions
+		}
+		m.conflictWithDeletion(s.parent, actionOf(actions, id, editscript.Insert, editscript.Move))
+	}
+	return revived
+}
+
+// breakCycles restores the left parent of nodes whose combined moves make them their own ancestor.
+// As the left and the right trees are both acyclic, every cycle contains a node of the base tree
+// whose parent comes from the right version while the left version has another one.
+// It returns true if a cycle is broken.
+func (m *merger) breakCycles() bool {
+	broken := false
+	for _, id := range m.order {
+		if !m.states[id].exists {
+			continue
+		}
+
+		path := make([]*ast.Node, 0)
+		for n := id; n != nil && !slices.Contains(path, n); n = m.states[n].parent {
+			path = append(path, n)
+		}
+		last := m.states[path[len(path)-1]].parent
+		if last == nil {
+			continue
+		}
+
+		cycle := path[slices.Index(path, last):]
+		for _, n := range cycle {
+			s := m.states[n]
+			if s.parentFromRight && s.left != nil {
+				m.conflict(MoveConflict, s.base, actionOf(m.leftActions, s.base, editscript.Move), actionOf(m.rightActions, s.base, editscript.Move))
+				s.parent, s.parentFromRight = s.leftParent, false
+				broken = true
+				break
+			}
+		}
+	}
+	return broken
+}
+
+// childrenOf returns the identities of the children of `n` that are in `children`,
+// where `identity` gives the identity of a node of the tree of `n`.
+func childrenOf(n *ast.Node, identity func(*ast.Node) *ast.Node, children map[*ast.Node]bool) []*ast.Node {
+	if n == nil {
+		return nil
+	}
+	identities := make([]*ast.Node, 0, n.Degree())
+	for _, child := range n.OrderedChildren() {
+		if id := identity(child); children[id] {
+			identities = append(identities, id)
+		}
+	}
+	return identities
+}
+
+// orderChildren orders the identities in `children`, the children of the merged node `s`,
+// by merging their order in the base, the left and the right trees.
+// The order of a version reordering the common children is preferred, the left one if both do.
+// The other children are placed after the child preceding them in their own tree.
+func (m *merger) orderChildren(s *state, children []*ast.Node) []*ast.Node {
+	isChild := make(map[*ast.Node]bool, len(children))
+	for _, child := range children {
+		isChild[child] = true
+	}
+	base := childrenOf(s.base, func(n *ast.Node) *ast.Node { return n }, isChild)
+	left := childrenOf(s.left, func(n *ast.Node) *ast.Node { return identityOf(m.left, n) }, isChild)
+	right := childrenOf(s.right, func(n *ast.Node) *ast.Node { return identityOf(m.right, n) }, isChild)
+
+	primary, secondary := left, right
+	if sameOrder(left, base) && !sameOrder(right, base) {
+		primary, secondary = right, left
+	}
+
+	ordered := slices.Clone(primary)
+	ordered = insertMissing(ordered, secondary)
+	ordered = insertMissing(ordered, base)
+	return insertMissing(ordered, children)
+}
+
+// sameOrder returns true if the elements common to `a` and `b` appear in the same order in both.
+func sameOrder(a, b []*ast.Node) bool {
+	commonA := slices.DeleteFunc(slices.Clone(a), func(n *ast.Node) bool { return !slices.Contains(b, n) })
+	commonB := slices.DeleteFunc(slices.Clone(b), func(n *ast.Node) bool { return !slices.Contains(a, n) })
+	return slices.Equal(commonA, commonB)
+}
+
+// insertMissing inserts the elements of `sequence` missing from `ordered` after the element preceding them in `sequence`,
+// or at the beginning if none of the preceding elements is in `ordered`.
+func insertMissing(ordered, sequence []*ast.Node) []*ast.Node {
+	for i, n := range sequence {
+		if slices.Contains(ordered, n) {
+			continue
+		}
+
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if k := slices.Index(ordered, sequence[j]); k >= 0 {
+				at = k + 1
+				break
+			}
+		}
+		ordered = slices.Insert(ordered, at, n)
+	}
+	return ordered
+}
+
+// build creates the merged tree from the resolved states,
+// with the origins of its nodes and the mappings from the base tree.
+func (m *merger) build(logger slog.Logger) (ast.AST, map[*ast.Node]*ast.Node, comparator.MappingStore, error) {
+	childrenOfId := make(map[*ast.Node][]*ast.Node)
+	roots := make([]*ast.Node, 0)
+	for _, id := range m.order {
+		s := m.states[id]
+		if !s.exists {
+			continue
+		}
+		if s.parent == nil {
+			roots = append(roots, id)
+		} else {
+			childrenOfId[s.parent] = append(childrenOfId[s.parent], id)
+		}
+	}
+
+	merged := ast.NewAST(logger)
+	origins := make(map[*ast.Node]*ast.Node)
+	mappings := comparator.NewMappingStore()
+	if len(roots) == 0 {
+		return merged, origins, mappings, nil
+	}
+
+	// Both versions may wrap the root in a new node. The right one is then kept under the left one.
+	for _, root := range roots[1:] {
+		m.conflict(MoveConflict, nil, actionOf(m.leftActions, roots[0], editscript.Insert), actionOf(m.rightActions, root, editscript.Insert))
+		childrenOfId[roots[0]] = append(childrenOfId[roots[0]], root)
+	}
+
+	var add func(parent *ast.Node, i int, id *ast.Node) error
+	add = func(parent *ast.Node, i int, id *ast.Node) error {
+		s := m.states[id]
+		n, err := merged.Add(parent, i, s.label, s.value)
+		if err != nil {
+			return err
+		}
+		origins[n] = s.origin()
+		if s.base != nil {
+			mappings.Add(s.base, n)
+		}
+
+		for j, child := range m.orderChildren(s, childrenOfId[id]) {
+			if err := add(n, j, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(nil, -1, roots[0]); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot build the merged tree: %w", err)
+	}
+	return merged, origins, mappings, nil
+}
+
+func newMerger(left, right *diff.Result) *merger {
+	m := &merger{
+		left:         left,
+		right:        right,
+		states:       make(map[*ast.Node]*state),
+		order:        make([]*ast.Node, 0),
+		leftActions:  make(map[*ast.Node][]editscript.Action),
+		rightActions: make(map[*ast.Node][]editscript.Action),
+	}
+
+	for _, b := range left.Src.PreOrderNodes() {
+		m.states[b] = &state{base: b, left: left.Mappings.DstOf(b), right: right.Mappings.DstOf(b)}
+		m.order = append(m.order, b)
+	}
+	for _, l := range left.Dst.PreOrderNodes() {
+		if !left.Mappings.IsDstMapped(l) {
+			m.states[l] = &state{left: l}
+			m.order = append(m.order, l)
+		}
+	}
+	for _, r := range right.Dst.PreOrderNodes() {
+		if !right.Mappings.IsDstMapped(r) {
+			m.states[r] = &state{right: r}
+			m.order = append(m.order, r)
+		}
+	}
+
+	for _, action := range left.Script {
+		m.leftActions[action.Node] = append(m.leftActions[action.Node], action)
+	}
+	for _, action := range right.Script {
+		m.rightActions[action.Node] = append(m.rightActions[action.Node], action)
+	}
+	return m
+}
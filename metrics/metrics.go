@@ -0,0 +1,156 @@
+// Package metrics measures the structural differences computed by the diff package,
+// e.g. to track the size of the changes of a project over time.
+package metrics
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+)
+
+// CostModel weighs the actions of edit scripts.
+type CostModel struct {
+	// ActionCosts are the costs of the actions of each type. Missing types cost 1.
+	ActionCosts map[editscript.ActionType]float64
+
+	// LabelCosts are the costs of the actions of each type on the nodes with a given label, overriding ActionCosts.
+	LabelCosts map[ast.NodeLabelType]map[editscript.ActionType]float64
+}
+
+// NewCostModel creates a CostModel where every action costs 1, i.e. the cost of a script is its length.
+func NewCostModel() CostModel {
+	return CostModel{
+		ActionCosts: make(map[editscript.ActionType]float64),
+		LabelCosts:  make(map[ast.NodeLabelType]map[editscript.ActionType]float64),
+	}
+}
+
+// CostOf returns the cost of `action`.
+// A TreeInsert or TreeDelete costs the inserts or deletes of all the nodes of its subtree,
+// so that a script and its simplified version have the same cost.
+func (m CostModel) CostOf(action editscript.Action) float64 {
+	switch action.Type {
+	case editscript.TreeInsert, editscript.TreeDelete:
+		nodeType := editscript.Insert
+		if action.Type == editscript.TreeDelete {
+			nodeType = editscript.Delete
+		}
+		cost := 0.0
+		for _, n := range preOrderOf(action.Node) {
+			cost += m.costOf(nodeType, n.Label)
+		}
+		return cost
+	default:
+		return m.costOf(action.Type, action.Node.Label)
+	}
+}
+
+// Cost returns the total cost of the actions of `script`.
+func (m CostModel) Cost(script editscript.Script) float64 {
+	cost := 0.0
+	for _, action := range script {
+		cost += m.CostOf(action)
+	}
+	return cost
+}
+
+func (m CostModel) costOf(t editscript.ActionType, label ast.NodeLabelType) float64 {
+	if cost, ok := m.LabelCosts[label][t]; ok {
+		return cost
+	}
+	if cost, ok := m.ActionCosts[t]; ok {
+		return cost
+	}
+	return 1
+}
+
+// TreeStats counts the nodes of one of the compared trees.
+type TreeStats struct {
+	Nodes    int `json:"nodes"`
+	Mapped   int `json:"mapped"`
+	Unmapped int `json:"unmapped"`
+}
+
+// Stats are the metrics of a structural diff.
+type Stats struct {
+	// ScriptLength is the number of actions of the edit script,
+	// and SimplifiedLength the number of actions of its simplified version, see editscript.Simplify.
+	ScriptLength     int `json:"scriptLength"`
+	SimplifiedLength int `json:"simplifiedLength"`
+
+	// Actions is the number of actions of the edit script of each type, e.g. `insert`.
+	Actions map[string]int `json:"actions"`
+
+	// Cost is the cost of the edit script in the cost model the stats were computed with.
+	Cost float64 `json:"cost"`
+
+	Src TreeStats `json:"src"`
+	Dst TreeStats `json:"dst"`
+
+	// Similarity is the ratio of the nodes of both trees that are mapped, see diff.Similarity.
+	Similarity float64 `json:"similarity"`
+}
+
+// Of computes the metrics of `result`, weighing its edit script with `model`.
+func Of(result *diff.Result, model CostModel) Stats {
+	actions := make(map[string]int)
+	for _, action := range result.Script {
+		actions[action.Type.String()]++
+	}
+
+	return Stats{
+		ScriptLength:     len(result.Script),
+		SimplifiedLength: len(editscript.Simplify(result.Script)),
+		Actions:          actions,
+		Cost:             model.Cost(result.Script),
+		Src:              treeStatsOf(result.Src, result.Mappings.IsSrcMapped),
+		Dst:              treeStatsOf(result.Dst, result.Mappings.IsDstMapped),
+		Similarity:       result.Similarity(),
+	}
+}
+
+// Sum returns the metrics of several diffs taken together, e.g. the files of a directory.
+// The similarity is the ratio of all their nodes that are mapped.
+func Sum(stats ...Stats) Stats {
+	total := Stats{Actions: make(map[string]int), Similarity: 1}
+	for _, s := range stats {
+		total.ScriptLength += s.ScriptLength
+		total.SimplifiedLength += s.SimplifiedLength
+		for t, count := range s.Actions {
+			total.Actions[t] += count
+		}
+		total.Cost += s.Cost
+		total.Src = total.Src.add(s.Src)
+		total.Dst = total.Dst.add(s.Dst)
+	}
+
+	if nodes := total.Src.Nodes + total.Dst.Nodes; nodes > 0 {
+		total.Similarity = float64(total.Src.Mapped+total.Dst.Mapped) / float64(nodes)
+	}
+	return total
+}
+
+func (s TreeStats) add(other TreeStats) TreeStats {
+	return TreeStats{Nodes: s.Nodes + other.Nodes, Mapped: s.Mapped + other.Mapped, Unmapped: s.Unmapped + other.Unmapped}
+}
+
+func treeStatsOf(tree ast.AST, isMapped func(n *ast.Node) bool) TreeStats {
+	stats := TreeStats{}
+	for _, n := range tree.PreOrderNodes() {
+		stats.Nodes++
+		if isMapped(n) {
+			stats.Mapped++
+		} else {
+			stats.Unmapped++
+		}
+	}
+	return stats
+}
+
+func preOrderOf(n *ast.Node) []*ast.Node {
+	result := []*ast.Node{n}
+	for _, child := range n.OrderedChildren() {
+		result = append(result, preOrderOf(child)...)
+	}
+	return result
+}
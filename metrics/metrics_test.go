@@ -0,0 +1,105 @@
+package metrics_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/internal/asttest"
+	"github.com/Xanonymous-GitHub/gumtree-go/metrics"
+	"testing"
+)
+
+// newResult compares two trees where the nodes `r`, `a` and `b` are mapped:
+// `a` is updated, `b` is moved, `c` is deleted and the block `d` is inserted.
+func newResult() *diff.Result {
+	src := asttest.NewBuilder().
+		Add("", "r", "root", "").
+		Add("r", "a", "Ident", "a").
+		Add("r", "c", "Comment", "c").
+		Add("r", "x", "block", "").
+		Add("x", "b", "Ident", "b")
+	dst := asttest.NewBuilder().
+		Add("", "r", "root", "").
+		Add("r", "b", "Ident", "b").
+		Add("r", "a", "Ident", "a2").
+		Add("r", "d", "block", "").
+		Add("d", "e", "Ident", "e").
+		Add("d", "f", "Ident", "f")
+
+	mappings := comparator.NewMappingStore()
+	for _, name := range []string{"r", "a", "b"} {
+		mappings.Add(src.Nodes[name], dst.Nodes[name])
+	}
+	return &diff.Result{
+		Src:      src.Tree,
+		Dst:      dst.Tree,
+		Mappings: mappings,
+		Script:   editscript.Generate(src.Tree, dst.Tree, mappings),
+	}
+}
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test the metrics of a diff", func(t *testing.T) {
+		result := newResult()
+		stats := metrics.Of(result, metrics.NewCostModel())
+
+		if stats.ScriptLength != len(result.Script) || stats.Cost != float64(len(result.Script)) {
+			t.Errorf("Expected a script of length and cost %d, got %d and %f", len(result.Script), stats.ScriptLength, stats.Cost)
+		}
+		expectedActions := map[string]int{"insert": 3, "delete": 2, "update": 1, "move": 1}
+		for name, count := range expectedActions {
+			if stats.Actions[name] != count {
+				t.Errorf("Expected %d %s actions, got %d", count, name, stats.Actions[name])
+			}
+		}
+		if stats.SimplifiedLength != stats.ScriptLength-2 {
+			t.Errorf("Expected the inserted block to take one action, got %d actions", stats.SimplifiedLength)
+		}
+		if stats.Src != (metrics.TreeStats{Nodes: 5, Mapped: 3, Unmapped: 2}) {
+			t.Errorf("Expected 5 source nodes with 3 mapped, got %+v", stats.Src)
+		}
+		if stats.Dst != (metrics.TreeStats{Nodes: 6, Mapped: 3, Unmapped: 3}) {
+			t.Errorf("Expected 6 destination nodes with 3 mapped, got %+v", stats.Dst)
+		}
+		if stats.Similarity != 6.0/11 {
+			t.Errorf("Expected a similarity of %f, got %f", 6.0/11, stats.Similarity)
+		}
+	})
+
+	t.Run("Test the cost model", func(t *testing.T) {
+		result := newResult()
+		model := metrics.NewCostModel()
+		model.ActionCosts[editscript.Insert] = 2
+		model.ActionCosts[editscript.Move] = 0.5
+		model.LabelCosts["Comment"] = map[editscript.ActionType]float64{editscript.Delete: 0}
+
+		// 3 inserts, 1 delete of the block and none of the comment, 1 update and 1 move.
+		if cost := metrics.Of(result, model).Cost; cost != 8.5 {
+			t.Errorf("Expected a cost of 8.5, got %f", cost)
+		}
+		if cost := model.Cost(editscript.Simplify(result.Script)); cost != 8.5 {
+			t.Errorf("Expected the simplified script to cost 8.5, got %f", cost)
+		}
+	})
+}
+
+func TestSum(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test the metrics of several diffs", func(t *testing.T) {
+		stats := metrics.Of(newResult(), metrics.NewCostModel())
+		total := metrics.Sum(stats, stats)
+
+		if total.ScriptLength != 2*stats.ScriptLength || total.Actions["insert"] != 6 || total.Src.Nodes != 10 {
+			t.Errorf("Expected the metrics to be added, got %+v", total)
+		}
+		if total.Similarity != stats.Similarity {
+			t.Errorf("Expected a similarity of %f, got %f", stats.Similarity, total.Similarity)
+		}
+		if empty := metrics.Sum(); empty.Similarity != 1 {
+			t.Errorf("Expected no diffs to be identical, got a similarity of %f", empty.Similarity)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package render
+
+import "slices"
+
+// maxEditDistance bounds the work of the line alignment.
+// Beyond it, the differing middle parts of the files are shown as replaced as a whole.
+const maxEditDistance = 2000
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// lineOp is one step of the alignment of the lines of two files.
+// `src` is the index of the line in the source file for opEqual and opDelete,
+// and `dst` is the index of the line in the destination file for opEqual and opInsert.
+type lineOp struct {
+	kind     opKind
+	src, dst int
+}
+
+// alignLines computes a shortest sequence of operations turning `n` source lines into `m` destination lines,
+// where `equal` tells whether two lines may be kept as they are.
+// It uses the algorithm of Myers, "An O(ND) difference algorithm and its variations".
+func alignLines(n, m int, equal func(i, j int) bool) []lineOp {
+	ops := make([]lineOp, 0, max(n, m))
+
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		ops = append(ops, lineOp{kind: opEqual, src: prefix, dst: prefix})
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	middle, ok := myers(prefix, n-suffix, prefix, m-suffix, equal)
+	if !ok {
+		middle = middle[:0]
+		for i := prefix; i < n-suffix; i++ {
+			middle = append(middle, lineOp{kind: opDelete, src: i})
+		}
+		for j := prefix; j < m-suffix; j++ {
+			middle = append(middle, lineOp{kind: opInsert, dst: j})
+		}
+	}
+	ops = append(ops, middle...)
+
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, lineOp{kind: opEqual, src: n - i, dst: m - i})
+	}
+	return ops
+}
+
+// myers aligns the source lines [srcStart, srcEnd) with the destination lines [dstStart, dstEnd).
+// It gives up and returns false when the edit distance exceeds maxEditDistance.
+func myers(srcStart, srcEnd, dstStart, dstEnd int, equal func(i, j int) bool) ([]lineOp, bool) {
+	n, m := srcEnd-srcStart, dstEnd-dstStart
+	limit := min(n+m, maxEditDistance)
+
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds the furthest reaching x of the diagonals [-d, d] before the step d.
+	trace := make([][]int, 0)
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && equal(srcStart+x, dstStart+y) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, srcStart, dstStart), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func backtrack(trace [][]int, n, m, srcStart, dstStart int) []lineOp {
+	ops := make([]lineOp, 0, n+m)
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		if d == 0 {
+			for x > 0 && y > 0 {
+				x--
+				y--
+				ops = append(ops, lineOp{kind: opEqual, src: srcStart + x, dst: dstStart + y})
+			}
+			break
+		}
+
+		vd := trace[d]
+		at := func(k int) int { return vd[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, lineOp{kind: opEqual, src: srcStart + x, dst: dstStart + y})
+		}
+		if x == prevX {
+			ops = append(ops, lineOp{kind: opInsert, dst: dstStart + prevY})
+		} else {
+			ops = append(ops, lineOp{kind: opDelete, src: srcStart + prevX})
+		}
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(ops)
+	return ops
+}
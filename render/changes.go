@@ -0,0 +1,41 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/changes"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"io"
+)
+
+// ChangesRenderer renders the changes of a diff result, see diff.Result.Changes, one change per line,
+// prefixed by the lines of its root in the source and the destination files, e.g. `-12 +14  method renamed: Area → Size`.
+type ChangesRenderer struct{}
+
+// Render writes the changes of `result` to `w`.
+func (r *ChangesRenderer) Render(w io.Writer, result *diff.Result) error {
+	srcLines, dstLines := newSourceLines(result.SrcContent), newSourceLines(result.DstContent)
+	out := bufio.NewWriter(w)
+
+	for _, change := range result.Changes() {
+		var src, dst *ast.Node
+		switch change.Kind {
+		case changes.Added, changes.Wrapped:
+			dst = change.Node
+		case changes.Removed, changes.Unwrapped:
+			src = change.Node
+		default:
+			src, dst = change.Node, result.Mappings.DstOf(change.Node)
+		}
+
+		_, _ = fmt.Fprintf(out, "%-6s %-6s %s\n", locationOf("-", src, srcLines), locationOf("+", dst, dstLines), change.String())
+	}
+
+	return out.Flush()
+}
+
+// NewChangesRenderer creates a ChangesRenderer.
+func NewChangesRenderer() *ChangesRenderer {
+	return &ChangesRenderer{}
+}
@@ -0,0 +1,259 @@
+package render
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// HTMLRenderer renders a diff result as a self-contained HTML page showing both files side by side.
+// Inserted, deleted, updated and moved nodes are colored, and clicking on a mapped node jumps to its partner.
+// The page embeds its styles and scripts, so that it can be opened without any network access, e.g. as a CI artifact.
+type HTMLRenderer struct {
+	// Title is the title of the page. The paths of the files are used if it is empty.
+	Title string
+}
+
+// Render writes the HTML page of `result` to `w`.
+func (r *HTMLRenderer) Render(w io.Writer, result *diff.Result) error {
+	return htmlPageTemplate.Execute(w, r.pageOf(result))
+}
+
+// RenderFragment writes the side-by-side view of `result` without the surrounding page,
+// to be embedded in a page that includes HTMLStyles and HTMLScript.
+func (r *HTMLRenderer) RenderFragment(w io.Writer, result *diff.Result) error {
+	return htmlPageTemplate.ExecuteTemplate(w, "fragment", r.pageOf(result))
+}
+
+type htmlPage struct {
+	Title            string
+	SrcPath, DstPath string
+	SrcCode, DstCode template.HTML
+	SrcLines         template.HTML
+	DstLines         template.HTML
+	Inserts          int
+	Deletes          int
+	Updates          int
+	Moves            int
+	Styles           template.CSS
+	Script           template.JS
+}
+
+func (r *HTMLRenderer) pageOf(result *diff.Result) *htmlPage {
+	title := r.Title
+	if title == "" {
+		title = fmt.Sprintf("%s → %s", displayPath(result.SrcPath, "a"), displayPath(result.DstPath, "b"))
+	}
+
+	srcSpans, dstSpans := spansOf(result)
+	// A subtree inserted or deleted as a whole by a simplified script counts once, see editscript.Simplify.
+	return &htmlPage{
+		Title:    title,
+		SrcPath:  displayPath(result.SrcPath, "a"),
+		DstPath:  displayPath(result.DstPath, "b"),
+		SrcCode:  highlight(result.SrcContent, srcSpans),
+		DstCode:  highlight(result.DstContent, dstSpans),
+		SrcLines: lineNumbers(result.SrcContent),
+		DstLines: lineNumbers(result.DstContent),
+		Inserts:  result.Script.CountOf(editscript.Insert) + result.Script.CountOf(editscript.TreeInsert),
+		Deletes:  result.Script.CountOf(editscript.Delete) + result.Script.CountOf(editscript.TreeDelete),
+		Updates:  result.Script.CountOf(editscript.Update),
+		Moves:    result.Script.CountOf(editscript.Move),
+		Styles:   template.CSS(HTMLStyles),
+		Script:   template.JS(HTMLScript),
+	}
+}
+
+// span is a decorated range of a source text.
+type span struct {
+	start, end int
+	class      string
+	id         string
+	partner    string
+	title      string
+}
+
+// spansOf computes the decorated ranges of both files of `result`:
+// one per mapped node, carrying the id of its partner, and one per inserted, deleted, updated or moved node.
+func spansOf(result *diff.Result) (src, dst []span) {
+	src = make([]span, 0)
+	dst = make([]span, 0)
+
+	for i, pair := range result.Mappings.Pairs() {
+		srcId, dstId := fmt.Sprintf("s%d", i), fmt.Sprintf("d%d", i)
+		src = appendSpan(src, pair.Left(), span{class: "mapped", id: srcId, partner: dstId})
+		dst = appendSpan(dst, pair.Right(), span{class: "mapped", id: dstId, partner: srcId})
+	}
+
+	for _, action := range result.Script {
+		switch action.Type {
+		case editscript.Insert, editscript.TreeInsert:
+			dst = appendSpan(dst, action.Node, span{class: "ins", title: "inserted " + string(action.Node.Label)})
+		case editscript.Delete, editscript.TreeDelete:
+			src = appendSpan(src, action.Node, span{class: "del", title: "deleted " + string(action.Node.Label)})
+		case editscript.Update:
+			title := "updated " + describeUpdate(action)
+			src = appendSpan(src, action.Node, span{class: "upd", title: title})
+			dst = appendSpan(dst, result.Mappings.DstOf(action.Node), span{class: "upd", title: title})
+		case editscript.Move:
+			partner := result.Mappings.DstOf(action.Node)
+			src = appendSpan(src, action.Node, span{class: "mv", title: "moved " + string(action.Node.Label)})
+			dst = appendSpan(dst, partner, span{class: "mv", title: "moved " + string(action.Node.Label)})
+		}
+	}
+
+	return src, dst
+}
+
+func appendSpan(spans []span, n *ast.Node, s span) []span {
+	if n == nil || !n.Pos.IsValid() || n.Pos.Start == n.Pos.End {
+		return spans
+	}
+	s.start, s.end = n.Pos.Start, n.Pos.End
+	return append(spans, s)
+}
+
+// highlight escapes `content` and wraps the decorated ranges into spans.
+// Ranges partially overlapping an enclosing range are clipped to it, so that the spans are well nested.
+func highlight(content []byte, spans []span) template.HTML {
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var b strings.Builder
+	open := make([]span, 0)
+	cursor := 0
+
+	closeUntil := func(offset int) {
+		for len(open) > 0 && open[len(open)-1].end <= offset {
+			top := open[len(open)-1]
+			b.WriteString(template.HTMLEscapeString(string(content[cursor:top.end])))
+			b.WriteString("</span>")
+			cursor = top.end
+			open = open[:len(open)-1]
+		}
+	}
+
+	for _, s := range spans {
+		s.start, s.end = min(s.start, len(content)), min(s.end, len(content))
+		closeUntil(s.start)
+		if len(open) > 0 {
+			s.end = min(s.end, open[len(open)-1].end)
+		}
+		if s.start >= s.end {
+			continue
+		}
+
+		b.WriteString(template.HTMLEscapeString(string(content[cursor:s.start])))
+		cursor = s.start
+		b.WriteString(openingTagOf(s))
+		open = append(open, s)
+	}
+	closeUntil(len(content))
+	b.WriteString(template.HTMLEscapeString(string(content[cursor:])))
+
+	return template.HTML(b.String())
+}
+
+func openingTagOf(s span) string {
+	var b strings.Builder
+	b.WriteString(`<span class="`)
+	b.WriteString(s.class)
+	b.WriteString(`"`)
+	if s.id != "" {
+		_, _ = fmt.Fprintf(&b, ` id="%s" data-partner="%s"`, s.id, s.partner)
+	}
+	if s.title != "" {
+		_, _ = fmt.Fprintf(&b, ` title="%s"`, template.HTMLEscapeString(s.title))
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+func lineNumbers(content []byte) template.HTML {
+	var b strings.Builder
+	for i := 1; i <= newSourceLines(content).count(); i++ {
+		_, _ = fmt.Fprintf(&b, "%d\n", i)
+	}
+	return template.HTML(b.String())
+}
+
+// NewHTMLRenderer creates an HTMLRenderer titling pages with the paths of the files.
+func NewHTMLRenderer() *HTMLRenderer {
+	return &HTMLRenderer{}
+}
+
+// HTMLStyles are the styles of the side-by-side view.
+const HTMLStyles = `
+body { margin: 0; font-family: sans-serif; }
+header { padding: 0.5em 1em; border-bottom: 1px solid #ccc; }
+header h1 { font-size: 1.1em; margin: 0 0 0.3em 0; }
+.legend span { padding: 0 0.4em; margin-right: 0.5em; border-radius: 3px; }
+.sides { display: flex; height: calc(100vh - 4.5em); }
+.side { flex: 1; overflow: auto; border-right: 1px solid #ccc; }
+.side h2 { font-size: 0.9em; margin: 0; padding: 0.3em 0.5em; background: #f4f4f4; position: sticky; top: 0; }
+.code { display: flex; }
+.code pre { margin: 0; padding: 0.3em 0.5em; font-size: 12px; line-height: 1.4; }
+.code pre.lines { color: #999; text-align: right; user-select: none; border-right: 1px solid #eee; }
+.mapped { cursor: pointer; }
+.ins { background: #c8f0c8; }
+.del { background: #f6c6c6; }
+.upd { background: #f8e2a0; }
+.mv { background: #c6d8f6; }
+.focused { outline: 2px solid #e08000; }
+`
+
+// HTMLScript makes mapped nodes jump to their partner when clicked.
+const HTMLScript = `
+document.addEventListener("click", function (event) {
+  var node = event.target.closest("[data-partner]");
+  if (!node) { return; }
+  var partner = document.getElementById(node.dataset.partner);
+  if (!partner) { return; }
+  document.querySelectorAll(".focused").forEach(function (n) { n.classList.remove("focused"); });
+  node.classList.add("focused");
+  partner.classList.add("focused");
+  partner.scrollIntoView({block: "center", inline: "nearest"});
+});
+`
+
+var htmlPageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>{{.Styles}}</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<div class="legend">
+<span class="ins">{{.Inserts}} inserted</span>
+<span class="del">{{.Deletes}} deleted</span>
+<span class="upd">{{.Updates}} updated</span>
+<span class="mv">{{.Moves}} moved</span>
+</div>
+</header>
+{{template "fragment" .}}
+<script>{{.Script}}</script>
+</body>
+</html>
+{{define "fragment"}}<div class="sides">
+<div class="side">
+<h2>{{.SrcPath}}</h2>
+<div class="code"><pre class="lines">{{.SrcLines}}</pre><pre>{{.SrcCode}}</pre></div>
+</div>
+<div class="side">
+<h2>{{.DstPath}}</h2>
+<div class="code"><pre class="lines">{{.DstLines}}</pre><pre>{{.DstCode}}</pre></div>
+</div>
+</div>{{end}}
+`))
@@ -0,0 +1,82 @@
+package render_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/render"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHTMLRenderer(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("package p\n\nfunc a() int { return 1 }\n\nfunc b() {}\n")
+	dst := []byte("package p\n\nfunc b() {}\n\nfunc a() int { return 2 < 3 }\n")
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	result, err := diff.Contents("a.go", src, "b.go", dst, diff.DefaultOptions(*logger))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := render.NewHTMLRenderer().Render(&out, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page := out.String()
+
+	t.Run("Test the page is self-contained", func(t *testing.T) {
+		for _, external := range []string{"<link", "src=", "http://", "https://"} {
+			if strings.Contains(page, external) {
+				t.Errorf("Expected no reference to external assets, got %q", external)
+			}
+		}
+	})
+
+	t.Run("Test the sources are escaped", func(t *testing.T) {
+		if strings.Contains(page, "2 < 3") || !strings.Contains(page, "&lt;") {
+			t.Errorf("Expected `<` to be escaped")
+		}
+	})
+
+	t.Run("Test the spans are balanced", func(t *testing.T) {
+		opening, closing := strings.Count(page, "<span"), strings.Count(page, "</span>")
+		if opening != closing {
+			t.Errorf("Expected as many opening as closing spans, got %d and %d", opening, closing)
+		}
+	})
+
+	t.Run("Test mapped nodes link to their partner", func(t *testing.T) {
+		if !strings.Contains(page, `id="s0" data-partner="d0"`) || !strings.Contains(page, `id="d0" data-partner="s0"`) {
+			t.Errorf("Expected the first mapped pair to link both ways")
+		}
+	})
+
+	t.Run("Test changes are colored", func(t *testing.T) {
+		if !strings.Contains(page, `class="ins"`) {
+			t.Errorf("Expected inserted nodes to be colored")
+		}
+	})
+
+	t.Run("Test the legend counts the tree actions of simplified scripts", func(t *testing.T) {
+		simplified := *result
+		simplified.Script = editscript.Simplify(result.Script)
+		trees := simplified.Script.CountOf(editscript.TreeInsert)
+		if trees == 0 {
+			t.Fatalf("Expected an inserted subtree, got %v", simplified.Script)
+		}
+
+		var out bytes.Buffer
+		if err := render.NewHTMLRenderer().Render(&out, &simplified); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		inserts := simplified.Script.CountOf(editscript.Insert) + trees
+		if legend := fmt.Sprintf(`<span class="ins">%d inserted</span>`, inserts); !strings.Contains(out.String(), legend) {
+			t.Errorf("Expected the legend %q, got %s", legend, out.String())
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Renderer writes a diff result in a given format.
+type Renderer interface {
+	Render(w io.Writer, result *diff.Result) error
+}
+
+// maxAnnotatedValueLen is the length beyond which values are shortened in annotations.
+const maxAnnotatedValueLen = 40
+
+// sourceLines indexes the lines of a source text.
+type sourceLines struct {
+	content []byte
+
+	// starts holds the offset of the first byte of each line.
+	starts []int
+}
+
+func newSourceLines(content []byte) *sourceLines {
+	starts := []int{0}
+	for i, c := range content {
+		if c == '\n' && i+1 < len(content) {
+			starts = append(starts, i+1)
+		}
+	}
+	if len(content) == 0 {
+		starts = starts[:0]
+	}
+	return &sourceLines{content: content, starts: starts}
+}
+
+func (l *sourceLines) count() int {
+	return len(l.starts)
+}
+
+// lineOf returns the index of the line containing the byte at `offset`.
+func (l *sourceLines) lineOf(offset int) int {
+	return sort.Search(len(l.starts), func(i int) bool { return l.starts[i] > offset }) - 1
+}
+
+// text returns the content of the line `i` without its line break.
+func (l *sourceLines) text(i int) string {
+	end := len(l.content)
+	if i+1 < len(l.starts) {
+		end = l.starts[i+1]
+	}
+	return strings.TrimRight(string(l.content[l.starts[i]:end]), "\r\n")
+}
+
+// sideChanges describes how the lines of one of the compared files are affected by the edit script.
+type sideChanges struct {
+	lines *sourceLines
+
+	// changed tells whether each line holds text of an inserted, deleted, updated or moved node.
+	changed []bool
+
+	// notes are the annotations of each line, describing the moves and updates of the nodes starting on it.
+	notes [][]note
+}
+
+type note struct {
+	kind editscript.ActionType
+	text string
+}
+
+func newSideChanges(content []byte) *sideChanges {
+	lines := newSourceLines(content)
+	return &sideChanges{
+		lines:   lines,
+		changed: make([]bool, lines.count()),
+		notes:   make([][]note, lines.count()),
+	}
+}
+
+// markRange marks the lines holding non-blank text in content[start:end].
+func (s *sideChanges) markRange(start, end int) {
+	start, end = max(start, 0), min(end, len(s.lines.content))
+	for i := start; i < end; i++ {
+		if !unicode.IsSpace(rune(s.lines.content[i])) {
+			s.changed[s.lines.lineOf(i)] = true
+		}
+	}
+}
+
+// markSubtree marks all the lines of the text of `n`.
+func (s *sideChanges) markSubtree(n *ast.Node) {
+	if n != nil && n.Pos.IsValid() {
+		s.markRange(n.Pos.Start, n.Pos.End)
+	}
+}
+
+// markOwnText marks the lines holding the text of `n` that does not belong to its children,
+// e.g. the keywords and punctuation of a statement, or the whole text of a leaf.
+func (s *sideChanges) markOwnText(n *ast.Node) {
+	if n == nil || !n.Pos.IsValid() {
+		return
+	}
+
+	children := make([]*ast.Node, 0, n.Degree())
+	for _, child := range n.OrderedChildren() {
+		if child.Pos.IsValid() {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Pos.Start < children[j].Pos.Start })
+
+	cursor := n.Pos.Start
+	for _, child := range children {
+		s.markRange(cursor, child.Pos.Start)
+		cursor = max(cursor, child.Pos.End)
+	}
+	s.markRange(cursor, n.Pos.End)
+}
+
+// annotate attaches a note to the line where `n` starts.
+func (s *sideChanges) annotate(n *ast.Node, kind editscript.ActionType, text string) {
+	if n == nil || !n.Pos.IsValid() || n.Pos.Start >= len(s.lines.content) {
+		return
+	}
+	line := s.lines.lineOf(n.Pos.Start)
+	s.notes[line] = append(s.notes[line], note{kind: kind, text: text})
+}
+
+// lineNumberOf returns the 1-based number of the line where `n` starts.
+func (s *sideChanges) lineNumberOf(n *ast.Node) int {
+	return s.lines.lineOf(n.Pos.Start) + 1
+}
+
+// changesOf computes how the lines of both files of `result` are affected by its edit script.
+func changesOf(result *diff.Result) (src, dst *sideChanges) {
+	src = newSideChanges(result.SrcContent)
+	dst = newSideChanges(result.DstContent)
+
+	for _, action := range result.Script {
+		switch action.Type {
+		case editscript.Insert:
+			dst.markOwnText(action.Node)
+		case editscript.Delete:
+			src.markOwnText(action.Node)
+		case editscript.TreeInsert:
+			dst.markSubtree(action.Node)
+		case editscript.TreeDelete:
+			src.markSubtree(action.Node)
+		case editscript.Update:
+			partner := result.Mappings.DstOf(action.Node)
+			src.markOwnText(action.Node)
+			dst.markOwnText(partner)
+			dst.annotate(partner, editscript.Update, fmt.Sprintf("[UPD %s]", describeUpdate(action)))
+		case editscript.Move:
+			partner := result.Mappings.DstOf(action.Node)
+			if partner == nil || !action.Node.Pos.IsValid() || !partner.Pos.IsValid() {
+				continue
+			}
+			src.markSubtree(action.Node)
+			dst.markSubtree(partner)
+			src.annotate(action.Node, editscript.Move, fmt.Sprintf("[MOVE to L%d]", dst.lineNumberOf(partner)))
+			dst.annotate(partner, editscript.Move, fmt.Sprintf("[MOVE from L%d]", src.lineNumberOf(action.Node)))
+		}
+	}
+
+	return src, dst
+}
+
+// describeUpdate returns the old and new values of an updated node, e.g. `foo→bar`.
+func describeUpdate(action editscript.Action) string {
+	if action.Node.Label != action.Label {
+		return fmt.Sprintf("%s→%s", shorten(describeLabelled(action.Node.Label, action.Node.Value)), shorten(describeLabelled(action.Label, action.Value)))
+	}
+	return fmt.Sprintf("%s→%s", shorten(string(action.Node.Value)), shorten(string(action.Value)))
+}
+
+func describeLabelled(label ast.NodeLabelType, value ast.NodeValueType) string {
+	if value == "" {
+		return string(label)
+	}
+	return fmt.Sprintf("%s:%s", label, value)
+}
+
+// shorten makes a value fit on a single line of a reasonable length.
+func shorten(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > maxAnnotatedValueLen {
+		return string(runes[:maxAnnotatedValueLen-1]) + "…"
+	}
+	return value
+}
+
+// hasChanges reports whether any line of any side is changed.
+func hasChanges(src, dst *sideChanges) bool {
+	return slices.Contains(src.changed, true) || slices.Contains(dst.changed, true)
+}
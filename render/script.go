@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"io"
+)
+
+// ScriptRenderer renders the edit script of a diff result, one action per line,
+// prefixed by the lines of the affected node in the source and the destination files, e.g. `-12 +14  move ...`.
+type ScriptRenderer struct {
+	// Simplified renders the simplified script, where whole inserted and deleted subtrees take one line, see editscript.Simplify.
+	Simplified bool
+}
+
+// Render writes the edit script of `result` to `w`.
+func (r *ScriptRenderer) Render(w io.Writer, result *diff.Result) error {
+	srcLines, dstLines := newSourceLines(result.SrcContent), newSourceLines(result.DstContent)
+	out := bufio.NewWriter(w)
+
+	script := result.Script
+	if r.Simplified {
+		script = editscript.Simplify(script)
+	}
+	for _, action := range script {
+		var src, dst *ast.Node
+		switch action.Type {
+		case editscript.Insert, editscript.TreeInsert:
+			dst = action.Node
+		case editscript.Delete, editscript.TreeDelete:
+			src = action.Node
+		default:
+			src, dst = action.Node, result.Mappings.DstOf(action.Node)
+		}
+
+		_, _ = fmt.Fprintf(out, "%-6s %-6s %s\n", locationOf("-", src, srcLines), locationOf("+", dst, dstLines), action.String())
+	}
+
+	return out.Flush()
+}
+
+// locationOf returns the line of `n` prefixed by `side`, or an empty string if it is unknown.
+func locationOf(side string, n *ast.Node, lines *sourceLines) string {
+	if n == nil || !n.Pos.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%s%d", side, lines.lineOf(n.Pos.Start)+1)
+}
+
+// NewScriptRenderer creates a ScriptRenderer of simplified scripts.
+func NewScriptRenderer() *ScriptRenderer {
+	return &ScriptRenderer{Simplified: true}
+}
@@ -0,0 +1,202 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"io"
+	"strings"
+)
+
+// DefaultContext is the default number of unchanged lines shown around changes.
+const DefaultContext = 3
+
+// ANSI escape sequences used by the TextRenderer.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// TextRenderer renders a diff result as a unified diff of the source texts,
+// where the lines holding moved and updated nodes are annotated with the structural actions,
+// e.g. `[MOVE from L12]` or `[UPD foo→bar]`.
+//
+// Unlike a textual diff, lines are only kept as unchanged when the edit script leaves their nodes untouched,
+// so that a moved block shows up as deleted at its old place and inserted at its new place, even if a textual diff could align it.
+type TextRenderer struct {
+	// Context is the number of unchanged lines shown around changes.
+	Context int
+
+	// Color enables ANSI colors, e.g. when the output is a terminal.
+	Color bool
+}
+
+// Render writes the annotated unified diff of `result` to `w`.
+// Nothing is written if the edit script is empty.
+func (r *TextRenderer) Render(w io.Writer, result *diff.Result) error {
+	src, dst := changesOf(result)
+	if !hasChanges(src, dst) {
+		return nil
+	}
+
+	ops := alignLines(src.lines.count(), dst.lines.count(), func(i, j int) bool {
+		return !src.changed[i] && !dst.changed[j] && src.lines.text(i) == dst.lines.text(j)
+	})
+
+	out := bufio.NewWriter(w)
+	r.writeLine(out, ansiBold, "--- "+displayPath(result.SrcPath, "a"))
+	r.writeLine(out, ansiBold, "+++ "+displayPath(result.DstPath, "b"))
+
+	for _, h := range hunksOf(ops, max(r.Context, 0)) {
+		r.writeHunk(out, h, src, dst)
+	}
+
+	return out.Flush()
+}
+
+// hunk is a range [start, end) of alignment operations shown together.
+type hunk struct {
+	ops []lineOp
+
+	// srcStart and dstStart are the indices of the first source and destination lines of the hunk.
+	srcStart, dstStart int
+}
+
+// hunksOf groups the changed operations with `context` unchanged operations around them.
+// Changes separated by at most 2*context unchanged lines share the same hunk.
+func hunksOf(ops []lineOp, context int) []hunk {
+	hunks := make([]hunk, 0)
+
+	// srcBefore[i] and dstBefore[i] are the numbers of source and destination lines before ops[i].
+	srcBefore := make([]int, len(ops)+1)
+	dstBefore := make([]int, len(ops)+1)
+	for i, op := range ops {
+		srcBefore[i+1], dstBefore[i+1] = srcBefore[i], dstBefore[i]
+		if op.kind != opInsert {
+			srcBefore[i+1]++
+		}
+		if op.kind != opDelete {
+			dstBefore[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+
+			nextChange := end
+			for nextChange < len(ops) && ops[nextChange].kind == opEqual {
+				nextChange++
+			}
+			if nextChange == len(ops) || nextChange-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = nextChange
+		}
+
+		hunks = append(hunks, hunk{ops: ops[start:end], srcStart: srcBefore[start], dstStart: dstBefore[start]})
+		i = end
+	}
+
+	return hunks
+}
+
+func (r *TextRenderer) writeHunk(out *bufio.Writer, h hunk, src, dst *sideChanges) {
+	srcLen, dstLen := 0, 0
+	for _, op := range h.ops {
+		if op.kind != opInsert {
+			srcLen++
+		}
+		if op.kind != opDelete {
+			dstLen++
+		}
+	}
+	r.writeLine(out, ansiCyan, fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.srcStart, srcLen), hunkRange(h.dstStart, dstLen)))
+
+	for _, op := range h.ops {
+		switch op.kind {
+		case opEqual:
+			r.writeLine(out, "", " "+src.lines.text(op.src))
+		case opDelete:
+			r.writeAnnotatedLine(out, ansiRed, "-"+src.lines.text(op.src), src.notes[op.src])
+		case opInsert:
+			r.writeAnnotatedLine(out, ansiGreen, "+"+dst.lines.text(op.dst), dst.notes[op.dst])
+		}
+	}
+}
+
+// hunkRange formats the range of a hunk header, e.g. `12,4`.
+// Following the unified diff format, empty ranges start at the line before them.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func (r *TextRenderer) writeAnnotatedLine(out *bufio.Writer, color, line string, notes []note) {
+	r.writeColored(out, color, line)
+	for i, n := range notes {
+		if i == 0 {
+			_, _ = out.WriteString("  ")
+		} else {
+			_, _ = out.WriteString(" ")
+		}
+
+		noteColor := ansiYellow
+		if n.kind == editscript.Update {
+			noteColor = ansiMagenta
+		}
+		r.writeColored(out, noteColor, n.text)
+	}
+	_ = out.WriteByte('\n')
+}
+
+func (r *TextRenderer) writeLine(out *bufio.Writer, color, line string) {
+	r.writeColored(out, color, line)
+	_ = out.WriteByte('\n')
+}
+
+func (r *TextRenderer) writeColored(out *bufio.Writer, color, text string) {
+	if r.Color && color != "" {
+		_, _ = out.WriteString(color + text + ansiReset)
+		return
+	}
+	_, _ = out.WriteString(text)
+}
+
+// displayPath returns the path shown in the file headers, or a placeholder if it is unknown.
+func displayPath(path, placeholder string) string {
+	if strings.TrimSpace(path) == "" {
+		return placeholder
+	}
+	return path
+}
+
+// NewTextRenderer creates a TextRenderer with the default context.
+func NewTextRenderer(color bool) *TextRenderer {
+	return &TextRenderer{
+		Context: DefaultContext,
+		Color:   color,
+	}
+}
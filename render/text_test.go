@@ -0,0 +1,109 @@
+package render_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/render"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// renderText compares the two versions of a Go file and renders their diff without color with `context` lines of context.
+func renderText(t *testing.T, src, dst string, context int) string {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	result, err := diff.Contents("a.go", []byte(src), "b.go", []byte(dst), diff.DefaultOptions(*logger))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	renderer := render.NewTextRenderer(false)
+	renderer.Context = context
+	var out bytes.Buffer
+	if err := renderer.Render(&out, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return out.String()
+}
+
+// functionsOf returns a Go file declaring a function per body, named f0, f1...
+func functionsOf(bodies ...string) string {
+	var b strings.Builder
+	b.WriteString("package p\n")
+	for i, body := range bodies {
+		fmt.Fprintf(&b, "\nfunc f%d() {\n\t%s\n}\n", i, body)
+	}
+	return b.String()
+}
+
+func TestTextRenderer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test identical files render nothing", func(t *testing.T) {
+		t.Parallel()
+
+		src := functionsOf("a()")
+		if out := renderText(t, src, src, render.DefaultContext); out != "" {
+			t.Errorf("Expected no output, got %q", out)
+		}
+	})
+
+	t.Run("Test an update is annotated in a single hunk", func(t *testing.T) {
+		t.Parallel()
+
+		out := renderText(t, "package p\n\nvar x = 1\n", "package p\n\nvar x = 2\n", render.DefaultContext)
+		expected := "--- a.go\n+++ b.go\n@@ -1,3 +1,3 @@\n package p\n \n-var x = 1\n+var x = 2  [UPD 1→2]\n"
+		if out != expected {
+			t.Errorf("Expected %q, got %q", expected, out)
+		}
+	})
+
+	t.Run("Test a move is annotated on both sides", func(t *testing.T) {
+		t.Parallel()
+
+		src := "package p\n\nfunc f() {\n\tx := 1\n\ty := 2\n\tg(x, y)\n}\n"
+		dst := "package p\n\nfunc f() {\n\ty := 2\n\tx := 1\n\tg(x, y)\n}\n"
+		out := renderText(t, src, dst, render.DefaultContext)
+		if !strings.Contains(out, "-\tx := 1  [MOVE to L5]\n") || !strings.Contains(out, "+\tx := 1  [MOVE from L4]\n") {
+			t.Errorf("Expected the moved statement to be annotated with its other line, got %q", out)
+		}
+		if strings.Contains(out, "-\ty := 2") || strings.Contains(out, "+\ty := 2") {
+			t.Errorf("Expected the statement in order to be unchanged, got %q", out)
+		}
+	})
+
+	t.Run("Test distant changes are in separate hunks", func(t *testing.T) {
+		t.Parallel()
+
+		src := functionsOf("a()", "b()", "c()", "d()", "e()")
+		dst := functionsOf("a(1)", "b()", "c()", "d()", "e(1)")
+		out := renderText(t, src, dst, 1)
+		headers := make([]string, 0)
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "@@") {
+				headers = append(headers, line)
+			}
+		}
+
+		// Each function takes 4 lines from the line 2, the changed ones are shown with a line of context.
+		expected := []string{"@@ -2,5 +2,5 @@", "@@ -18,4 +18,4 @@"}
+		if strings.Join(headers, "|") != strings.Join(expected, "|") {
+			t.Errorf("Expected hunks %v, got %v in %q", expected, headers, out)
+		}
+	})
+
+	t.Run("Test close changes share a hunk", func(t *testing.T) {
+		t.Parallel()
+
+		src := functionsOf("a()", "b()")
+		dst := functionsOf("a(1)", "b(1)")
+		out := renderText(t, src, dst, render.DefaultContext)
+		if count := strings.Count(out, "@@ -"); count != 1 {
+			t.Errorf("Expected a single hunk, got %d in %q", count, out)
+		}
+	})
+}
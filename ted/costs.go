@@ -0,0 +1,72 @@
+package ted
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+)
+
+// CostModel gives the costs of the edit operations of the tree edit distance.
+// Costs must be non-negative, and renaming a node into an identical one should cost nothing.
+type CostModel interface {
+	// Delete is the cost of deleting the node `n` of the source tree.
+	Delete(n *ast.Node) float64
+
+	// Insert is the cost of inserting the node `n` of the destination tree.
+	Insert(n *ast.Node) float64
+
+	// Rename is the cost of turning the node `src` of the source tree into the node `dst` of the destination tree.
+	Rename(src, dst *ast.Node) float64
+}
+
+// UnitCostModel is the CostModel where deletions and insertions cost 1,
+// and renames cost 1 unless the nodes have the same label and value.
+type UnitCostModel struct{}
+
+func (m *UnitCostModel) Delete(*ast.Node) float64 {
+	return 1
+}
+
+func (m *UnitCostModel) Insert(*ast.Node) float64 {
+	return 1
+}
+
+func (m *UnitCostModel) Rename(src, dst *ast.Node) float64 {
+	if src.Label == dst.Label && src.Value == dst.Value {
+		return 0
+	}
+	return 1
+}
+
+// NewUnitCostModel creates a UnitCostModel.
+func NewUnitCostModel() *UnitCostModel {
+	return &UnitCostModel{}
+}
+
+// FuncCostModel is a CostModel computing the costs from the labels and values of the nodes with custom functions,
+// e.g. to make renames between different labels cost more than the deletion and the insertion of the nodes.
+// The operations whose function is nil are priced as in UnitCostModel.
+type FuncCostModel struct {
+	DeleteCost func(label ast.NodeLabelType, value ast.NodeValueType) float64
+	InsertCost func(label ast.NodeLabelType, value ast.NodeValueType) float64
+	RenameCost func(srcLabel ast.NodeLabelType, srcValue ast.NodeValueType, dstLabel ast.NodeLabelType, dstValue ast.NodeValueType) float64
+}
+
+func (m *FuncCostModel) Delete(n *ast.Node) float64 {
+	if m.DeleteCost == nil {
+		return 1
+	}
+	return m.DeleteCost(n.Label, n.Value)
+}
+
+func (m *FuncCostModel) Insert(n *ast.Node) float64 {
+	if m.InsertCost == nil {
+		return 1
+	}
+	return m.InsertCost(n.Label, n.Value)
+}
+
+func (m *FuncCostModel) Rename(src, dst *ast.Node) float64 {
+	if m.RenameCost == nil {
+		return (&UnitCostModel{}).Rename(src, dst)
+	}
+	return m.RenameCost(src.Label, src.Value, dst.Label, dst.Value)
+}
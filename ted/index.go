@@ -0,0 +1,121 @@
+package ted
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"slices"
+)
+
+// indexedTree numbers the nodes of a tree in postorder, their ids, to compute distances in arrays.
+type indexedTree struct {
+	nodes    []*ast.Node
+	children [][]int
+	sizes    []int
+
+	// left and right are the views of the tree where the children are in order and in reverse order.
+	left, right *view
+}
+
+func newIndexedTree(tree ast.AST) *indexedTree {
+	t := &indexedTree{}
+	if tree.Root() != nil {
+		t.index(tree.Root())
+	}
+	t.left = newView(t, false)
+	t.right = newView(t, true)
+	return t
+}
+
+// index numbers the subtree of `n`, returning the id of `n`.
+func (t *indexedTree) index(n *ast.Node) int {
+	children := make([]int, 0, n.Degree())
+	size := 1
+	for _, child := range n.OrderedChildren() {
+		id := t.index(child)
+		children = append(children, id)
+		size += t.sizes[id]
+	}
+
+	t.nodes = append(t.nodes, n)
+	t.children = append(t.children, children)
+	t.sizes = append(t.sizes, size)
+	return len(t.nodes) - 1
+}
+
+// view orders the nodes of a tree in the postorder where the children are visited in order, or in reverse order.
+// Nodes are referred to by their position in the view, and the leftmost nodes of a view are the rightmost ones
+// of the tree in a reversed view, so that the same code decomposes the trees along their leftmost or rightmost paths.
+type view struct {
+	// ids and pos map the positions in the view and the ids of the nodes to each other.
+	ids, pos []int
+
+	// lml is the position of the leftmost leaf of the subtree of each position.
+	lml []int
+
+	// isLeftmostChild tells whether each position is the leftmost child of its parent.
+	isLeftmostChild []bool
+
+	// children are the ids of the children of each node in the order of the view, by id.
+	children [][]int
+
+	// keyrootSizes is the sum of the sizes of the subtrees of the keyroots of the subtree of each node, by id,
+	// i.e. the number of subforests considered by the forest distances of a single path function on this subtree.
+	keyrootSizes []int
+}
+
+func newView(t *indexedTree, reversed bool) *view {
+	n := len(t.nodes)
+	v := &view{
+		ids:             make([]int, 0, n),
+		pos:             make([]int, n),
+		lml:             make([]int, 0, n),
+		isLeftmostChild: make([]bool, 0, n),
+		children:        make([][]int, n),
+		keyrootSizes:    make([]int, n),
+	}
+	for id, children := range t.children {
+		v.children[id] = children
+		if reversed {
+			v.children[id] = slices.Clone(children)
+			slices.Reverse(v.children[id])
+		}
+	}
+
+	if n > 0 {
+		v.visit(t, n-1, true)
+	}
+	return v
+}
+
+// visit adds the subtree of the node `id` to the view.
+func (v *view) visit(t *indexedTree, id int, isLeftmostChild bool) {
+	lml := -1
+	v.keyrootSizes[id] = t.sizes[id]
+	for i, child := range v.children[id] {
+		v.visit(t, child, i == 0)
+		if i == 0 {
+			lml = v.lml[v.pos[child]]
+			// The leftmost child is not a keyroot of the subtree, unlike its keyroots.
+			v.keyrootSizes[id] -= t.sizes[child]
+		}
+		v.keyrootSizes[id] += v.keyrootSizes[child]
+	}
+
+	v.pos[id] = len(v.ids)
+	if lml == -1 {
+		lml = v.pos[id]
+	}
+	v.ids = append(v.ids, id)
+	v.lml = append(v.lml, lml)
+	v.isLeftmostChild = append(v.isLeftmostChild, isLeftmostChild)
+}
+
+// hangingOff returns the ids of the subtrees hanging off the leftmost path of the node `id`,
+// i.e. the children of the nodes of the path that are not on the path.
+func (v *view) hangingOff(id int) []int {
+	hanging := make([]int, 0)
+	for len(v.children[id]) > 0 {
+		hanging = append(hanging, v.children[id][1:]...)
+		id = v.children[id][0]
+	}
+	return hanging
+}
@@ -0,0 +1,85 @@
+package ted
+
+// strategy computes the path decomposing each pair of subtrees, by their ids, as strategy[v*len(dst.nodes)+w],
+// which minimizes the number of subproblems of the whole computation.
+//
+// Decomposing the subtrees `v` and `w` along the leftmost path of `v` costs the single path function,
+// i.e. the size of `v` times the number of keyroot subforests of `w`, plus the cost of the subtrees hanging off the path
+// against `w`. The other paths are symmetric. The costs are computed bottom-up, keeping only the rows of the subtrees
+// of the source tree whose parent is not computed yet.
+func (c *computation) strategy() []path {
+	n, m := len(c.src.nodes), len(c.dst.nodes)
+	strategy := make([]path, n*m)
+
+	// costs[v] are the costs of the subtree `v` against every subtree of the destination tree,
+	// and leftHanging[v] and rightHanging[v] the sums of the costs of the subtrees hanging off its leftmost and rightmost paths.
+	costs := make([][]float64, n)
+	leftHanging, rightHanging := make([][]float64, n), make([][]float64, n)
+	dstLeftHanging, dstRightHanging := make([]float64, m), make([]float64, m)
+
+	for v := 0; v < n; v++ {
+		row := make([]float64, m)
+		leftRow, rightRow := c.hangingCosts(c.src.left.children[v], costs, leftHanging), c.hangingCosts(c.src.right.children[v], costs, rightHanging)
+		srcSize := float64(c.src.sizes[v])
+
+		for w := 0; w < m; w++ {
+			dstLeftHanging[w] = hangingCost(c.dst.left.children[w], row, dstLeftHanging)
+			dstRightHanging[w] = hangingCost(c.dst.right.children[w], row, dstRightHanging)
+			dstSize := float64(c.dst.sizes[w])
+
+			candidates := [...]float64{
+				leftInSrc:  srcSize*float64(c.dst.left.keyrootSizes[w]) + leftRow[w],
+				rightInSrc: srcSize*float64(c.dst.right.keyrootSizes[w]) + rightRow[w],
+				leftInDst:  dstSize*float64(c.src.left.keyrootSizes[v]) + dstLeftHanging[w],
+				rightInDst: dstSize*float64(c.src.right.keyrootSizes[v]) + dstRightHanging[w],
+			}
+			best, cost := leftInSrc, candidates[leftInSrc]
+			for p := rightInSrc; p <= rightInDst; p++ {
+				if candidates[p] < cost {
+					best, cost = p, candidates[p]
+				}
+			}
+			strategy[v*m+w] = best
+			row[w] = cost
+		}
+
+		costs[v], leftHanging[v], rightHanging[v] = row, leftRow, rightRow
+		for _, child := range c.src.left.children[v] {
+			costs[child], leftHanging[child], rightHanging[child] = nil, nil, nil
+		}
+	}
+	return strategy
+}
+
+// hangingCosts returns the sums of the costs of the subtrees hanging off the leftmost path of a node of the source tree
+// against every subtree of the destination tree, given the children of the node in the order of the view,
+// their costs and their own sums.
+func (c *computation) hangingCosts(children []int, costs, hanging [][]float64) []float64 {
+	row := make([]float64, len(c.dst.nodes))
+	if len(children) == 0 {
+		return row
+	}
+
+	copy(row, hanging[children[0]])
+	for _, child := range children[1:] {
+		for w, cost := range costs[child] {
+			row[w] += cost
+		}
+	}
+	return row
+}
+
+// hangingCost returns the sum of the costs of the subtrees hanging off the leftmost path of a node of the destination tree
+// against a subtree of the source tree, given the children of the node in the order of the view,
+// the costs of the subtree of the source tree against them and their own sums.
+func hangingCost(children []int, costs, hanging []float64) float64 {
+	if len(children) == 0 {
+		return 0
+	}
+
+	cost := hanging[children[0]]
+	for _, child := range children[1:] {
+		cost += costs[child]
+	}
+	return cost
+}
@@ -0,0 +1,262 @@
+// Package ted computes the optimal tree edit distance between two ASTs,
+// i.e. the minimal cost of the node deletions, insertions and renames turning one into the other,
+// together with an optimal mapping of their nodes.
+//
+// Unlike the GumTree matching, which is fast but heuristic and also finds moves, the distance is exact.
+// Both algorithms take O(nm) memory, for trees of n and m nodes: ZhangShasha runs in O(n²m²) time in the worst case
+// and APTED picks its decomposition of the trees to avoid most of these worst cases.
+package ted
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/comparator"
+)
+
+// Result is an optimal edit of a source tree into a destination tree.
+type Result struct {
+	// Distance is the total cost of the edit.
+	Distance float64
+
+	// Mappings are the pairs of nodes kept by the edit, which are renamed unless the cost model says they are identical.
+	// The other nodes of the source tree are deleted, and the other nodes of the destination tree are inserted.
+	Mappings comparator.MappingStore
+}
+
+// ZhangShasha computes the tree edit distance between `src` and `dst` with the algorithm of Zhang and Shasha,
+// "Simple fast algorithms for the editing distance between trees and related problems",
+// which always decomposes the trees along the leftmost paths of the source tree.
+func ZhangShasha(src, dst ast.AST, costs CostModel) Result {
+	c := newComputation(src, dst, costs)
+	return c.run(func(v, w int) path { return leftInSrc })
+}
+
+// APTED computes the tree edit distance between `src` and `dst` with the algorithm of Pawlik and Augsten,
+// "Tree edit distance: Robust and memory-efficient", which decomposes each pair of subtrees along the path
+// minimizing the number of subproblems, as computed beforehand by dynamic programming.
+//
+// The decomposition paths are the leftmost and rightmost paths of either tree.
+// The heavy paths of the original algorithm are not used, as their single-path function takes more memory.
+// The result is the same as ZhangShasha, up to the choice among optimal mappings.
+func APTED(src, dst ast.AST, costs CostModel) Result {
+	c := newComputation(src, dst, costs)
+	strategy := c.strategy()
+	m := len(c.dst.nodes)
+	return c.run(func(v, w int) path { return strategy[v*m+w] })
+}
+
+// path is a decomposition path of a pair of subtrees.
+type path uint8
+
+const (
+	leftInSrc path = iota
+	rightInSrc
+	leftInDst
+	rightInDst
+)
+
+// computation holds the state of the computation of a tree edit distance.
+type computation struct {
+	src, dst *indexedTree
+	costs    CostModel
+
+	deleteCosts, insertCosts []float64
+
+	// distances holds the distance between every pair of subtrees, by their ids, as distances[v*len(dst.nodes)+w].
+	distances []float64
+
+	// forest is the buffer of the forest distances.
+	forest []float64
+}
+
+func newComputation(src, dst ast.AST, costs CostModel) *computation {
+	c := &computation{src: newIndexedTree(src), dst: newIndexedTree(dst), costs: costs}
+	c.deleteCosts = make([]float64, len(c.src.nodes))
+	for v, n := range c.src.nodes {
+		c.deleteCosts[v] = costs.Delete(n)
+	}
+	c.insertCosts = make([]float64, len(c.dst.nodes))
+	for w, n := range c.dst.nodes {
+		c.insertCosts[w] = costs.Insert(n)
+	}
+	return c
+}
+
+// run computes the distance between the trees, decomposing each pair of subtrees along the path given by `pathOf`.
+func (c *computation) run(pathOf func(v, w int) path) Result {
+	n, m := len(c.src.nodes), len(c.dst.nodes)
+	if n == 0 || m == 0 {
+		distance := 0.0
+		for _, cost := range c.deleteCosts {
+			distance += cost
+		}
+		for _, cost := range c.insertCosts {
+			distance += cost
+		}
+		return Result{Distance: distance, Mappings: comparator.NewMappingStore()}
+	}
+
+	c.distances = make([]float64, n*m)
+	c.forest = make([]float64, (n+1)*(m+1))
+	c.decompose(n-1, m-1, pathOf)
+	return Result{Distance: c.distance(n-1, m-1), Mappings: c.mappings()}
+}
+
+// decompose computes the distances between all the subtrees of the subtree `v` of the source tree
+// and all the subtrees of the subtree `w` of the destination tree.
+// The subtrees hanging off the decomposition path are compared first, then the subtrees rooted on the path.
+func (c *computation) decompose(v, w int, pathOf func(v, w int) path) {
+	switch pathOf(v, w) {
+	case leftInSrc:
+		for _, h := range c.src.left.hangingOff(v) {
+			c.decompose(h, w, pathOf)
+		}
+		c.singlePath(c.src.left, c.dst.left, v, w, false)
+	case rightInSrc:
+		for _, h := range c.src.right.hangingOff(v) {
+			c.decompose(h, w, pathOf)
+		}
+		c.singlePath(c.src.right, c.dst.right, v, w, false)
+	case leftInDst:
+		for _, h := range c.dst.left.hangingOff(w) {
+			c.decompose(v, h, pathOf)
+		}
+		c.singlePath(c.dst.left, c.src.left, w, v, true)
+	case rightInDst:
+		for _, h := range c.dst.right.hangingOff(w) {
+			c.decompose(v, h, pathOf)
+		}
+		c.singlePath(c.dst.right, c.src.right, w, v, true)
+	}
+}
+
+// singlePath computes the distances between the subtrees rooted on the leftmost path of the subtree `v` of `f`
+// and all the subtrees of the subtree `w` of `g`, given the distances of the subtrees hanging off the path.
+// The views tell the direction of the decomposition, e.g. rightmost paths on the right views.
+// `f` is a view of the destination tree and `g` a view of the source tree if `swapped` is set.
+//
+// This is the forest distance of Zhang and Shasha between the whole subtree of `v` and each keyroot of the subtree of `w`,
+// i.e. its root and the nodes that are not the leftmost child of their parent, in postorder.
+func (c *computation) singlePath(f, g *view, v, w int, swapped bool) {
+	vp, wp := f.pos[v], g.pos[w]
+	for j := g.lml[wp]; j <= wp; j++ {
+		if j == wp || !g.isLeftmostChild[j] {
+			c.forestDistance(f, g, vp, j, swapped, true)
+		}
+	}
+}
+
+// forestDistance computes the distance between the forests made of the first nodes in postorder of the subtrees
+// at the view positions `vp` in `f` and `wp` in `g`, where the distances of the subtrees that are not
+// on the leftmost paths of `vp` and `wp` are known. The distances of the subtrees on the paths are saved if `save` is set.
+// It returns the width of the table of the forest distances.
+func (c *computation) forestDistance(f, g *view, vp, wp int, swapped, save bool) int {
+	fl, gl := f.lml[vp], g.lml[wp]
+	rows, cols := vp-fl+2, wp-gl+2
+	fd := c.forest[:rows*cols]
+
+	fd[0] = 0
+	for r := 1; r < rows; r++ {
+		fd[r*cols] = fd[(r-1)*cols] + c.deleteCostOf(f.ids[fl+r-1], swapped)
+	}
+	for col := 1; col < cols; col++ {
+		fd[col] = fd[col-1] + c.insertCostOf(g.ids[gl+col-1], swapped)
+	}
+
+	for r := 1; r < rows; r++ {
+		x := fl + r - 1
+		xid := f.ids[x]
+		deleteCost := c.deleteCostOf(xid, swapped)
+		for col := 1; col < cols; col++ {
+			y := gl + col - 1
+			yid := g.ids[y]
+
+			cost := min(fd[(r-1)*cols+col]+deleteCost, fd[r*cols+col-1]+c.insertCostOf(yid, swapped))
+			if f.lml[x] == fl && g.lml[y] == gl {
+				cost = min(cost, fd[(r-1)*cols+col-1]+c.renameCostOf(xid, yid, swapped))
+				if save {
+					c.setDistance(xid, yid, swapped, cost)
+				}
+			} else {
+				cost = min(cost, fd[(f.lml[x]-fl)*cols+g.lml[y]-gl]+c.distanceOf(xid, yid, swapped))
+			}
+			fd[r*cols+col] = cost
+		}
+	}
+	return cols
+}
+
+// mappings backtracks through the forest distances to find the pairs of nodes of an optimal edit,
+// starting from the roots of the trees.
+func (c *computation) mappings() comparator.MappingStore {
+	mappings := comparator.NewMappingStore()
+	f, g := c.src.left, c.dst.left
+
+	stack := [][2]int{{len(c.src.nodes) - 1, len(c.dst.nodes) - 1}}
+	for len(stack) > 0 {
+		vp, wp := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		cols := c.forestDistance(f, g, vp, wp, false, false)
+		fl, gl := f.lml[vp], g.lml[wp]
+		fd := c.forest
+		at := func(x, y int) float64 {
+			return fd[(x-fl+1)*cols+y-gl+1]
+		}
+
+		x, y := vp, wp
+		for x >= fl || y >= gl {
+			switch {
+			case y < gl || (x >= fl && at(x, y) == at(x-1, y)+c.deleteCosts[f.ids[x]]):
+				x--
+			case x < fl || at(x, y) == at(x, y-1)+c.insertCosts[g.ids[y]]:
+				y--
+			case f.lml[x] == fl && g.lml[y] == gl:
+				mappings.Add(c.src.nodes[f.ids[x]], c.dst.nodes[g.ids[y]])
+				x, y = x-1, y-1
+			default:
+				stack = append(stack, [2]int{x, y})
+				x, y = f.lml[x]-1, g.lml[y]-1
+			}
+		}
+	}
+	return mappings
+}
+
+func (c *computation) deleteCostOf(id int, swapped bool) float64 {
+	if swapped {
+		return c.insertCosts[id]
+	}
+	return c.deleteCosts[id]
+}
+
+func (c *computation) insertCostOf(id int, swapped bool) float64 {
+	if swapped {
+		return c.deleteCosts[id]
+	}
+	return c.insertCosts[id]
+}
+
+func (c *computation) renameCostOf(fid, gid int, swapped bool) float64 {
+	if swapped {
+		return c.costs.Rename(c.src.nodes[gid], c.dst.nodes[fid])
+	}
+	return c.costs.Rename(c.src.nodes[fid], c.dst.nodes[gid])
+}
+
+func (c *computation) distance(v, w int) float64 {
+	return c.distances[v*len(c.dst.nodes)+w]
+}
+
+func (c *computation) distanceOf(fid, gid int, swapped bool) float64 {
+	if swapped {
+		return c.distance(gid, fid)
+	}
+	return c.distance(fid, gid)
+}
+
+func (c *computation) setDistance(fid, gid int, swapped bool, distance float64) {
+	if swapped {
+		fid, gid = gid, fid
+	}
+	c.distances[fid*len(c.dst.nodes)+gid] = distance
+}
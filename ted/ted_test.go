@@ -0,0 +1,269 @@
+package ted_test
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/ted"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// parseTree builds a tree from a bracket notation where every node is a label, e.g. `f(d(a c(b)) e)`.
+func parseTree(text string) ast.AST {
+	tree := ast.NewAST(slog.Logger{})
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(text))
+
+	var parse func(parent *ast.Node)
+	parse = func(parent *ast.Node) {
+		for len(tokens) > 0 && tokens[0] != ")" {
+			idx := -1
+			if parent != nil {
+				idx = parent.Degree()
+			}
+			n, err := tree.Add(parent, idx, ast.NodeLabelType(tokens[0]), "")
+			if err != nil {
+				panic(err)
+			}
+			tokens = tokens[1:]
+			if len(tokens) > 0 && tokens[0] == "(" {
+				tokens = tokens[1:]
+				parse(n)
+				tokens = tokens[1:]
+			}
+		}
+	}
+	parse(nil)
+	return tree
+}
+
+// randomTree builds a tree of `size` nodes with random shapes and labels.
+func randomTree(rng *rand.Rand, size int) ast.AST {
+	tree := ast.NewAST(slog.Logger{})
+	nodes := make([]*ast.Node, 0, size)
+	for i := 0; i < size; i++ {
+		var parent *ast.Node
+		idx := -1
+		if i > 0 {
+			parent = nodes[rng.Intn(len(nodes))]
+			idx = parent.Degree()
+		}
+		n, err := tree.Add(parent, idx, ast.NodeLabelType(string(rune('a'+rng.Intn(3)))), "")
+		if err != nil {
+			panic(err)
+		}
+		nodes = append(nodes, n)
+	}
+	return tree
+}
+
+// bruteForce computes the tree edit distance with the recursive definition on forests, memoizing the forests by their roots.
+func bruteForce(src, dst ast.AST, costs ted.CostModel) float64 {
+	memo := make(map[string]float64)
+	var distance func(f, g []*ast.Node) float64
+	distance = func(f, g []*ast.Node) float64 {
+		key := keyOf(f) + "|" + keyOf(g)
+		if d, ok := memo[key]; ok {
+			return d
+		}
+
+		var d float64
+		switch {
+		case len(f) == 0 && len(g) == 0:
+			d = 0
+		case len(g) == 0:
+			v := f[len(f)-1]
+			d = distance(withChildren(f[:len(f)-1], v), g) + costs.Delete(v)
+		case len(f) == 0:
+			w := g[len(g)-1]
+			d = distance(f, withChildren(g[:len(g)-1], w)) + costs.Insert(w)
+		default:
+			v, w := f[len(f)-1], g[len(g)-1]
+			d = min(
+				distance(withChildren(f[:len(f)-1], v), g)+costs.Delete(v),
+				distance(f, withChildren(g[:len(g)-1], w))+costs.Insert(w),
+				distance(f[:len(f)-1], g[:len(g)-1])+distance(v.OrderedChildren(), w.OrderedChildren())+costs.Rename(v, w),
+			)
+		}
+		memo[key] = d
+		return d
+	}
+	return distance(rootsOf(src), rootsOf(dst))
+}
+
+// keyOf identifies a forest by the addresses of its roots.
+func keyOf(forest []*ast.Node) string {
+	var b strings.Builder
+	for _, n := range forest {
+		_, _ = fmt.Fprintf(&b, "%p,", n)
+	}
+	return b.String()
+}
+
+func rootsOf(tree ast.AST) []*ast.Node {
+	if tree.Root() == nil {
+		return nil
+	}
+	return []*ast.Node{tree.Root()}
+}
+
+func withChildren(forest []*ast.Node, n *ast.Node) []*ast.Node {
+	result := make([]*ast.Node, 0, len(forest)+n.Degree())
+	result = append(result, forest...)
+	return append(result, n.OrderedChildren()...)
+}
+
+// assertOptimalMapping checks that `result` maps the nodes of the trees consistently with their ancestors and order,
+// and that the cost of the mapping is the distance.
+func assertOptimalMapping(t *testing.T, src, dst ast.AST, costs ted.CostModel, result ted.Result) {
+	t.Helper()
+
+	srcOrder, dstOrder := ordersOf(src), ordersOf(dst)
+	pairs := result.Mappings.Pairs()
+	cost := 0.0
+	for _, n := range src.PreOrderNodes() {
+		if !result.Mappings.IsSrcMapped(n) {
+			cost += costs.Delete(n)
+		}
+	}
+	for _, n := range dst.PreOrderNodes() {
+		if !result.Mappings.IsDstMapped(n) {
+			cost += costs.Insert(n)
+		}
+	}
+	for _, p := range pairs {
+		cost += costs.Rename(p.Left(), p.Right())
+		for _, q := range pairs {
+			if srcOrder.isAncestor(p.Left(), q.Left()) != dstOrder.isAncestor(p.Right(), q.Right()) {
+				t.Fatalf("Expected the mapping to keep the ancestors of %v and %v", p, q)
+			}
+			if srcOrder.isLeftOf(p.Left(), q.Left()) != dstOrder.isLeftOf(p.Right(), q.Right()) {
+				t.Fatalf("Expected the mapping to keep the order of %v and %v", p, q)
+			}
+		}
+	}
+	if cost != result.Distance {
+		t.Errorf("Expected the mapping to cost the distance %f, got %f", result.Distance, cost)
+	}
+}
+
+// orders are the preorder and postorder indices of the nodes of a tree.
+type orders struct {
+	pre, post map[*ast.Node]int
+}
+
+func ordersOf(tree ast.AST) orders {
+	o := orders{pre: make(map[*ast.Node]int), post: make(map[*ast.Node]int)}
+	var visit func(n *ast.Node)
+	visit = func(n *ast.Node) {
+		o.pre[n] = len(o.pre)
+		for _, child := range n.OrderedChildren() {
+			visit(child)
+		}
+		o.post[n] = len(o.post)
+	}
+	if tree.Root() != nil {
+		visit(tree.Root())
+	}
+	return o
+}
+
+func (o orders) isAncestor(a, b *ast.Node) bool {
+	return o.pre[a] < o.pre[b] && o.post[a] > o.post[b]
+}
+
+func (o orders) isLeftOf(a, b *ast.Node) bool {
+	return o.pre[a] < o.pre[b] && o.post[a] < o.post[b]
+}
+
+var algorithms = map[string]func(src, dst ast.AST, costs ted.CostModel) ted.Result{
+	"ZhangShasha": ted.ZhangShasha,
+	"APTED":       ted.APTED,
+}
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	for name, algorithm := range algorithms {
+		t.Run(fmt.Sprintf("Test %s on the example of Zhang and Shasha", name), func(t *testing.T) {
+			t.Parallel()
+
+			src, dst := parseTree("f(d(a c(b)) e)"), parseTree("f(c(d(a b)) e)")
+			result := algorithm(src, dst, ted.NewUnitCostModel())
+			if result.Distance != 2 {
+				t.Errorf("Expected a distance of 2, got %f", result.Distance)
+			}
+			if result.Mappings.Size() != 5 {
+				t.Errorf("Expected 5 mapped nodes, got %d", result.Mappings.Size())
+			}
+			assertOptimalMapping(t, src, dst, ted.NewUnitCostModel(), result)
+		})
+
+		t.Run(fmt.Sprintf("Test %s with empty trees", name), func(t *testing.T) {
+			t.Parallel()
+
+			empty, tree := ast.NewAST(slog.Logger{}), parseTree("a(b c)")
+			if d := algorithm(empty, tree, ted.NewUnitCostModel()).Distance; d != 3 {
+				t.Errorf("Expected inserting 3 nodes, got %f", d)
+			}
+			if d := algorithm(tree, empty, ted.NewUnitCostModel()).Distance; d != 3 {
+				t.Errorf("Expected deleting 3 nodes, got %f", d)
+			}
+			if d := algorithm(empty, empty, ted.NewUnitCostModel()).Distance; d != 0 {
+				t.Errorf("Expected no cost, got %f", d)
+			}
+		})
+
+		t.Run(fmt.Sprintf("Test %s with custom costs", name), func(t *testing.T) {
+			t.Parallel()
+
+			// Renames cost more than a deletion and an insertion, so that they never happen.
+			costs := &ted.FuncCostModel{
+				RenameCost: func(srcLabel ast.NodeLabelType, _ ast.NodeValueType, dstLabel ast.NodeLabelType, _ ast.NodeValueType) float64 {
+					if srcLabel == dstLabel {
+						return 0
+					}
+					return 3
+				},
+			}
+			src, dst := parseTree("a(b c)"), parseTree("a(x c)")
+			result := algorithm(src, dst, costs)
+			if result.Distance != 2 || result.Mappings.Size() != 2 {
+				t.Errorf("Expected a distance of 2 with 2 mapped nodes, got %f with %d", result.Distance, result.Mappings.Size())
+			}
+		})
+
+		t.Run(fmt.Sprintf("Test %s against the recursive definition on random trees", name), func(t *testing.T) {
+			t.Parallel()
+
+			rng := rand.New(rand.NewSource(42))
+			costs := &ted.FuncCostModel{
+				DeleteCost: func(label ast.NodeLabelType, _ ast.NodeValueType) float64 { return float64(label[0]-'a') + 1 },
+				InsertCost: func(ast.NodeLabelType, ast.NodeValueType) float64 { return 2 },
+			}
+			for i := 0; i < 100; i++ {
+				src, dst := randomTree(rng, 1+rng.Intn(8)), randomTree(rng, 1+rng.Intn(8))
+				result := algorithm(src, dst, costs)
+				if expected := bruteForce(src, dst, costs); result.Distance != expected {
+					t.Fatalf("Expected a distance of %f, got %f", expected, result.Distance)
+				}
+				assertOptimalMapping(t, src, dst, costs, result)
+			}
+		})
+	}
+
+	t.Run("Test both algorithms agree on larger trees", func(t *testing.T) {
+		t.Parallel()
+
+		rng := rand.New(rand.NewSource(7))
+		for i := 0; i < 20; i++ {
+			src, dst := randomTree(rng, 50+rng.Intn(100)), randomTree(rng, 50+rng.Intn(100))
+			zs, apted := ted.ZhangShasha(src, dst, ted.NewUnitCostModel()), ted.APTED(src, dst, ted.NewUnitCostModel())
+			if zs.Distance != apted.Distance {
+				t.Fatalf("Expected the same distance, got %f and %f", zs.Distance, apted.Distance)
+			}
+			assertOptimalMapping(t, src, dst, ted.NewUnitCostModel(), apted)
+		}
+	})
+}
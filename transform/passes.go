@@ -0,0 +1,126 @@
+package transform
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+	"strings"
+)
+
+// ValueNormalizer computes the normalized form of a node value.
+type ValueNormalizer func(value ast.NodeValueType) ast.NodeValueType
+
+// CollapseWhitespace replaces every run of whitespace in a value with a single space,
+// and trims the whitespace at both ends.
+func CollapseWhitespace(value ast.NodeValueType) ast.NodeValueType {
+	return ast.NodeValueType(strings.Join(strings.Fields(string(value)), " "))
+}
+
+// Lowercase converts a value to lower case, e.g. for case-insensitive languages.
+func Lowercase(value ast.NodeValueType) ast.NodeValueType {
+	return ast.NodeValueType(strings.ToLower(string(value)))
+}
+
+type dropLabelsPass struct {
+	labels []ast.NodeLabelType
+	logger slog.Logger
+}
+
+func (p *dropLabelsPass) Name() string {
+	return fmt.Sprintf("drop%v", p.labels)
+}
+
+func (p *dropLabelsPass) Apply(tree ast.AST) (ast.AST, error) {
+	if len(p.labels) == 0 {
+		return tree, nil
+	}
+
+	isDropped := labelSet(p.labels)
+	return rewrite(tree, rewriter{
+		substitute: func(n *ast.Node) *ast.Node {
+			if isDropped(n.Label) {
+				return nil
+			}
+			return n
+		},
+	}, p.logger)
+}
+
+// NewDropLabelsPass creates a Pass removing the nodes with one of the given labels, along with their subtrees.
+// It is typically used to ignore comments. The root of the tree is never removed.
+func NewDropLabelsPass(logger slog.Logger, labels ...ast.NodeLabelType) Pass {
+	return &dropLabelsPass{
+		labels: labels,
+		logger: logger,
+	}
+}
+
+type collapseChainsPass struct {
+	labels []ast.NodeLabelType
+	logger slog.Logger
+}
+
+func (p *collapseChainsPass) Name() string {
+	if len(p.labels) == 0 {
+		return "collapse[*]"
+	}
+	return fmt.Sprintf("collapse%v", p.labels)
+}
+
+func (p *collapseChainsPass) Apply(tree ast.AST) (ast.AST, error) {
+	isCollapsed := labelSet(p.labels)
+	return rewrite(tree, rewriter{
+		substitute: func(n *ast.Node) *ast.Node {
+			for n.Degree() == 1 && n.Value == "" && isCollapsed(n.Label) {
+				n = n.OrderedChildren()[0]
+			}
+			return n
+		},
+	}, p.logger)
+}
+
+// NewCollapseChainsPass creates a Pass replacing the nodes that have a single child and no value by that child,
+// e.g. to ignore parenthesis wrappers. Chains of such nodes are collapsed down to their first node that does not qualify.
+// Only nodes with one of the given labels are collapsed, or any node if no label is given.
+// The root of the tree is never collapsed.
+func NewCollapseChainsPass(logger slog.Logger, labels ...ast.NodeLabelType) Pass {
+	return &collapseChainsPass{
+		labels: labels,
+		logger: logger,
+	}
+}
+
+type normalizeValuesPass struct {
+	normalize ValueNormalizer
+	labels    []ast.NodeLabelType
+	logger    slog.Logger
+}
+
+func (p *normalizeValuesPass) Name() string {
+	if len(p.labels) == 0 {
+		return "normalize[*]"
+	}
+	return fmt.Sprintf("normalize%v", p.labels)
+}
+
+func (p *normalizeValuesPass) Apply(tree ast.AST) (ast.AST, error) {
+	isNormalized := labelSet(p.labels)
+	return rewrite(tree, rewriter{
+		valueOf: func(n *ast.Node) ast.NodeValueType {
+			if isNormalized(n.Label) {
+				return p.normalize(n.Value)
+			}
+			return n.Value
+		},
+	}, p.logger)
+}
+
+// NewNormalizeValuesPass creates a Pass rewriting the values of the nodes with `normalize`.
+// Only nodes with one of the given labels are normalized, or any node if no label is given.
+func NewNormalizeValuesPass(normalize ValueNormalizer, logger slog.Logger, labels ...ast.NodeLabelType) Pass {
+	return &normalizeValuesPass{
+		normalize: normalize,
+		labels:    labels,
+		logger:    logger,
+	}
+}
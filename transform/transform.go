@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"log/slog"
+)
+
+// Pass is a transformation of an AST, applied before matching to get rid of spurious differences.
+// A Pass never modifies its input, it returns a new AST instead.
+type Pass interface {
+	// Name returns a short description of the pass, e.g. for logging.
+	Name() string
+
+	// Apply returns the transformed copy of `tree`.
+	Apply(tree ast.AST) (ast.AST, error)
+}
+
+// Pipeline is a sequence of passes applied one after another.
+type Pipeline []Pass
+
+// Apply runs every pass of the pipeline on `tree` in order.
+// An empty pipeline returns `tree` itself.
+func (p Pipeline) Apply(tree ast.AST) (ast.AST, error) {
+	var err error
+	for _, pass := range p {
+		tree, err = pass.Apply(tree)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
+
+// rewriter describes how a tree is copied by rewrite.
+type rewriter struct {
+	// substitute returns the node to copy in place of `n`, or nil to drop `n` with its subtree.
+	// It is never called on the root.
+	substitute func(n *ast.Node) *ast.Node
+
+	// valueOf returns the value of the copy of `n`.
+	valueOf func(n *ast.Node) ast.NodeValueType
+}
+
+// rewrite creates a new AST out of `tree` according to `r`.
+// Positions of the nodes are kept, so that the new AST still refers to the same source text.
+func rewrite(tree ast.AST, r rewriter, logger slog.Logger) (ast.AST, error) {
+	newTree := ast.NewAST(logger)
+	root := tree.Root()
+	if root == nil {
+		return newTree, nil
+	}
+
+	if r.substitute == nil {
+		r.substitute = func(n *ast.Node) *ast.Node { return n }
+	}
+	if r.valueOf == nil {
+		r.valueOf = func(n *ast.Node) ast.NodeValueType { return n.Value }
+	}
+
+	var copyNode func(n, newParent *ast.Node) error
+	copyNode = func(n, newParent *ast.Node) error {
+		idx := -1
+		if newParent != nil {
+			idx = newParent.Degree()
+		}
+
+		newNode, err := newTree.Add(newParent, idx, n.Label, r.valueOf(n))
+		if err != nil {
+			return err
+		}
+		newNode.Pos = n.Pos
+
+		for _, child := range n.OrderedChildren() {
+			if child = r.substitute(child); child == nil {
+				continue
+			}
+			if err := copyNode(child, newNode); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := copyNode(root, nil); err != nil {
+		return nil, err
+	}
+	return newTree, nil
+}
+
+// labelSet returns a predicate telling whether a label is one of `labels`.
+// If `labels` is empty, every label is accepted.
+func labelSet(labels []ast.NodeLabelType) func(ast.NodeLabelType) bool {
+	if len(labels) == 0 {
+		return func(ast.NodeLabelType) bool { return true }
+	}
+
+	set := make(map[ast.NodeLabelType]struct{}, len(labels))
+	for _, label := range labels {
+		set[label] = struct{}{}
+	}
+	return func(label ast.NodeLabelType) bool {
+		_, ok := set[label]
+		return ok
+	}
+}
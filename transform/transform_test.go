@@ -0,0 +1,101 @@
+package transform_test
+
+import (
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/transform"
+	"log/slog"
+	"testing"
+)
+
+// givenTree builds `root(paren(paren(lit:"a  b")), comment:"c", call(lit:" x "))`.
+func givenTree(t *testing.T) ast.AST {
+	tree := ast.NewAST(slog.Logger{})
+	root, _ := tree.Add(nil, -1, "root", "")
+	outer, _ := tree.Add(root, 0, "paren", "")
+	inner, _ := tree.Add(outer, 0, "paren", "")
+	_, _ = tree.Add(inner, 0, "lit", "a  b")
+	_, _ = tree.Add(root, 1, "comment", "c")
+	call, _ := tree.Add(root, 2, "call", "")
+	_, err := tree.Add(call, 0, "lit", " x ")
+	if err != nil {
+		t.Fatalf("error building the tree: %v", err)
+	}
+	return tree
+}
+
+func labelsOf(nodes []*ast.Node) []ast.NodeLabelType {
+	labels := make([]ast.NodeLabelType, 0, len(nodes))
+	for _, n := range nodes {
+		labels = append(labels, n.Label)
+	}
+	return labels
+}
+
+func TestDropLabelsPass(t *testing.T) {
+	t.Parallel()
+
+	tree := givenTree(t)
+	newTree, err := transform.NewDropLabelsPass(slog.Logger{}, "comment", "lit").Apply(tree)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(newTree.PreOrderNodes()) != 4 {
+		t.Errorf("Expected 4 nodes left, got %v", labelsOf(newTree.PreOrderNodes()))
+	}
+	if len(tree.PreOrderNodes()) != 7 {
+		t.Errorf("Expected the input tree to be left untouched, got %v", labelsOf(tree.PreOrderNodes()))
+	}
+}
+
+func TestCollapseChainsPass(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test collapsing given labels", func(t *testing.T) {
+		newTree, _ := transform.NewCollapseChainsPass(slog.Logger{}, "paren").Apply(givenTree(t))
+		children := newTree.Root().OrderedChildren()
+		if children[0].Label != "lit" || children[0].Value != "a  b" {
+			t.Errorf("Expected the parenthesis to be collapsed, got %v", labelsOf(children))
+		}
+		if children[2].Label != "call" {
+			t.Errorf("Expected the call not to be collapsed, got %v", labelsOf(children))
+		}
+	})
+
+	t.Run("Test collapsing any label", func(t *testing.T) {
+		newTree, _ := transform.NewCollapseChainsPass(slog.Logger{}).Apply(givenTree(t))
+		expected := []ast.NodeLabelType{"lit", "comment", "lit"}
+		children := newTree.Root().OrderedChildren()
+		for i, label := range labelsOf(children) {
+			if label != expected[i] {
+				t.Errorf("Expected %v, got %v", expected, labelsOf(children))
+				break
+			}
+		}
+	})
+}
+
+func TestPipeline_Apply(t *testing.T) {
+	t.Parallel()
+
+	pipeline := transform.Pipeline{
+		transform.NewDropLabelsPass(slog.Logger{}, "comment"),
+		transform.NewCollapseChainsPass(slog.Logger{}, "paren"),
+		transform.NewNormalizeValuesPass(transform.CollapseWhitespace, slog.Logger{}, "lit"),
+	}
+	newTree, err := pipeline.Apply(givenTree(t))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	nodes := newTree.PreOrderNodes()
+	expectedValues := []ast.NodeValueType{"", "a b", "", "x"}
+	if len(nodes) != len(expectedValues) {
+		t.Fatalf("Expected %d nodes, got %v", len(expectedValues), labelsOf(nodes))
+	}
+	for i, n := range nodes {
+		if n.Value != expectedValues[i] {
+			t.Errorf("Expected value %q for node %d, got %q", expectedValues[i], i, n.Value)
+		}
+	}
+}
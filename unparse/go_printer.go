@@ -0,0 +1,432 @@
+package unparse
+
+import (
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"slices"
+	"strings"
+)
+
+// GoPrinter prints the nodes built by frontend.GoTreeGenerator in the style of gofmt.
+//
+// As the tree only keeps the nodes of go/ast, the fields of some nodes are guessed from the labels of their children,
+// e.g. the condition of a `for` statement is the expression between its statements.
+// The guesses are right for the code gofmt produces, but missing optional parts may be misplaced in rare cases,
+// e.g. the high bound of a slice expression without low bound is printed as its low bound.
+type GoPrinter struct{}
+
+// goChild is a child of the printed node: its label and its text.
+type goChild struct {
+	label ast.NodeLabelType
+	text  string
+}
+
+func (p *GoPrinter) Print(n *ast.Node, texts []string) (string, error) {
+	children := make([]goChild, 0, len(texts))
+	for i, child := range n.OrderedChildren() {
+		children = append(children, goChild{label: child.Label, text: texts[i]})
+	}
+
+	// Doc comments precede their node, and line comments of fields and specifications follow it.
+	doc, comment := "", ""
+	if n.Label != "CommentGroup" {
+		if len(children) > 0 && children[0].label == "CommentGroup" {
+			doc, children = children[0].text+"\n", children[1:]
+		}
+		if len(children) > 0 && children[len(children)-1].label == "CommentGroup" {
+			comment, children = " "+children[len(children)-1].text, children[:len(children)-1]
+		}
+	}
+
+	text, err := p.print(n, children)
+	if err != nil {
+		return "", err
+	}
+	return doc + text + comment, nil
+}
+
+func (p *GoPrinter) print(n *ast.Node, children []goChild) (string, error) {
+	value := string(n.Value)
+	switch n.Label {
+	case "Ident", "BasicLit", "Comment":
+		return value, nil
+	case "CommentGroup":
+		return joinGo(children, "\n"), nil
+	case "Ellipsis":
+		return "..." + joinGo(children, ""), nil
+	case "FuncLit":
+		return joinGo(children, " "), nil
+	case "CompositeLit":
+		if len(children) > 0 && isGoType(children[0].label) {
+			return children[0].text + "{" + joinGo(children[1:], ", ") + "}", nil
+		}
+		return "{" + joinGo(children, ", ") + "}", nil
+	case "ParenExpr":
+		return "(" + joinGo(children, "") + ")", nil
+	case "SelectorExpr":
+		return joinGo(children, "."), nil
+	case "IndexExpr", "IndexListExpr":
+		if len(children) < 2 {
+			break
+		}
+		return children[0].text + "[" + joinGo(children[1:], ", ") + "]", nil
+	case "SliceExpr":
+		if len(children) == 0 {
+			break
+		}
+		bounds := joinGo(children[1:], ":")
+		if len(children) == 2 {
+			bounds += ":"
+		}
+		return children[0].text + "[" + bounds + "]", nil
+	case "TypeAssertExpr":
+		if len(children) == 1 {
+			return children[0].text + ".(type)", nil
+		}
+		return joinGo(children[:1], "") + ".(" + joinGo(children[1:], "") + ")", nil
+	case "CallExpr":
+		if len(children) == 0 {
+			break
+		}
+		return children[0].text + "(" + joinGo(children[1:], ", ") + ")", nil
+	case "StarExpr":
+		return "*" + joinGo(children, ""), nil
+	case "UnaryExpr":
+		return value + joinGo(children, ""), nil
+	case "BinaryExpr":
+		return joinGo(children, " "+value+" "), nil
+	case "KeyValueExpr":
+		return joinGo(children, ": "), nil
+
+	case "ArrayType":
+		if len(children) == 1 {
+			return "[]" + children[0].text, nil
+		}
+		return "[" + joinGo(children[:len(children)-1], "") + "]" + joinGo(children[len(children)-1:], ""), nil
+	case "StructType":
+		return "struct " + joinGo(children, ""), nil
+	case "InterfaceType":
+		return "interface " + joinGo(children, ""), nil
+	case "MapType":
+		if len(children) != 2 {
+			break
+		}
+		return "map[" + children[0].text + "]" + children[1].text, nil
+	case "ChanType":
+		return value + " " + joinGo(children, ""), nil
+	case "FuncType":
+		// The type parameters, the parameters and the results are all field lists.
+		switch len(children) {
+		case 0:
+			return "func()", nil
+		case 3:
+			return "func[" + strings.Trim(children[0].text, "()") + "]" + children[1].text + " " + children[2].text, nil
+		default:
+			return "func" + joinGo(children, " "), nil
+		}
+	case "FieldList":
+		if parent := n.Parent; parent != nil && (parent.Label == "StructType" || parent.Label == "InterfaceType") {
+			if len(children) == 0 {
+				return "{}", nil
+			}
+			return "{\n" + indentGo(joinGo(children, "\n")) + "\n}", nil
+		}
+		if isUnnamedResult(n) {
+			return joinGo(children, ""), nil
+		}
+		return "(" + joinGo(children, ", ") + ")", nil
+	case "Field":
+		return p.printField(n, children)
+
+	case "DeclStmt", "ExprStmt", "SelectStmt":
+		if n.Label == "SelectStmt" {
+			return "select " + joinGo(children, ""), nil
+		}
+		return joinGo(children, ""), nil
+	case "EmptyStmt":
+		return "", nil
+	case "LabeledStmt":
+		if len(children) != 2 {
+			break
+		}
+		return children[0].text + ":\n" + children[1].text, nil
+	case "SendStmt":
+		return joinGo(children, " <- "), nil
+	case "IncDecStmt":
+		return joinGo(children, "") + value, nil
+	case "AssignStmt":
+		// The left and the right hand sides have the same length, unless the right one is a single call.
+		lhs := len(children) / 2
+		if len(children)%2 == 1 {
+			lhs = len(children) - 1
+		}
+		return joinGo(children[:lhs], ", ") + " " + value + " " + joinGo(children[lhs:], ", "), nil
+	case "GoStmt":
+		return "go " + joinGo(children, ""), nil
+	case "DeferStmt":
+		return "defer " + joinGo(children, ""), nil
+	case "ReturnStmt":
+		if len(children) == 0 {
+			return "return", nil
+		}
+		return "return " + joinGo(children, ", "), nil
+	case "BranchStmt":
+		if len(children) == 0 {
+			return value, nil
+		}
+		return value + " " + joinGo(children, ""), nil
+	case "BlockStmt":
+		if len(children) == 0 {
+			return "{\n}", nil
+		}
+		// The clauses of switch and select statements are not indented.
+		if parent := n.Parent; parent != nil && (parent.Label == "SwitchStmt" || parent.Label == "TypeSwitchStmt" || parent.Label == "SelectStmt") {
+			return "{\n" + joinGo(children, "\n") + "\n}", nil
+		}
+		return "{\n" + indentGo(joinGo(children, "\n")) + "\n}", nil
+	case "IfStmt":
+		body := indexOfGo(children, "BlockStmt", 1)
+		if body < 0 {
+			break
+		}
+		text := "if " + joinGo(children[:body], "; ") + " " + children[body].text
+		if body+1 < len(children) {
+			text += " else " + joinGo(children[body+1:], "")
+		}
+		return text, nil
+	case "CaseClause", "CommClause":
+		return p.printClause(n, children)
+	case "SwitchStmt", "TypeSwitchStmt":
+		if len(children) == 0 {
+			break
+		}
+		header := joinGo(children[:len(children)-1], "; ")
+		if n.Label == "SwitchStmt" && len(children) == 2 && isGoStmt(children[0].label) {
+			// A switch statement without tag but with an init statement.
+			header += ";"
+		}
+		if header == "" {
+			return "switch " + children[len(children)-1].text, nil
+		}
+		return "switch " + header + " " + children[len(children)-1].text, nil
+	case "ForStmt":
+		return p.printFor(children)
+	case "RangeStmt":
+		if len(children) < 2 {
+			break
+		}
+		x, body := children[len(children)-2], children[len(children)-1]
+		if value == "" {
+			return "for range " + x.text + " " + body.text, nil
+		}
+		return "for " + joinGo(children[:len(children)-2], ", ") + " " + value + " range " + x.text + " " + body.text, nil
+
+	case "ImportSpec":
+		return joinGo(children, " "), nil
+	case "ValueSpec":
+		return p.printValueSpec(children), nil
+	case "TypeSpec":
+		if len(children) == 3 && children[1].label == "FieldList" {
+			return children[0].text + "[" + strings.Trim(children[1].text, "()") + "] " + children[2].text, nil
+		}
+		return joinGo(children, " "), nil
+	case "GenDecl":
+		if len(children) == 1 {
+			return value + " " + children[0].text, nil
+		}
+		return value + " (\n" + indentGo(joinGo(children, "\n")) + "\n)", nil
+	case "FuncDecl":
+		name := indexOfGo(children, "Ident", 0)
+		if name < 0 || name+1 >= len(children) {
+			break
+		}
+		text := "func "
+		if name > 0 {
+			text += children[0].text + " "
+		}
+		text += children[name].text + strings.TrimPrefix(children[name+1].text, "func")
+		if name+2 < len(children) {
+			text += " " + children[name+2].text
+		}
+		return text, nil
+	case "File":
+		if len(children) == 0 {
+			break
+		}
+		text := "package " + children[0].text + "\n"
+		for _, decl := range children[1:] {
+			text += "\n" + decl.text + "\n"
+		}
+		return text, nil
+	}
+	return "", fmt.Errorf("%w: %s with %d children", ErrUnprintable, n.Label, len(children))
+}
+
+// printField prints a parameter, a result, a struct field or an interface method:
+// its names are followed by its type and, for struct fields, by its tag.
+func (p *GoPrinter) printField(n *ast.Node, children []goChild) (string, error) {
+	tag := ""
+	if len(children) > 1 && children[len(children)-1].label == "BasicLit" {
+		tag, children = " "+children[len(children)-1].text, children[:len(children)-1]
+	}
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: field without type", ErrUnprintable)
+	}
+
+	names, typ := children[:len(children)-1], children[len(children)-1]
+	if len(names) == 0 {
+		return typ.text + tag, nil
+	}
+	if typ.label == "FuncType" && n.Parent != nil && n.Parent.Parent != nil && n.Parent.Parent.Label == "InterfaceType" {
+		return joinGo(names, ", ") + strings.TrimPrefix(typ.text, "func") + tag, nil
+	}
+	return joinGo(names, ", ") + " " + typ.text + tag, nil
+}
+
+// printClause prints a case of a switch or a select statement: its expressions or its communication, then its statements.
+func (p *GoPrinter) printClause(n *ast.Node, children []goChild) (string, error) {
+	header := 0
+	if n.Label == "CaseClause" {
+		for header < len(children) && !isGoStmt(children[header].label) {
+			header++
+		}
+	} else if len(children) > 0 && strings.Contains(children[0].text, "<-") {
+		header = 1
+	}
+
+	text := "default:"
+	if header > 0 {
+		text = "case " + joinGo(children[:header], ", ") + ":"
+	}
+	if header < len(children) {
+		text += "\n" + indentGo(joinGo(children[header:], "\n"))
+	}
+	return text, nil
+}
+
+// printFor prints a `for` statement, whose condition is the expression between its statements.
+func (p *GoPrinter) printFor(children []goChild) (string, error) {
+	if len(children) == 0 {
+		return "", fmt.Errorf("%w: for statement without body", ErrUnprintable)
+	}
+	header, body := children[:len(children)-1], children[len(children)-1]
+
+	cond := slices.IndexFunc(header, func(c goChild) bool { return !isGoStmt(c.label) })
+	switch {
+	case len(header) == 0:
+		return "for " + body.text, nil
+	case cond == 0 && len(header) == 1:
+		return "for " + header[0].text + " " + body.text, nil
+	case cond < 0:
+		// Without condition, a single statement is the init statement.
+		header = slices.Insert(header, 1, goChild{})
+	case cond == 0:
+		header = slices.Insert(header, 0, goChild{})
+	}
+	for len(header) < 3 {
+		header = append(header, goChild{})
+	}
+	return "for " + joinGo(header, "; ") + " " + body.text, nil
+}
+
+// printValueSpec prints the specification of constants or variables: its names, its optional type and its values.
+func (p *GoPrinter) printValueSpec(children []goChild) string {
+	names := 0
+	for names < len(children) && children[names].label == "Ident" {
+		names++
+	}
+	values := len(children) - names
+
+	typ := ""
+	switch {
+	case names < len(children) && isGoType(children[names].label) && children[names].label != "Ident":
+		typ, values = " "+children[names].text, values-1
+	case names > 1 && (values == 0 || names > values):
+		// Without values, or with fewer values than identifiers, the last identifier is the type.
+		names--
+		typ = " " + children[names].text
+	}
+
+	text := joinGo(children[:names], ", ") + typ
+	if values > 0 {
+		text += " = " + joinGo(children[len(children)-values:], ", ")
+	}
+	return text
+}
+
+// Token returns the value of `n`, as the values of the nodes of Go are their tokens.
+func (p *GoPrinter) Token(n *ast.Node) string {
+	return string(n.Value)
+}
+
+func (p *GoPrinter) Separator(n *ast.Node) string {
+	switch n.Label {
+	case "BlockStmt", "CaseClause", "CommClause", "CommentGroup":
+		return "\n"
+	case "File":
+		return "\n\n"
+	case "FieldList":
+		if parent := n.Parent; parent != nil && (parent.Label == "StructType" || parent.Label == "InterfaceType") {
+			return "\n"
+		}
+		return ", "
+	case "CallExpr", "CompositeLit", "ReturnStmt", "IndexListExpr":
+		return ", "
+	default:
+		return ""
+	}
+}
+
+// isUnnamedResult returns true if the field list `n` is made of the single unnamed result of a function type,
+// which is not parenthesized.
+func isUnnamedResult(n *ast.Node) bool {
+	parent := n.Parent
+	if parent == nil || parent.Label != "FuncType" || parent.Degree() < 2 || n.Degree() != 1 {
+		return false
+	}
+	siblings := parent.OrderedChildren()
+	return siblings[len(siblings)-1] == n && n.OrderedChildren()[0].Degree() == 1
+}
+
+func joinGo(children []goChild, separator string) string {
+	texts := make([]string, 0, len(children))
+	for _, child := range children {
+		texts = append(texts, child.text)
+	}
+	return strings.Join(texts, separator)
+}
+
+// indexOfGo returns the index of the first child labeled `label` from `from`, or -1 if there is none.
+func indexOfGo(children []goChild, label ast.NodeLabelType, from int) int {
+	for i := from; i < len(children); i++ {
+		if children[i].label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// indentGo indents the non-empty lines of `text` by a tab.
+func indentGo(text string) string {
+	return withIndent("\t"+text, "\t")
+}
+
+func isGoStmt(label ast.NodeLabelType) bool {
+	return strings.HasSuffix(string(label), "Stmt")
+}
+
+// isGoType returns true if a node labeled `label` may be a type, e.g. the type of a composite literal.
+func isGoType(label ast.NodeLabelType) bool {
+	switch label {
+	case "Ident", "SelectorExpr", "IndexExpr", "IndexListExpr", "StarExpr",
+		"ArrayType", "MapType", "StructType", "InterfaceType", "FuncType", "ChanType":
+		return true
+	default:
+		return false
+	}
+}
+
+// NewGoPrinter creates a GoPrinter.
+func NewGoPrinter() *GoPrinter {
+	return &GoPrinter{}
+}
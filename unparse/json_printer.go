@@ -0,0 +1,53 @@
+package unparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"strings"
+)
+
+// JSONPrinter prints the nodes built by frontend.JSONTreeGenerator on a single line, e.g. `{"a": [1, 2]}`.
+type JSONPrinter struct{}
+
+func (p *JSONPrinter) Print(n *ast.Node, children []string) (string, error) {
+	switch n.Label {
+	case frontend.JSONObjectLabel:
+		return "{" + strings.Join(children, ", ") + "}", nil
+	case frontend.JSONArrayLabel:
+		return "[" + strings.Join(children, ", ") + "]", nil
+	case frontend.JSONMemberLabel:
+		if len(children) != 1 {
+			return "", fmt.Errorf("%w: member %q has %d values", ErrUnprintable, n.Value, len(children))
+		}
+		return p.Token(n) + ": " + children[0], nil
+	case frontend.JSONStringLabel, frontend.JSONNumberLabel, frontend.JSONBooleanLabel, frontend.JSONNullLabel:
+		return string(n.Value), nil
+	default:
+		return "", fmt.Errorf("%w: unknown JSON label %q", ErrUnprintable, n.Label)
+	}
+}
+
+// Token returns the quoted key of members, and the literal text of scalars.
+func (p *JSONPrinter) Token(n *ast.Node) string {
+	if n.Label == frontend.JSONMemberLabel {
+		key, _ := json.Marshal(string(n.Value))
+		return string(key)
+	}
+	return string(n.Value)
+}
+
+func (p *JSONPrinter) Separator(n *ast.Node) string {
+	switch n.Label {
+	case frontend.JSONObjectLabel, frontend.JSONArrayLabel:
+		return ", "
+	default:
+		return ""
+	}
+}
+
+// NewJSONPrinter creates a JSONPrinter.
+func NewJSONPrinter() *JSONPrinter {
+	return &JSONPrinter{}
+}
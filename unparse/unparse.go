@@ -0,0 +1,410 @@
+// Package unparse turns ASTs back into source text.
+package unparse
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnprintable is returned when a node has no original text and the printer does not know how to print it.
+var ErrUnprintable = errors.New("cannot print node")
+
+// ErrUnsupportedLanguage is returned by PrinterFor for the files no printer handles.
+var ErrUnsupportedLanguage = errors.New("no printer for the language")
+
+// Origin is the node of a parsed file another node comes from, e.g. the node of the destination file an inserted node copies.
+type Origin struct {
+	Node *ast.Node
+
+	// Content is the content of the file of Node, which its position refers to.
+	Content []byte
+}
+
+// Origins returns the origin of a node, or false if it has none, e.g. because it has been built from scratch.
+type Origins func(n *ast.Node) (Origin, bool)
+
+// Copies returns the origins of the nodes of a copy of a tree parsed from `content`, made by ast.Copy,
+// which may have been edited since, e.g. by editscript.Apply. `copies` maps the nodes of the tree to their copies,
+// and the origin of a copy is the node it copies. The nodes added to the copy have no origin.
+func Copies(copies map[*ast.Node]*ast.Node, content []byte) Origins {
+	originals := make(map[*ast.Node]*ast.Node, len(copies))
+	for original, c := range copies {
+		originals[c] = original
+	}
+	return func(n *ast.Node) (Origin, bool) {
+		original, ok := originals[n]
+		return Origin{Node: original, Content: content}, ok
+	}
+}
+
+// Printer prints the nodes of a language whose text cannot be copied from their origin.
+// The texts it returns and receives are relative to the indentation of the node:
+// the lines following the first one are indented as if the node started at the beginning of a line.
+type Printer interface {
+	// Print returns the text of `n` given the texts of its children, in order.
+	// It returns ErrUnprintable if it does not know how to print `n`.
+	Print(n *ast.Node, children []string) (string, error)
+
+	// Token returns the text of the value of `n` as it appears in the source, e.g. the quoted key of a JSON member.
+	Token(n *ast.Node) string
+
+	// Separator returns the text between two children of `n`, e.g. `, ` between the elements of a list,
+	// used when the origin of `n` does not provide one. A separator ending with a line break is followed
+	// by the indentation of the previous line. It returns an empty string if the separator depends on the children,
+	// in which case `n` is printed from scratch.
+	Separator(n *ast.Node) string
+}
+
+// PrinterFor returns the printer of the language of the file at `path`, picked by its extension.
+func PrinterFor(path string) (Printer, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return NewGoPrinter(), nil
+	case ".json":
+		return NewJSONPrinter(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, path)
+	}
+}
+
+// Unparse returns the source text of `tree`.
+//
+// The text of a subtree that is identical to the subtree of its origin is copied from the content of the origin.
+// A node with an origin whose children or value changed keeps the text of its origin around its children,
+// e.g. brackets, keywords and separators, where the token of its previous value is replaced by the new one.
+// Any other node, e.g. a node without origin or whose label changed, is printed by `printer`.
+func Unparse(tree ast.AST, origins Origins, printer Printer) ([]byte, error) {
+	u := &unparser{
+		origins:   origins,
+		printer:   printer,
+		unchanged: make(map[*ast.Node]bool),
+		printed:   make(map[*ast.Node]string),
+		spans:     make(map[*ast.Node][2]int),
+		verbatim:  make([]string, 0),
+	}
+
+	root := tree.Root()
+	if root == nil {
+		return []byte{}, nil
+	}
+	text, err := u.print(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// The text around the root of the origin, e.g. a license header or the last line break, is kept.
+	if o, ok := u.originOf(root); ok {
+		start, end := u.spanOf(o.Node)
+		text = string(o.Content[:start]) + text + string(o.Content[end:])
+	}
+	return []byte(u.restore(text)), nil
+}
+
+type unparser struct {
+	origins Origins
+	printer Printer
+
+	// unchanged memoizes whether the subtree of a node is identical to the subtree of its origin,
+	// and printed memoizes the text of the nodes, as a node may be printed again when its parent cannot be spliced.
+	unchanged map[*ast.Node]bool
+	printed   map[*ast.Node]string
+	spans     map[*ast.Node][2]int
+
+	// verbatim holds the multi-line tokens, e.g. raw strings, which are replaced by placeholders while the text is built
+	// so that their lines are not indented.
+	verbatim []string
+}
+
+// protect returns the placeholder of the token `text` if it spans several lines, and `text` otherwise.
+func (u *unparser) protect(text string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	u.verbatim = append(u.verbatim, text)
+	return fmt.Sprintf("\x00%d\x00", len(u.verbatim)-1)
+}
+
+// restore replaces the placeholders of `text` by their tokens.
+func (u *unparser) restore(text string) string {
+	if len(u.verbatim) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(u.verbatim))
+	for i, token := range u.verbatim {
+		pairs = append(pairs, fmt.Sprintf("\x00%d\x00", i), token)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// textOf returns the text of the origin `o` from `start` to `end`, where the multi-line tokens are protected.
+func (u *unparser) textOf(o Origin, start, end int) string {
+	var b strings.Builder
+	for _, n := range preOrderOf(o.Node) {
+		if n.Degree() > 0 || !n.Pos.IsValid() || n.Pos.Start < start || n.Pos.End > end {
+			continue
+		}
+		if token := string(o.Content[n.Pos.Start:n.Pos.End]); strings.Contains(token, "\n") {
+			b.Write(o.Content[start:n.Pos.Start])
+			b.WriteString(u.protect(token))
+			start = n.Pos.End
+		}
+	}
+	b.Write(o.Content[start:end])
+	return b.String()
+}
+
+func preOrderOf(n *ast.Node) []*ast.Node {
+	nodes := []*ast.Node{n}
+	for _, child := range n.OrderedChildren() {
+		nodes = append(nodes, preOrderOf(child)...)
+	}
+	return nodes
+}
+
+// spanOf returns the range of the text of an origin node, including the text of its children,
+// which may lie outside of its position, e.g. the doc comment of a Go file.
+func (u *unparser) spanOf(n *ast.Node) (int, int) {
+	if span, ok := u.spans[n]; ok {
+		return span[0], span[1]
+	}
+
+	start, end := n.Pos.Start, n.Pos.End
+	for _, child := range n.OrderedChildren() {
+		if child.Pos.IsValid() {
+			childStart, childEnd := u.spanOf(child)
+			start, end = min(start, childStart), max(end, childEnd)
+		}
+	}
+	u.spans[n] = [2]int{start, end}
+	return start, end
+}
+
+func (u *unparser) originOf(n *ast.Node) (Origin, bool) {
+	o, ok := u.origins(n)
+	if !ok || o.Node == nil || !o.Node.Pos.IsValid() || o.Node.Pos.End > len(o.Content) {
+		return Origin{}, false
+	}
+	return o, true
+}
+
+// isUnchanged returns true if the subtree of `n` is identical to the subtree of its origin,
+// whose nodes are the origins of the nodes of the subtree of `n`.
+func (u *unparser) isUnchanged(n *ast.Node) bool {
+	if unchanged, ok := u.unchanged[n]; ok {
+		return unchanged
+	}
+
+	unchanged := func() bool {
+		o, ok := u.originOf(n)
+		if !ok || o.Node.Label != n.Label || o.Node.Value != n.Value || o.Node.Degree() != n.Degree() {
+			return false
+		}
+		originChildren := o.Node.OrderedChildren()
+		for i, child := range n.OrderedChildren() {
+			childOrigin, ok := u.originOf(child)
+			if !ok || childOrigin.Node != originChildren[i] || !u.isUnchanged(child) {
+				return false
+			}
+		}
+		return true
+	}()
+	u.unchanged[n] = unchanged
+	return unchanged
+}
+
+func (u *unparser) print(n *ast.Node) (string, error) {
+	if text, ok := u.printed[n]; ok {
+		return text, nil
+	}
+	text, err := u.printNode(n)
+	if err != nil {
+		return "", err
+	}
+	u.printed[n] = text
+	return text, nil
+}
+
+func (u *unparser) printNode(n *ast.Node) (string, error) {
+	o, ok := u.originOf(n)
+	if ok && u.isUnchanged(n) {
+		start, end := u.spanOf(o.Node)
+		return withoutIndent(u.textOf(o, start, end), indentOf(o.Content, start)), nil
+	}
+	if ok && o.Node.Label == n.Label {
+		if text, spliced, err := u.splice(n, o); spliced || err != nil {
+			return text, err
+		}
+	}
+
+	children := make([]string, 0, n.Degree())
+	for _, child := range n.OrderedChildren() {
+		text, err := u.print(child)
+		if err != nil {
+			return "", err
+		}
+		children = append(children, text)
+	}
+	text, err := u.printer.Print(n, children)
+	if err != nil || n.Degree() > 0 {
+		return text, err
+	}
+	return u.protect(text), nil
+}
+
+// splice prints `n` by placing the texts of its children in the text of its origin `o`.
+// It returns false if the text of the origin cannot be split around its children.
+func (u *unparser) splice(n *ast.Node, o Origin) (string, bool, error) {
+	originChildren := o.Node.OrderedChildren()
+	if len(originChildren) == 0 {
+		// The text of a leaf is its token, which is replaced if its value changed.
+		if n.Degree() > 0 {
+			return "", false, nil
+		}
+		text := []string{string(o.Content[o.Node.Pos.Start:o.Node.Pos.End])}
+		if o.Node.Value != n.Value && !replaceToken(text, u.printer.Token(o.Node), u.printer.Token(n)) {
+			return "", false, nil
+		}
+		return u.protect(text[0]), true, nil
+	}
+
+	// The text of the origin is split into the parts between its children: parts[i] precedes the ith child.
+	spanStart, spanEnd := u.spanOf(o.Node)
+	baseIndent := indentOf(o.Content, spanStart)
+	parts := make([]string, 0, len(originChildren)+1)
+	start := spanStart
+	for _, child := range originChildren {
+		if !child.Pos.IsValid() {
+			return "", false, nil
+		}
+		childStart, childEnd := u.spanOf(child)
+		if childStart < start {
+			return "", false, nil
+		}
+		parts = append(parts, withoutIndent(string(o.Content[start:childStart]), baseIndent))
+		start = childEnd
+	}
+	parts = append(parts, withoutIndent(string(o.Content[start:spanEnd]), baseIndent))
+
+	if o.Node.Value != n.Value {
+		if !replaceToken(parts, u.printer.Token(o.Node), u.printer.Token(n)) {
+			return "", false, nil
+		}
+	}
+
+	indexOf := make(map[*ast.Node]int, len(originChildren))
+	for i, child := range originChildren {
+		indexOf[child] = i
+	}
+	originIndexOf := func(child *ast.Node) int {
+		if childOrigin, ok := u.originOf(child); ok {
+			if i, ok := indexOf[childOrigin.Node]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var b strings.Builder
+	b.WriteString(parts[0])
+	children := n.OrderedChildren()
+	for i, child := range children {
+		text, err := u.print(child)
+		if err != nil {
+			return "", true, err
+		}
+		b.WriteString(withIndent(text, currentIndent(b.String())))
+
+		if i+1 < len(children) {
+			separator, ok := u.separatorOf(n, parts, originIndexOf(child), originIndexOf(children[i+1]), b.String())
+			if !ok {
+				return "", false, nil
+			}
+			b.WriteString(separator)
+		}
+	}
+	b.WriteString(parts[len(parts)-1])
+	return b.String(), true, nil
+}
+
+// separatorOf returns the text between two children of `n` whose origins are the `prev`th and the `next`th children
+// of the origin of `n`, or -1 if they are not children of it. `parts` is the text of the origin split around its children,
+// and `text` is the text of `n` printed so far. It returns false if there is no suitable separator.
+func (u *unparser) separatorOf(n *ast.Node, parts []string, prev, next int, text string) (string, bool) {
+	// The separators of the origin are the parts between its children.
+	// The one following the previous child is kept when the children in between are deleted or moved away.
+	separators := parts[1 : len(parts)-1]
+	if prev >= 0 && prev < len(separators) {
+		return separators[prev], true
+	}
+
+	separator := u.printer.Separator(n)
+	if separator == "" {
+		return "", false
+	}
+	if strings.HasSuffix(separator, "\n") {
+		separator += currentIndent(text)
+	}
+	return separator, true
+}
+
+// replaceToken replaces the first occurrence of `old` in `parts` by `new`. It returns false if there is none.
+func replaceToken(parts []string, old, new string) bool {
+	if old == "" {
+		return false
+	}
+	for i, part := range parts {
+		if strings.Contains(part, old) {
+			parts[i] = strings.Replace(part, old, new, 1)
+			return true
+		}
+	}
+	return false
+}
+
+// indentOf returns the indentation of the line of `content` holding the offset `pos`.
+func indentOf(content []byte, pos int) string {
+	lineStart := pos
+	for lineStart > 0 && content[lineStart-1] != '\n' {
+		lineStart--
+	}
+	end := lineStart
+	for end < pos && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return string(content[lineStart:end])
+}
+
+// currentIndent returns the indentation of the last line of `text`.
+func currentIndent(text string) string {
+	lastLine := text[strings.LastIndexByte(text, '\n')+1:]
+	return lastLine[:len(lastLine)-len(strings.TrimLeft(lastLine, " \t"))]
+}
+
+// withoutIndent removes `indent` from the beginning of the lines of `text` following the first one.
+func withoutIndent(text, indent string) string {
+	if indent == "" || !strings.Contains(text, "\n") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = strings.TrimPrefix(lines[i], indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withIndent adds `indent` at the beginning of the non-empty lines of `text` following the first one.
+func withIndent(text, indent string) string {
+	if indent == "" || !strings.Contains(text, "\n") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,234 @@
+package unparse_test
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"github.com/Xanonymous-GitHub/gumtree-go/unparse"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+const goSource = `// Package shapes computes areas.
+package shapes
+
+import (
+	"fmt"
+	"math"
+)
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 ` + "`json:\"radius\"`" + `
+}
+
+func (c *Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
+
+func Total(shapes []Shape, limit int) (float64, error) {
+	total := 0.0
+	for i, s := range shapes {
+		if i >= limit {
+			return 0, fmt.Errorf("too many shapes: %d", len(shapes))
+		} else if s == nil {
+			continue
+		}
+		total += s.Area()
+	}
+	switch {
+	case total > 100:
+		fmt.Println("large")
+	default:
+		fmt.Println("small")
+	}
+	for j := 0; j < 3; j++ {
+		defer func() {
+			total--
+		}()
+	}
+	sizes := map[string]int{"a": 1, "b": -2}
+	var names []string
+	for name := range sizes {
+		names = append(names, name[1:])
+	}
+	return total, nil
+}
+`
+
+const jsonSource = `{
+  "name": "gumtree",
+  "tags": ["diff", "ast"],
+  "license": "MIT"
+}`
+
+func logger() slog.Logger {
+	return *slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+}
+
+func parseGo(t *testing.T, src string) ast.AST {
+	tree, err := frontend.NewGoTreeGenerator(logger()).Generate([]byte(src))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return tree
+}
+
+func parseJSON(t *testing.T, src string) ast.AST {
+	tree, err := frontend.NewJSONTreeGenerator(logger()).Generate([]byte(src))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return tree
+}
+
+// copyOf returns a copy of `tree` to edit, and its origins in `src`.
+func copyOf(tree ast.AST, src string) (ast.AST, unparse.Origins) {
+	working, copies := ast.Copy(tree, logger())
+	return working, unparse.Copies(copies, []byte(src))
+}
+
+func nodeOf(tree ast.AST, label ast.NodeLabelType, value ast.NodeValueType) *ast.Node {
+	for _, n := range tree.PreOrderNodes() {
+		if n.Label == label && n.Value == value {
+			return n
+		}
+	}
+	return nil
+}
+
+// noOrigins makes the printers print every node.
+func noOrigins(*ast.Node) (unparse.Origin, bool) {
+	return unparse.Origin{}, false
+}
+
+func TestUnparse(t *testing.T) {
+	t.Parallel()
+
+	assertText := func(t *testing.T, text []byte, err error, expected string) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(text) != expected {
+			t.Errorf("Expected %q, got %q", expected, text)
+		}
+	}
+
+	t.Run("Test an unchanged tree gives back its source", func(t *testing.T) {
+		t.Parallel()
+
+		tree, origins := copyOf(parseGo(t, goSource), goSource)
+		text, err := unparse.Unparse(tree, origins, unparse.NewGoPrinter())
+		assertText(t, text, err, goSource)
+
+		tree, origins = copyOf(parseJSON(t, jsonSource), jsonSource)
+		text, err = unparse.Unparse(tree, origins, unparse.NewJSONPrinter())
+		assertText(t, text, err, jsonSource)
+	})
+
+	t.Run("Test an updated value replaces its token only", func(t *testing.T) {
+		t.Parallel()
+
+		src := "package main\n\nfunc main() {\n\tx := 1 + 2\n\tprintln(x)\n}\n"
+		tree, origins := copyOf(parseGo(t, src), src)
+		_ = tree.UpdateValue(nodeOf(tree, "BinaryExpr", "+"), "-")
+		for _, n := range tree.PreOrderNodes() {
+			if n.Label == "Ident" && n.Value == "x" {
+				_ = tree.UpdateValue(n, "y")
+			}
+		}
+
+		text, err := unparse.Unparse(tree, origins, unparse.NewGoPrinter())
+		assertText(t, text, err, "package main\n\nfunc main() {\n\ty := 1 - 2\n\tprintln(y)\n}\n")
+	})
+
+	t.Run("Test deleting and moving statements keeps the formatting", func(t *testing.T) {
+		t.Parallel()
+
+		src := "package main\n\nfunc main() {\n\ta()\n\tb() // b\n\tif ok {\n\t\tc()\n\t}\n}\n"
+		tree, origins := copyOf(parseGo(t, src), src)
+		block := nodeOf(tree, "BlockStmt", "")
+		statements := block.OrderedChildren()
+		_ = tree.Delete(statements[0])
+		// The comment following b() is not attached to it, so it stays in place.
+		ifBody := statements[3].OrderedChildren()[1]
+		_ = tree.Move(statements[1], ifBody, 5)
+
+		text, err := unparse.Unparse(tree, origins, unparse.NewGoPrinter())
+		assertText(t, text, err, "package main\n\nfunc main() {\n\t// b\n\tif ok {\n\t\tc()\n\t\tb()\n\t}\n}\n")
+	})
+
+	t.Run("Test the Go printer prints a whole file", func(t *testing.T) {
+		t.Parallel()
+
+		text, err := unparse.Unparse(parseGo(t, goSource), noOrigins, unparse.NewGoPrinter())
+		assertText(t, text, err, goSource)
+	})
+
+	t.Run("Test inserted nodes are printed within the original text", func(t *testing.T) {
+		t.Parallel()
+
+		src := "package main\n\nfunc main() {\n\tif ok {\n\t\ta()\n\t}\n}\n"
+		tree, origins := copyOf(parseGo(t, src), src)
+		ifBody := nodeOf(tree, "IfStmt", "").OrderedChildren()[1]
+		stmt, _ := tree.Add(ifBody, 1, "ExprStmt", "")
+		call, _ := tree.Add(stmt, 0, "CallExpr", "")
+		_, _ = tree.Add(call, 0, "Ident", "b")
+		_, _ = tree.Add(call, 1, "BasicLit", "1")
+
+		text, err := unparse.Unparse(tree, origins, unparse.NewGoPrinter())
+		assertText(t, text, err, "package main\n\nfunc main() {\n\tif ok {\n\t\ta()\n\t\tb(1)\n\t}\n}\n")
+	})
+
+	t.Run("Test JSON edits", func(t *testing.T) {
+		t.Parallel()
+
+		tree, origins := copyOf(parseJSON(t, jsonSource), jsonSource)
+		_ = tree.UpdateValue(nodeOf(tree, frontend.JSONMemberLabel, "license"), "licence")
+		_ = tree.Delete(nodeOf(tree, frontend.JSONStringLabel, `"diff"`))
+		array := nodeOf(tree, frontend.JSONArrayLabel, "")
+		_, _ = tree.Add(array, 5, frontend.JSONStringLabel, `"merge"`)
+		object, _ := tree.Add(array, 6, frontend.JSONObjectLabel, "")
+		member, _ := tree.Add(object, 0, frontend.JSONMemberLabel, `a "b"`)
+		_, _ = tree.Add(member, 0, frontend.JSONNullLabel, "null")
+
+		text, err := unparse.Unparse(tree, origins, unparse.NewJSONPrinter())
+		assertText(t, text, err, `{
+  "name": "gumtree",
+  "tags": ["ast", "merge", {"a \"b\"": null}],
+  "licence": "MIT"
+}`)
+	})
+
+	t.Run("Test unknown nodes cannot be printed", func(t *testing.T) {
+		t.Parallel()
+
+		tree := ast.NewAST(logger())
+		_, _ = tree.Add(nil, -1, "Unknown", "")
+
+		if _, err := unparse.Unparse(tree, noOrigins, unparse.NewGoPrinter()); !errors.Is(err, unparse.ErrUnprintable) {
+			t.Errorf("Expected ErrUnprintable, got %v", err)
+		}
+	})
+}
+
+func TestPrinterFor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Test printers are picked by extension", func(t *testing.T) {
+		if _, err := unparse.PrinterFor("main.go"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if _, err := unparse.PrinterFor("package.JSON"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if _, err := unparse.PrinterFor("README.md"); !errors.Is(err, unparse.ErrUnsupportedLanguage) {
+			t.Errorf("Expected ErrUnsupportedLanguage, got %v", err)
+		}
+	})
+}
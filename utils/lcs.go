@@ -0,0 +1,40 @@
+package utils
+
+// LongestCommonSubsequence returns the pairs of indices (i, j) such that `a[i]` and `b[j]` form
+// a longest common subsequence of `a` and `b` according to `equal`, in increasing order.
+func LongestCommonSubsequence[A, B any](a []A, b []B, equal func(x A, y B) bool) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:].
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(a[i], b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	result := make([][2]int, 0, lengths[0][0])
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(a[i], b[j]):
+			result = append(result, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Xanonymous-GitHub/gumtree-go/ast"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"net/http"
+)
+
+// APIFile is a file listed by `GET /api/files`.
+type APIFile struct {
+	Id      int            `json:"id"`
+	SrcPath string         `json:"src,omitempty"`
+	DstPath string         `json:"dst,omitempty"`
+	Status  dirdiff.Status `json:"status"`
+
+	// Similarity is the similarity of a renamed file, see dirdiff.FilePair.
+	Similarity float64 `json:"similarity,omitempty"`
+}
+
+// APIRange is the range of a node in its file.
+// Offsets are in bytes and End is exclusive; lines start at 1 and EndLine is the line of the last byte of the node.
+type APIRange struct {
+	Start     int `json:"start"`
+	End       int `json:"end"`
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// APIAction is an action of the edit script of a file.
+// Src is the range of the affected node in the source file, unless it is inserted,
+// and Dst is the range of the corresponding node in the destination file, unless it is deleted.
+type APIAction struct {
+	Type     string    `json:"type"`
+	Label    string    `json:"label"`
+	Value    string    `json:"value,omitempty"`
+	NewLabel string    `json:"newLabel,omitempty"`
+	NewValue string    `json:"newValue,omitempty"`
+	Src      *APIRange `json:"src,omitempty"`
+	Dst      *APIRange `json:"dst,omitempty"`
+}
+
+// APIMapping is a pair of matched nodes.
+type APIMapping struct {
+	Label string    `json:"label"`
+	Src   *APIRange `json:"src"`
+	Dst   *APIRange `json:"dst"`
+}
+
+// APIDiff is the structural diff of a file returned by `GET /api/files/{id}`.
+type APIDiff struct {
+	APIFile
+	Actions  []APIAction  `json:"actions"`
+	Mappings []APIMapping `json:"mappings"`
+}
+
+func (s *Server) handleAPIFiles(w http.ResponseWriter, _ *http.Request) {
+	files := make([]APIFile, 0, len(s.pairs))
+	for id := range s.pairs {
+		files = append(files, s.apiFileOf(id))
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+func (s *Server) handleAPIFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.idOf(r)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such file"})
+		return
+	}
+
+	result, err := s.resultOf(id)
+	if err != nil {
+		writeJSON(w, statusOf(err), map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, apiDiffOf(s.apiFileOf(id), result))
+}
+
+func (s *Server) apiFileOf(id int) APIFile {
+	pair := s.pairs[id]
+	return APIFile{Id: id, SrcPath: pair.SrcPath, DstPath: pair.DstPath, Status: pair.Status, Similarity: pair.Similarity}
+}
+
+func apiDiffOf(file APIFile, result *diff.Result) APIDiff {
+	srcRange := rangeFunc(result.SrcContent)
+	dstRange := rangeFunc(result.DstContent)
+
+	actions := make([]APIAction, 0, len(result.Script))
+	for _, action := range result.Script {
+		a := APIAction{Type: action.Type.String(), Label: string(action.Node.Label), Value: string(action.Node.Value)}
+		switch action.Type {
+		case editscript.Insert, editscript.TreeInsert:
+			a.Dst = dstRange(action.Node)
+		case editscript.Delete, editscript.TreeDelete:
+			a.Src = srcRange(action.Node)
+		case editscript.Update:
+			a.NewLabel, a.NewValue = string(action.Label), string(action.Value)
+			a.Src, a.Dst = srcRange(action.Node), dstRange(result.Mappings.DstOf(action.Node))
+		case editscript.Move:
+			a.Src, a.Dst = srcRange(action.Node), dstRange(result.Mappings.DstOf(action.Node))
+		}
+		actions = append(actions, a)
+	}
+
+	mappings := make([]APIMapping, 0, result.Mappings.Size())
+	for _, pair := range result.Mappings.Pairs() {
+		src, dst := srcRange(pair.Left()), dstRange(pair.Right())
+		if src == nil || dst == nil {
+			continue
+		}
+		mappings = append(mappings, APIMapping{Label: string(pair.Left().Label), Src: src, Dst: dst})
+	}
+
+	return APIDiff{APIFile: file, Actions: actions, Mappings: mappings}
+}
+
+// rangeFunc returns a function computing the ranges of the nodes of a file with the content `content`.
+// It returns nil for nodes without a position.
+func rangeFunc(content []byte) func(n *ast.Node) *APIRange {
+	lineStarts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		lo, hi := 0, len(lineStarts)
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			if lineStarts[mid] <= offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return lo + 1
+	}
+
+	return func(n *ast.Node) *APIRange {
+		if n == nil || !n.Pos.IsValid() {
+			return nil
+		}
+		return &APIRange{Start: n.Pos.Start, End: n.Pos.End, StartLine: lineOf(n.Pos.Start), EndLine: lineOf(max(n.Pos.End-1, n.Pos.Start))}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body.Bytes())
+}
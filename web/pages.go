@@ -0,0 +1,135 @@
+package web
+
+import (
+	"bytes"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/editscript"
+	"github.com/Xanonymous-GitHub/gumtree-go/render"
+	"html/template"
+	"net/http"
+)
+
+type indexEntry struct {
+	Id   int
+	Pair dirdiff.FilePair
+	Name string
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if len(s.pairs) == 1 {
+		http.Redirect(w, r, "/files/0", http.StatusFound)
+		return
+	}
+
+	entries := make([]indexEntry, 0, len(s.pairs))
+	for id, pair := range s.pairs {
+		entries = append(entries, indexEntry{Id: id, Pair: pair, Name: nameOf(pair)})
+	}
+	writePage(w, indexTemplate, map[string]any{"Files": entries, "Styles": template.CSS(render.HTMLStyles + pageStyles)})
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.idOf(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	result, err := s.resultOf(id)
+	if err != nil {
+		http.Error(w, err.Error(), statusOf(err))
+		return
+	}
+
+	var fragment bytes.Buffer
+	if err := render.NewHTMLRenderer().RenderFragment(&fragment, result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writePage(w, fileTemplate, map[string]any{
+		"Name":     nameOf(s.pairs[id]),
+		"Status":   s.pairs[id].Status,
+		"Single":   len(s.pairs) == 1,
+		"Inserts":  result.Script.CountOf(editscript.Insert) + result.Script.CountOf(editscript.TreeInsert),
+		"Deletes":  result.Script.CountOf(editscript.Delete) + result.Script.CountOf(editscript.TreeDelete),
+		"Updates":  result.Script.CountOf(editscript.Update),
+		"Moves":    result.Script.CountOf(editscript.Move),
+		"Fragment": template.HTML(fragment.String()),
+		"Styles":   template.CSS(render.HTMLStyles + pageStyles),
+		"Script":   template.JS(render.HTMLScript),
+	})
+}
+
+// writePage renders `tmpl` into a buffer first, so that a failure results in an error page rather than a truncated one.
+func writePage(w http.ResponseWriter, tmpl *template.Template, data any) {
+	var page bytes.Buffer
+	if err := tmpl.Execute(&page, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(page.Bytes())
+}
+
+// nameOf returns the name under which a file is listed, e.g. `a.go → b.go` for a renamed file.
+func nameOf(pair dirdiff.FilePair) string {
+	switch {
+	case pair.SrcPath == "":
+		return pair.DstPath
+	case pair.DstPath == "" || pair.SrcPath == pair.DstPath:
+		return pair.SrcPath
+	default:
+		return pair.SrcPath + " → " + pair.DstPath
+	}
+}
+
+const pageStyles = `
+nav { margin-bottom: 0.3em; font-size: 0.9em; }
+table.files { border-collapse: collapse; margin: 1em; }
+table.files td { padding: 0.2em 0.8em; font-family: monospace; }
+.status-added { color: #2a8a2a; }
+.status-removed { color: #b02a2a; }
+.status-modified { color: #b07a00; }
+.status-renamed { color: #2a5ab0; }
+`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GumTree diff</title>
+<style>{{.Styles}}</style>
+</head>
+<body>
+<header><h1>{{len .Files}} changed files</h1></header>
+<table class="files">
+{{range .Files}}<tr><td class="status-{{.Pair.Status}}">{{.Pair.Status}}</td><td><a href="/files/{{.Id}}">{{.Name}}</a></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var fileTemplate = template.Must(template.New("file").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>{{.Styles}}</style>
+</head>
+<body>
+<header>
+{{if not .Single}}<nav><a href="/">all files</a></nav>{{end}}
+<h1>{{.Name}} <span class="status-{{.Status}}">({{.Status}})</span></h1>
+<div class="legend">
+<span class="ins">{{.Inserts}} inserted</span>
+<span class="del">{{.Deletes}} deleted</span>
+<span class="upd">{{.Updates}} updated</span>
+<span class="mv">{{.Moves}} moved</span>
+</div>
+</header>
+{{.Fragment}}
+<script>{{.Script}}</script>
+</body>
+</html>
+`))
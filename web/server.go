@@ -0,0 +1,87 @@
+package web
+
+import (
+	"errors"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/frontend"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Server serves an interactive viewer of the structural diffs of a list of files, and the JSON API backing it:
+//
+//	GET /                   the list of the changed files
+//	GET /files/{id}         the side-by-side diff of a file
+//	GET /api/files          the list of the changed files, as JSON
+//	GET /api/files/{id}     the edit script and the mappings of a file, as JSON
+//
+// The diffs are computed on demand, when a file is first requested, and kept for later requests.
+type Server struct {
+	pairs []dirdiff.FilePair
+	opts  diff.Options
+	mux   *http.ServeMux
+
+	mu      sync.Mutex
+	results map[int]*diff.Result
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// resultOf returns the diff of the file with the index `id`, computing it if it is requested for the first time.
+func (s *Server) resultOf(id int) (*diff.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if result, ok := s.results[id]; ok {
+		return result, nil
+	}
+
+	result, err := dirdiff.CompareFiles(s.pairs[id], s.opts)
+	if err != nil {
+		return nil, err
+	}
+	s.results[id] = result
+	return result, nil
+}
+
+// idOf returns the index of the file requested by `r`, or false if there is no such file.
+func (s *Server) idOf(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 0 || id >= len(s.pairs) {
+		return 0, false
+	}
+	return id, true
+}
+
+// statusOf returns the HTTP status code reporting `err`.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, frontend.ErrUnsupportedFile):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// NewServer creates a Server for `pairs`, comparing the files with `opts`.
+func NewServer(pairs []dirdiff.FilePair, opts diff.Options) *Server {
+	s := &Server{
+		pairs:   pairs,
+		opts:    opts,
+		mux:     http.NewServeMux(),
+		results: make(map[int]*diff.Result),
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /files/{id}", s.handleFile)
+	s.mux.HandleFunc("GET /api/files", s.handleAPIFiles)
+	s.mux.HandleFunc("GET /api/files/{id}", s.handleAPIFile)
+	return s
+}
@@ -0,0 +1,145 @@
+package web_test
+
+import (
+	"encoding/json"
+	"github.com/Xanonymous-GitHub/gumtree-go/diff"
+	"github.com/Xanonymous-GitHub/gumtree-go/dirdiff"
+	"github.com/Xanonymous-GitHub/gumtree-go/web"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	src := write("a.json", `{"name": "foo", "tags": ["x", "y"]}`)
+	dst := write("b.json", `{"name": "bar", "tags": ["x", "y"], "size": 3}`)
+	added := write("c.json", `[1, 2]`)
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	pairs := []dirdiff.FilePair{
+		{SrcPath: src, DstPath: dst, Status: dirdiff.Modified},
+		{DstPath: added, Status: dirdiff.Added},
+	}
+	server := httptest.NewServer(web.NewServer(pairs, diff.DefaultOptions(*logger)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, url string) (*http.Response, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	server := newTestServer(t)
+
+	t.Run("Test the index lists the files", func(t *testing.T) {
+		resp, body := get(t, server.URL+"/")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if !strings.Contains(body, `href="/files/0"`) || !strings.Contains(body, `href="/files/1"`) {
+			t.Errorf("Expected links to both files, got %s", body)
+		}
+	})
+
+	t.Run("Test the diff page of a file", func(t *testing.T) {
+		resp, body := get(t, server.URL+"/files/0")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if !strings.Contains(body, `title="inserted `) {
+			t.Errorf("Expected the inserted nodes to be colored")
+		}
+	})
+
+	t.Run("Test the file list API", func(t *testing.T) {
+		_, body := get(t, server.URL+"/api/files")
+		var files []web.APIFile
+		if err := json.Unmarshal([]byte(body), &files); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(files) != 2 || files[1].Status != dirdiff.Added || files[1].SrcPath != "" {
+			t.Errorf("Expected a modified and an added file, got %+v", files)
+		}
+	})
+
+	t.Run("Test the diff API", func(t *testing.T) {
+		_, body := get(t, server.URL+"/api/files/0")
+		var d web.APIDiff
+		if err := json.Unmarshal([]byte(body), &d); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(d.Actions) == 0 {
+			t.Fatalf("Expected actions")
+		}
+		for _, action := range d.Actions {
+			hasSrc, hasDst := action.Src != nil, action.Dst != nil
+			switch action.Type {
+			case "insert":
+				if hasSrc || !hasDst {
+					t.Errorf("Expected an inserted node to only have a destination range, got %+v", action)
+				}
+			case "delete":
+				if !hasSrc || hasDst {
+					t.Errorf("Expected a deleted node to only have a source range, got %+v", action)
+				}
+			default:
+				if !hasSrc || !hasDst {
+					t.Errorf("Expected both ranges, got %+v", action)
+				}
+			}
+			if hasSrc && (action.Src.StartLine != 1 || action.Src.Start >= action.Src.End) {
+				t.Errorf("Expected a range on the first line, got %+v", action.Src)
+			}
+		}
+	})
+
+	t.Run("Test the diff API of an added file", func(t *testing.T) {
+		_, body := get(t, server.URL+"/api/files/1")
+		var d web.APIDiff
+		if err := json.Unmarshal([]byte(body), &d); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, action := range d.Actions {
+			if action.Type != "insert" {
+				t.Errorf("Expected only inserts, got %+v", action)
+			}
+		}
+		if len(d.Actions) == 0 {
+			t.Errorf("Expected inserts")
+		}
+	})
+
+	t.Run("Test unknown files", func(t *testing.T) {
+		resp, _ := get(t, server.URL+"/api/files/42")
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}